@@ -3,28 +3,50 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime/debug"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 
+	"github.com/gandarez/btc-price-service/internal/app/domain/adminapp"
+	"github.com/gandarez/btc-price-service/internal/app/domain/alertapp"
 	"github.com/gandarez/btc-price-service/internal/app/domain/checkapp"
 	"github.com/gandarez/btc-price-service/internal/app/domain/priceapp"
+	"github.com/gandarez/btc-price-service/internal/app/domain/webhookapp"
 	"github.com/gandarez/btc-price-service/internal/app/sdk/mux"
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pubsub"
+	"github.com/gandarez/btc-price-service/internal/business/domain/alertbus"
 	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus"
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus/stores/webhookfile"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coinbaseclient"
 	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/krakenclient"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/webhookclient"
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
 	"github.com/gandarez/btc-price-service/internal/foundation/config"
 	"github.com/gandarez/btc-price-service/internal/foundation/log"
 	"github.com/gandarez/btc-price-service/internal/foundation/version"
 	"github.com/gandarez/btc-price-service/internal/foundation/web"
 )
 
+const (
+	// ingestionPoll polls the price sources periodically.
+	ingestionPoll = "poll"
+	// ingestionStream ingests the ticks pushed by the CoinDesk WebSocket API, polling while it is disconnected.
+	ingestionStream = "stream"
+)
+
 func main() {
 	ctx := context.Background()
 
@@ -61,33 +83,118 @@ func main() {
 	}()
 
 	// Initialize price bus
-	coindeskcli := coindeskclient.NewClient(
-		cfg.CoinDeskConfig.URL,
-		cfg.CoinDeskConfig.APIKey,
-	)
-	priceBus := pricebus.NewBusiness(coindeskcli)
+	source, err := newPriceSource(cfg)
+	if err != nil {
+		logger.Fatalf("failed to initialize price source: %v", err)
+	}
+
+	busOpts, err := newPriceBusOptions(cfg)
+	if err != nil {
+		logger.Fatalf("failed to initialize price streamer: %v", err)
+	}
+
+	busOpts = append(busOpts, pricebus.WithValidator(pricebus.NewValidator(pricebus.ValidatorConfig{
+		MaxJump:           cfg.TickConfig.MaxJump,
+		JumpConfirmations: cfg.TickConfig.JumpConfirmations,
+		MaxFutureSkew:     time.Duration(cfg.TickConfig.MaxFutureSkew) * time.Second,
+		MaxRejected:       cfg.TickConfig.MaxRejected,
+	})))
+
+	candleIntervals, err := parseCandleIntervals(cfg.CandleConfig.Intervals)
+	if err != nil {
+		logger.Fatalf("failed to parse candle intervals: %v", err)
+	}
+
+	overflowPolicy, err := pubsub.ParsePolicy(cfg.BroadcastConfig.OverflowPolicy)
+	if err != nil {
+		logger.Fatalf("failed to parse overflow policy: %v", err)
+	}
+
+	overflow := pubsub.Options{
+		Policy:     overflowPolicy,
+		BufferSize: cfg.BroadcastConfig.BufferSize,
+		MaxDrops:   cfg.BroadcastConfig.MaxDrops,
+	}
+
+	priceBus := pricebus.NewBusiness(source, breaker.Config{
+		FailureThreshold: cfg.BreakerConfig.FailureThreshold,
+		CoolDown:         time.Duration(cfg.BreakerConfig.CoolDown) * time.Second,
+	}, busOpts...)
+
+	// Initialize webhook bus, with the queue left by the previous run
+	webhookBus, err := webhookbus.NewBusiness(webhookclient.NewClient(), webhookfile.NewStore(cfg.WebhookConfig.QueuePath),
+		webhookbus.Config{
+			MaxAttempts:    cfg.WebhookConfig.MaxAttempts,
+			BaseBackoff:    time.Duration(cfg.WebhookConfig.BaseBackoff) * time.Millisecond,
+			MaxBackoff:     time.Duration(cfg.WebhookConfig.MaxBackoff) * time.Millisecond,
+			MaxPending:     cfg.WebhookConfig.MaxPending,
+			MaxDeadLetters: cfg.WebhookConfig.MaxDeadLetters,
+		})
+	if err != nil {
+		logger.Fatalf("failed to initialize webhooks: %v", err)
+	}
+
+	// Initialize alert bus, whose webhooks are retried by the webhook bus
+	alertBus := alertbus.NewBusiness(webhookBus)
 
 	// build http routes
 	cfgMux := mux.Config{
+		AdminConfig: mux.AdminConfig{
+			PriceBus: priceBus,
+			Token:    cfg.AdminConfig.Token,
+		},
+		AlertConfig: mux.AlertConfig{
+			AlertBus: alertBus,
+		},
+		CheckConfig: mux.CheckConfig{
+			PriceBus: priceBus,
+		},
 		PriceConfig: mux.PriceConfig{
 			BufferTTL:                 time.Duration(cfg.CacheConfig.TTL) * time.Second,
 			MaxCacheSize:              cfg.CacheConfig.MaxSize,
 			DefaultExpirationInterval: time.Duration(cfg.CacheConfig.ExpirationInterval) * time.Second,
 			PollInterval:              time.Duration(cfg.CoinDeskConfig.PollInterval) * time.Second,
 			MaxPeersPerBroadcaster:    cfg.BroadcastConfig.MaxPeersPerBroadcaster,
+			Overflow:                  overflow,
+			Symbols:                   cfg.PriceConfig.Symbols,
+			Streaming:                 cfg.PriceConfig.Ingestion == ingestionStream,
+			Retry:                     time.Duration(cfg.SSEConfig.Retry) * time.Millisecond,
+			ShutdownRetry:             time.Duration(cfg.SSEConfig.ShutdownRetry) * time.Millisecond,
+			RetryJitter:               cfg.SSEConfig.RetryJitter,
+			CandleIntervals:           candleIntervals,
+			CandleMaxBars:             cfg.CandleConfig.MaxBars,
 			PriceBus:                  priceBus,
+			AlertBus:                  alertBus,
+			WebhookBus:                webhookBus,
+		},
+		WebhookConfig: mux.WebhookConfig{
+			WebhookBus: webhookBus,
 		},
 	}
 
-	mux := mux.WebAPI(ctx, cfgMux, buildRoutes())
+	grpcServer := grpc.NewServer()
+	cfgMux.PriceConfig.GRPC = grpcServer
+
+	// canceled on shutdown, so streams can tell their clients before the server waits for them
+	appCtx, stopApp := context.WithCancel(ctx)
+	defer stopApp()
+
+	go webhookBus.Run(appCtx)
+
+	mux := mux.WebAPI(appCtx, cfgMux, buildRoutes())
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", cfg.ServerConfig.Port),
 		ReadHeaderTimeout: time.Duration(cfg.ServerConfig.ReadHeaderTimeout) * time.Second,
 		Handler:           mux,
 	}
 
-	// Start http server
-	serverError := make(chan error, 1)
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.ServerConfig.GRPCPort))
+	if err != nil {
+		logger.Fatalf("failed to listen for grpc: %v", err)
+	}
+
+	// Start http and grpc servers
+	serverError := make(chan error, 2)
 
 	go func() {
 		logger.Infof("http server started on %s", server.Addr)
@@ -95,6 +202,12 @@ func main() {
 		serverError <- server.ListenAndServe()
 	}()
 
+	go func() {
+		logger.Infof("grpc server started on %s", grpcListener.Addr())
+
+		serverError <- grpcServer.Serve(grpcListener)
+	}()
+
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
@@ -104,6 +217,8 @@ func main() {
 	case <-shutdown:
 		logger.Infoln("received shutdown signal, shutting down service")
 
+		stopApp()
+
 		ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.ShutdownTimeout)*time.Second)
 		defer cancel()
 
@@ -111,12 +226,134 @@ func main() {
 			logger.Errorf("failed to shutdown server: %v", err)
 		}
 
+		stopGRPC(ctx, grpcServer)
+
+		// every change to the webhook queue is persisted when made, only the ones whose save failed are left
+		if err := webhookBus.Flush(); err != nil {
+			logger.Errorf("failed to save webhook queue: %v", err)
+		}
+
 		logger.Infof("service %s has shut down", cfg.ServiceName)
 	}
 
 	logger.Infof("service %s gracefully stopped", cfg.ServiceName)
 }
 
+// stopGRPC stops the grpc server gracefully, or forcibly once ctx is done.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Extract(ctx).Errorf("failed to shutdown grpc server gracefully: %v", ctx.Err())
+
+		server.Stop()
+	}
+}
+
+// parseCandleIntervals parses the candle intervals of the configuration, such as 1m or 1h.
+func parseCandleIntervals(values []string) ([]time.Duration, error) {
+	intervals := make([]time.Duration, 0, len(values))
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid candle interval %q: %w", value, err)
+		}
+
+		if interval <= 0 {
+			return nil, fmt.Errorf("candle interval %q must be positive", value)
+		}
+
+		if !slices.Contains(intervals, interval) {
+			intervals = append(intervals, interval)
+		}
+	}
+
+	return intervals, nil
+}
+
+// newPriceBusOptions creates the options of the price bus for the ingestion mode selected in the configuration.
+func newPriceBusOptions(cfg config.Config) ([]pricebus.Option, error) {
+	switch cfg.PriceConfig.Ingestion {
+	case ingestionPoll:
+		return nil, nil
+	case ingestionStream:
+		stream := coindeskclient.NewStream(
+			cfg.CoinDeskConfig.Stream.URL,
+			cfg.CoinDeskConfig.APIKey,
+			coindeskclient.WithHeartbeatTimeout(time.Duration(cfg.CoinDeskConfig.Stream.HeartbeatTimeout)*time.Second),
+		)
+
+		return []pricebus.Option{pricebus.WithStreamer(pricebus.NewCoinDeskStreamer(stream))}, nil
+	default:
+		return nil, fmt.Errorf("unsupported price ingestion %q", cfg.PriceConfig.Ingestion)
+	}
+}
+
+// newPriceSource creates the upstream price source selected in the configuration.
+// When more than one source is configured, their quotes are aggregated into a composite price.
+func newPriceSource(cfg config.Config) (pricebus.PriceSource, error) {
+	sources := make([]pricebus.PriceSource, 0, len(cfg.PriceConfig.Sources))
+
+	for _, name := range cfg.PriceConfig.Sources {
+		switch name {
+		case pricebus.SourceCoinDesk:
+			opts := []coindeskclient.Option{
+				coindeskclient.WithRequestTimeout(time.Duration(cfg.CoinDeskConfig.RequestTimeout) * time.Second),
+				coindeskclient.WithTotalTimeout(time.Duration(cfg.CoinDeskConfig.TotalTimeout) * time.Second),
+				coindeskclient.WithBudget(coindeskclient.Budget{
+					Daily:   cfg.CoinDeskConfig.DailyBudget,
+					Monthly: cfg.CoinDeskConfig.MonthlyBudget,
+				}),
+				coindeskclient.WithRetryPolicy(coindeskclient.RetryPolicy{
+					MaxAttempts: cfg.CoinDeskConfig.Retry.MaxAttempts,
+					BaseBackoff: time.Duration(cfg.CoinDeskConfig.Retry.BaseBackoff) * time.Millisecond,
+					MaxBackoff:  time.Duration(cfg.CoinDeskConfig.Retry.MaxBackoff) * time.Millisecond,
+					Jitter:      cfg.CoinDeskConfig.Retry.Jitter,
+				}),
+			}
+
+			// the calls are only counted against a budget
+			if cfg.CoinDeskConfig.DailyBudget > 0 || cfg.CoinDeskConfig.MonthlyBudget > 0 {
+				opts = append(opts, coindeskclient.WithUsageStore(coindeskclient.NewUsageFile(cfg.CoinDeskConfig.UsagePath)))
+			}
+
+			sources = append(sources, pricebus.NewCoinDeskSource(
+				coindeskclient.NewClient(cfg.CoinDeskConfig.URL, cfg.CoinDeskConfig.APIKey, opts...),
+			))
+		case pricebus.SourceCoinbase:
+			sources = append(sources, pricebus.NewCoinbaseSource(coinbaseclient.NewClient(cfg.CoinbaseConfig.URL)))
+		case pricebus.SourceKraken:
+			sources = append(sources, pricebus.NewKrakenSource(krakenclient.NewClient(cfg.KrakenConfig.URL)))
+		default:
+			return nil, fmt.Errorf("unsupported price source %q", name)
+		}
+	}
+
+	if len(sources) == 1 {
+		return sources[0], nil
+	}
+
+	return pricebus.NewAggregator(pricebus.AggregatorConfig{
+		Method:       cfg.PriceConfig.Aggregation,
+		MaxDeviation: cfg.PriceConfig.MaxDeviation,
+		MaxQuoteAge:  time.Duration(cfg.PriceConfig.MaxQuoteAge) * time.Second,
+		MinSources:   cfg.PriceConfig.MinSources,
+	}, sources...)
+}
+
 type add struct{}
 
 func buildRoutes() mux.RouteAdder {
@@ -124,6 +361,9 @@ func buildRoutes() mux.RouteAdder {
 }
 
 func (add) Add(ctx context.Context, app *web.App, cfg mux.Config) {
-	checkapp.Routes(ctx, app)
+	adminapp.Routes(ctx, app, cfg)
+	alertapp.Routes(ctx, app, cfg)
+	checkapp.Routes(ctx, app, cfg)
 	priceapp.Routes(ctx, app, cfg)
+	webhookapp.Routes(ctx, app, cfg)
 }
@@ -16,6 +16,8 @@ export async function GET(req: NextRequest) {
     method: 'GET',
     headers: {
       Accept: 'text/event-stream',
+      // let the service replay the prices missed while reconnecting
+      ...(req.headers.get('last-event-id') ? { 'Last-Event-ID': req.headers.get('last-event-id')! } : {}),
     },
     signal: controller.signal, // Pass abort signal to fetch
   });
@@ -45,13 +45,13 @@ export default function Home() {
       }
     };
 
-    source.onmessage = (event) => {
-      try {
-        if (event.data === "ping" || event.data === "connected") {
-          // Ignore ping messages
-          return;
-        }
+    source.addEventListener("status", (event: MessageEvent) => {
+      const data = JSON.parse(event.data);
+      setStatus(data.available ? "Connected ✅" : "Upstream unavailable ⚠️");
+    });
 
+    source.addEventListener("price", (event: MessageEvent) => {
+      try {
         const data = JSON.parse(event.data);
         const newPrice = Number(data.price);
 
@@ -76,7 +76,7 @@ export default function Home() {
       } catch (err) {
         console.error("Parse error:", err);
       }
-    };
+    });
   };
 
   connect();
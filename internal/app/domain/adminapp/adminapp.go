@@ -0,0 +1,50 @@
+package adminapp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+type (
+	app struct {
+		priceBus PriceBusiness
+		token    string
+	}
+
+	// PriceBusiness defines the interface for inspecting the price domain.
+	PriceBusiness interface {
+		Rejections() pricebus.Rejections
+	}
+)
+
+func newApp(priceBus PriceBusiness, token string) *app {
+	return &app{
+		priceBus: priceBus,
+		token:    token,
+	}
+}
+
+// rejectedTicks returns the ticks rejected by validation, most recent first.
+func (a *app) rejectedTicks(_ context.Context, r *http.Request) web.Encoder {
+	if !a.authorized(r) {
+		return web.NewError(http.StatusUnauthorized, errors.New("invalid or missing admin token"))
+	}
+
+	return toAppRejectedTicks(a.priceBus.Rejections())
+}
+
+// authorized reports whether the request carries the admin token as a bearer token.
+func (a *app) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || a.token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1
+}
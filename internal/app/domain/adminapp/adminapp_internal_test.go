@@ -0,0 +1,49 @@
+package adminapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+func TestRejectedTicks_Authorization(t *testing.T) {
+	a := newApp(&mockPriceBusiness{}, "secret")
+
+	server := web.NewApp()
+	server.HandlerFunc(t.Context(), http.MethodGet, "v1", "/admin/rejected-ticks", a.rejectedTicks)
+
+	tests := map[string]struct {
+		Header   string
+		Expected int
+	}{
+		"valid token":   {Header: "Bearer secret", Expected: http.StatusOK},
+		"invalid token": {Header: "Bearer wrong", Expected: http.StatusUnauthorized},
+		"not a bearer":  {Header: "Basic secret", Expected: http.StatusUnauthorized},
+		"missing":       {Expected: http.StatusUnauthorized},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/admin/rejected-ticks", nil)
+			if test.Header != "" {
+				r.Header.Set("Authorization", test.Header)
+			}
+
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, r)
+
+			assert.Equal(t, test.Expected, w.Code)
+		})
+	}
+}
+
+type mockPriceBusiness struct{}
+
+func (*mockPriceBusiness) Rejections() pricebus.Rejections {
+	return pricebus.Rejections{}
+}
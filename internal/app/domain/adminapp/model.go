@@ -0,0 +1,60 @@
+package adminapp
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+)
+
+type (
+	// RejectedTicks represents the ticks rejected by validation.
+	RejectedTicks struct {
+		Total  int            `json:"total"`
+		Counts map[string]int `json:"counts"` // keyed by reason
+		Ticks  []RejectedTick `json:"ticks"`  // most recent first, only the last ones are kept
+	}
+
+	// RejectedTick represents a single rejected tick.
+	RejectedTick struct {
+		Symbol     string          `json:"symbol"`
+		Price      decimal.Decimal `json:"price"`
+		Timestamp  string          `json:"timestamp"`
+		Reason     string          `json:"reason"`
+		Detail     string          `json:"detail"`
+		RejectedAt string          `json:"rejected_at"`
+	}
+)
+
+// Encode implements web.Encoder interface.
+func (r RejectedTicks) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+func toAppRejectedTicks(rejections pricebus.Rejections) RejectedTicks {
+	var total int
+	for _, count := range rejections.Counts {
+		total += count
+	}
+
+	ticks := make([]RejectedTick, 0, len(rejections.Ticks))
+	for _, r := range rejections.Ticks {
+		ticks = append(ticks, RejectedTick{
+			Symbol:     r.Price.Symbol,
+			Price:      r.Price.Price,
+			Timestamp:  r.Price.Timestamp,
+			Reason:     r.Reason,
+			Detail:     r.Detail,
+			RejectedAt: r.RejectedAt.Format(time.RFC3339),
+		})
+	}
+
+	return RejectedTicks{
+		Total:  total,
+		Counts: rejections.Counts,
+		Ticks:  ticks,
+	}
+}
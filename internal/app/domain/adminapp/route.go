@@ -0,0 +1,23 @@
+package adminapp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/mux"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+// Routes sets up the HTTP routes for the admin application.
+// The routes are only registered when an admin token is configured.
+func Routes(ctx context.Context, app *web.App, cfg mux.Config) {
+	const version = "v1"
+
+	if cfg.AdminConfig.Token == "" {
+		return
+	}
+
+	api := newApp(cfg.AdminConfig.PriceBus, cfg.AdminConfig.Token)
+
+	app.HandlerFunc(ctx, http.MethodGet, version, "/admin/rejected-ticks", api.rejectedTicks)
+}
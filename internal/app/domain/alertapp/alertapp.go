@@ -0,0 +1,120 @@
+package alertapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/alertbus"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+// maxBodySize is the maximum size of the body of a request to create a rule.
+const maxBodySize = 64 << 10
+
+type (
+	app struct {
+		alertBus AlertBusiness
+		symbols  []string
+	}
+
+	// AlertBusiness defines the interface for managing alert rules.
+	AlertBusiness interface {
+		Create(nr alertbus.NewRule) (alertbus.Rule, error)
+		Rules() []alertbus.Rule
+		Rule(id string) (alertbus.Rule, error)
+		Delete(id string) error
+	}
+)
+
+func newApp(alertBus AlertBusiness, symbols []string) *app {
+	return &app{
+		alertBus: alertBus,
+		symbols:  symbols,
+	}
+}
+
+// list returns every rule, oldest first.
+func (a *app) list(_ context.Context, _ *http.Request) web.Encoder {
+	return toAppRules(a.alertBus.Rules())
+}
+
+// create creates a rule, kept in memory until the service restarts. Its webhook secret is only returned in the response.
+func (a *app) create(_ context.Context, r *http.Request) web.Encoder {
+	var nr NewRule
+
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&nr); err != nil {
+		return web.NewError(http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	newRule, err := toBusNewRule(nr)
+	if err != nil {
+		return web.NewError(http.StatusBadRequest, err)
+	}
+
+	newRule.Symbol = strings.ToUpper(strings.TrimSpace(newRule.Symbol))
+	if newRule.Symbol != "" && !slices.Contains(a.symbols, newRule.Symbol) {
+		return web.NewError(http.StatusBadRequest, fmt.Errorf("unsupported symbol %q", nr.Symbol))
+	}
+
+	rule, err := a.alertBus.Create(newRule)
+	if err != nil {
+		if errors.Is(err, alertbus.ErrInvalidRule) {
+			return web.NewError(http.StatusBadRequest, err)
+		}
+
+		return web.NewError(http.StatusInternalServerError, err)
+	}
+
+	return CreatedRule{Rule: toAppRule(rule), WebhookSecret: rule.WebhookSecret}
+}
+
+// get returns the rule with the ID of the path.
+func (a *app) get(_ context.Context, r *http.Request) web.Encoder {
+	rule, err := a.alertBus.Rule(r.PathValue("id"))
+	if err != nil {
+		return toWebError(err)
+	}
+
+	return toAppRule(rule)
+}
+
+// delete deletes the rule with the ID of the path, replying with no content.
+func (a *app) delete(_ context.Context, r *http.Request) web.Encoder {
+	if err := a.alertBus.Delete(r.PathValue("id")); err != nil {
+		return toWebError(err)
+	}
+
+	return nil
+}
+
+func toWebError(err error) *web.Error {
+	if errors.Is(err, alertbus.ErrNotFound) {
+		return web.NewError(http.StatusNotFound, err)
+	}
+
+	return web.NewError(http.StatusInternalServerError, err)
+}
+
+// parseWindow parses the window of a rule, such as 15m. An empty window is left for the business to validate.
+func parseWindow(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid window %q", value)
+	}
+
+	return window, nil
+}
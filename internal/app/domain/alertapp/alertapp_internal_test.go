@@ -0,0 +1,113 @@
+package alertapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/alertbus"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+func TestAlerts(t *testing.T) {
+	server := newTestWebApp(t)
+
+	w := serve(t, server, http.MethodPost, "/v1/alerts",
+		`{"symbol":"btc","kind":"move","threshold":"3","window":"15m","webhook_url":"https://example.com/hook"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	id, _ := created["id"].(string)
+	require.NotEmpty(t, id)
+
+	assert.Equal(t, "BTC", created["symbol"])
+	assert.Equal(t, "move", created["kind"])
+	assert.Equal(t, "3", created["threshold"])
+	assert.Equal(t, "15m0s", created["window"])
+	assert.Len(t, created["webhook_secret"], 64)
+
+	// the secret is only returned on creation
+	w = serve(t, server, http.MethodGet, "/v1/alerts/"+id, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "webhook_secret")
+	assert.Contains(t, w.Body.String(), `"webhook_url":"https://example.com/hook"`)
+
+	w = serve(t, server, http.MethodGet, "/v1/alerts", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), `{"rules":[{"id":"`+id+`"`))
+
+	w = serve(t, server, http.MethodDelete, "/v1/alerts/"+id, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = serve(t, server, http.MethodDelete, "/v1/alerts/"+id, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"alert rule not found"}`, w.Body.String())
+
+	w = serve(t, server, http.MethodGet, "/v1/alerts", "")
+	assert.JSONEq(t, `{"rules":[]}`, w.Body.String())
+}
+
+func TestCreate_Invalid(t *testing.T) {
+	server := newTestWebApp(t)
+
+	tests := map[string]struct {
+		Body     string
+		Expected string
+	}{
+		"unknown field": {
+			Body:     `{"symbol":"BTC","kind":"stale","window":"5m","foo":1}`,
+			Expected: `{"error":"invalid request body: json: unknown field \"foo\""}`,
+		},
+		"invalid window": {
+			Body:     `{"symbol":"BTC","kind":"stale","window":"5 minutes"}`,
+			Expected: `{"error":"invalid window \"5 minutes\""}`,
+		},
+		"unsupported symbol": {
+			Body:     `{"symbol":"doge","kind":"stale","window":"5m"}`,
+			Expected: `{"error":"unsupported symbol \"doge\""}`,
+		},
+		"invalid rule": {
+			Body:     `{"symbol":"BTC","kind":"crosses"}`,
+			Expected: `{"error":"invalid alert rule: threshold must be a positive price"}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := serve(t, server, http.MethodPost, "/v1/alerts", test.Body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, test.Expected, w.Body.String())
+		})
+	}
+}
+
+func newTestWebApp(t *testing.T) *web.App {
+	t.Helper()
+
+	a := newApp(alertbus.NewBusiness(nil), []string{"BTC", "ETH"})
+
+	server := web.NewApp()
+	server.HandlerFunc(t.Context(), http.MethodGet, "v1", "/alerts", a.list)
+	server.HandlerFunc(t.Context(), http.MethodPost, "v1", "/alerts", a.create)
+	server.HandlerFunc(t.Context(), http.MethodGet, "v1", "/alerts/{id}", a.get)
+	server.HandlerFunc(t.Context(), http.MethodDelete, "v1", "/alerts/{id}", a.delete)
+
+	return server
+}
+
+func serve(t *testing.T, server *web.App, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), method, path, strings.NewReader(body)))
+
+	return w
+}
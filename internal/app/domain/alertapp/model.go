@@ -0,0 +1,110 @@
+package alertapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/alertbus"
+)
+
+type (
+	// NewRule represents the request to create an alert rule.
+	NewRule struct {
+		Symbol        string          `json:"symbol"`
+		Kind          string          `json:"kind"`           // crosses, move or stale
+		Threshold     decimal.Decimal `json:"threshold"`      // price for crosses, percentage for move
+		Window        string          `json:"window"`         // duration such as 15m, for move and stale
+		WebhookURL    string          `json:"webhook_url"`    // optional
+		WebhookSecret string          `json:"webhook_secret"` // generated when a webhook has none
+	}
+
+	// Rule represents an alert rule.
+	Rule struct {
+		ID         string          `json:"id"`
+		Symbol     string          `json:"symbol"`
+		Kind       string          `json:"kind"`
+		Threshold  decimal.Decimal `json:"threshold"`
+		Window     string          `json:"window,omitempty"`
+		WebhookURL string          `json:"webhook_url,omitempty"`
+		CreatedAt  string          `json:"created_at"`
+	}
+
+	// Rules represents a list of alert rules.
+	Rules struct {
+		Rules []Rule `json:"rules"`
+	}
+
+	// CreatedRule represents a rule just created, the only time its webhook secret is returned.
+	CreatedRule struct {
+		Rule
+		WebhookSecret string `json:"webhook_secret,omitempty"`
+	}
+)
+
+// Encode implements web.Encoder interface.
+func (r Rule) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// Encode implements web.Encoder interface.
+func (r Rules) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// Encode implements web.Encoder interface.
+func (c CreatedRule) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(c)
+	return data, "application/json", err
+}
+
+// StatusCode implements web.StatusCoder interface.
+func (CreatedRule) StatusCode() int {
+	return http.StatusCreated
+}
+
+func toBusNewRule(nr NewRule) (alertbus.NewRule, error) {
+	window, err := parseWindow(nr.Window)
+	if err != nil {
+		return alertbus.NewRule{}, err
+	}
+
+	return alertbus.NewRule{
+		Symbol:        nr.Symbol,
+		Kind:          nr.Kind,
+		Threshold:     nr.Threshold,
+		Window:        window,
+		WebhookURL:    nr.WebhookURL,
+		WebhookSecret: nr.WebhookSecret,
+	}, nil
+}
+
+func toAppRule(rule alertbus.Rule) Rule {
+	var window string
+	if rule.Window > 0 {
+		window = rule.Window.String()
+	}
+
+	return Rule{
+		ID:         rule.ID,
+		Symbol:     rule.Symbol,
+		Kind:       rule.Kind,
+		Threshold:  rule.Threshold,
+		Window:     window,
+		WebhookURL: rule.WebhookURL,
+		CreatedAt:  rule.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toAppRules(rules []alertbus.Rule) Rules {
+	appRules := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		appRules = append(appRules, toAppRule(rule))
+	}
+
+	return Rules{Rules: appRules}
+}
@@ -0,0 +1,22 @@
+package alertapp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/mux"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+// Routes sets up the HTTP routes for the alert application.
+// Rules are kept in memory only, so they are lost on restart and must be created again.
+func Routes(ctx context.Context, app *web.App, cfg mux.Config) {
+	const version = "v1"
+
+	api := newApp(cfg.AlertConfig.AlertBus, cfg.PriceConfig.Symbols)
+
+	app.HandlerFunc(ctx, http.MethodGet, version, "/alerts", api.list)
+	app.HandlerFunc(ctx, http.MethodPost, version, "/alerts", api.create)
+	app.HandlerFunc(ctx, http.MethodGet, version, "/alerts/{id}", api.get)
+	app.HandlerFunc(ctx, http.MethodDelete, version, "/alerts/{id}", api.delete)
+}
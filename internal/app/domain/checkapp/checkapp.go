@@ -5,22 +5,43 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
 	"github.com/gandarez/btc-price-service/internal/foundation/version"
 	"github.com/gandarez/btc-price-service/internal/foundation/web"
 )
 
-type app struct {
-}
+type (
+	app struct {
+		upstream UpstreamChecker
+	}
+
+	// UpstreamChecker defines the interface for checking the state of the upstream price source.
+	UpstreamChecker interface {
+		UpstreamState() breaker.State
+	}
+)
 
-func newApp() *app {
-	return &app{}
+func newApp(upstream UpstreamChecker) *app {
+	return &app{
+		upstream: upstream,
+	}
 }
 
-func (*app) readiness(_ context.Context, _ *http.Request) web.Encoder {
-	return nil
+func (a *app) readiness(_ context.Context, _ *http.Request) web.Encoder {
+	state := a.upstream.UpstreamState()
+
+	status := "OK"
+	if state == breaker.StateOpen {
+		status = "DEGRADED"
+	}
+
+	return Readiness{
+		Status:   status,
+		Upstream: state.String(),
+	}
 }
 
-func (*app) liveness(_ context.Context, _ *http.Request) web.Encoder {
+func (a *app) liveness(_ context.Context, _ *http.Request) web.Encoder {
 	host, err := os.Hostname()
 	if err != nil {
 		host = "unknown"
@@ -30,5 +51,6 @@ func (*app) liveness(_ context.Context, _ *http.Request) web.Encoder {
 		Status:   "OK",
 		Version:  version.Version,
 		Hostname: host,
+		Upstream: a.upstream.UpstreamState().String(),
 	}
 }
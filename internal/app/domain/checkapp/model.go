@@ -2,15 +2,31 @@ package checkapp
 
 import "encoding/json"
 
-// Info represents the health check information.
-type Info struct {
-	Status   string `json:"status"`
-	Version  string `json:"version"`
-	Hostname string `json:"hostname"`
-}
+type (
+	// Info represents the health check information.
+	Info struct {
+		Status   string `json:"status"`
+		Version  string `json:"version"`
+		Hostname string `json:"hostname"`
+		Upstream string `json:"upstream"`
+	}
+
+	// Readiness represents the readiness check information.
+	// The service stays ready while the upstream is unavailable, since it can still serve cached prices.
+	Readiness struct {
+		Status   string `json:"status"`
+		Upstream string `json:"upstream"`
+	}
+)
 
 // Encode implements web.Encoder interface.
 func (i Info) Encode() ([]byte, string, error) {
 	data, err := json.Marshal(i)
 	return data, "application/json", err
 }
+
+// Encode implements web.Encoder interface.
+func (r Readiness) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
@@ -4,14 +4,15 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/gandarez/btc-price-service/internal/app/sdk/mux"
 	"github.com/gandarez/btc-price-service/internal/foundation/web"
 )
 
 // Routes sets up the HTTP routes for the check/healthcheck application.
-func Routes(ctx context.Context, app *web.App) {
+func Routes(ctx context.Context, app *web.App, cfg mux.Config) {
 	const version = "v1"
 
-	api := newApp()
+	api := newApp(cfg.CheckConfig.PriceBus)
 
 	app.HandlerFunc(ctx, http.MethodGet, version, "/readiness", api.readiness)
 	app.HandlerFunc(ctx, http.MethodGet, version, "/liveness", api.liveness)
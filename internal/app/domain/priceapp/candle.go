@@ -0,0 +1,234 @@
+package priceapp
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// candles aggregates the published prices into OHLC bars, for each symbol and interval.
+	candles struct {
+		intervals []time.Duration
+		maxBars   int // completed bars kept per symbol and interval
+		series    map[candleKey]*candleSeries
+		mu        sync.RWMutex
+	}
+
+	candleKey struct {
+		symbol   string
+		interval time.Duration
+	}
+
+	// candleSeries holds the bar in progress, if any, and the last completed ones, oldest first.
+	candleSeries struct {
+		current   *candleBar
+		last      time.Time // start of the last bar, prices before its end are too late for a new one
+		completed []candleBar
+	}
+
+	candleBar struct {
+		start                  time.Time
+		open, high, low, close decimal.Decimal
+		ticks                  int
+	}
+)
+
+func newCandles(intervals []time.Duration, maxBars int) *candles {
+	return &candles{
+		intervals: intervals,
+		maxBars:   maxBars,
+		series:    make(map[candleKey]*candleSeries),
+	}
+}
+
+// add adds the price to the bar in progress of each interval, completing it when the price belongs to the next one.
+// It returns the bars of the symbol updated by the price, in the order of the intervals: the bar completed, if any,
+// then the bar in progress. Prices older than the last bar are skipped.
+func (c *candles) add(price Price) []Candle {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ts := price.Timestamp()
+
+	bars := make([]Candle, 0, len(c.intervals))
+
+	for _, interval := range c.intervals {
+		key := candleKey{symbol: price.Symbol, interval: interval}
+		start := ts.Truncate(interval)
+
+		s, ok := c.series[key]
+		if !ok {
+			s = &candleSeries{}
+			c.series[key] = s
+		}
+
+		switch {
+		case s.current != nil && start.Equal(s.current.start):
+			s.current.update(price.Price)
+		case ok && !start.After(s.last):
+			continue
+		default:
+			if s.current != nil {
+				bars = append(bars, c.complete(s, key))
+			}
+
+			bar := newCandleBar(start, price.Price)
+			s.current = &bar
+			s.last = start
+		}
+
+		bars = append(bars, toAppCandle(price.Symbol, interval, *s.current, false))
+	}
+
+	return bars
+}
+
+// close completes the bars in progress whose interval ended by now, so they are completed on time even when
+// no later price comes. It returns them ordered by open time, symbol and close time.
+func (c *candles) close(now time.Time) []Candle {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var bars []Candle
+
+	for key, s := range c.series {
+		if s.current != nil && !s.current.start.Add(key.interval).After(now) {
+			bars = append(bars, c.complete(s, key))
+		}
+	}
+
+	slices.SortFunc(bars, func(x, y Candle) int {
+		return cmp.Or(
+			cmp.Compare(x.OpenTime, y.OpenTime),
+			cmp.Compare(x.Symbol, y.Symbol),
+			cmp.Compare(x.CloseTime, y.CloseTime),
+		)
+	})
+
+	return bars
+}
+
+// next returns when the next interval ends after now.
+func (c *candles) next(now time.Time) time.Time {
+	var next time.Time
+
+	for _, interval := range c.intervals {
+		if end := now.Truncate(interval).Add(interval); next.IsZero() || end.Before(next) {
+			next = end
+		}
+	}
+
+	return next
+}
+
+// complete moves the bar in progress of the series to the completed ones and returns it.
+// It must be called with mu held.
+func (c *candles) complete(s *candleSeries, key candleKey) Candle {
+	s.completed = append(s.completed, *s.current)
+	if len(s.completed) > c.maxBars {
+		s.completed = s.completed[len(s.completed)-c.maxBars:]
+	}
+
+	bar := toAppCandle(key.symbol, key.interval, *s.current, true)
+	s.current = nil
+
+	return bar
+}
+
+// completed returns the last completed bars of the symbol and interval, oldest first.
+// A limit of 0 returns every bar kept.
+func (c *candles) completed(symbol string, interval time.Duration, limit int) []Candle {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	bars := make([]Candle, 0)
+
+	s, ok := c.series[candleKey{symbol: symbol, interval: interval}]
+	if !ok {
+		return bars
+	}
+
+	completed := s.completed
+	if limit > 0 && len(completed) > limit {
+		completed = completed[len(completed)-limit:]
+	}
+
+	for _, bar := range completed {
+		bars = append(bars, toAppCandle(symbol, interval, bar, true))
+	}
+
+	return bars
+}
+
+func newCandleBar(start time.Time, price decimal.Decimal) candleBar {
+	return candleBar{
+		start: start,
+		open:  price,
+		high:  price,
+		low:   price,
+		close: price,
+		ticks: 1,
+	}
+}
+
+func (b *candleBar) update(price decimal.Decimal) {
+	b.high = decimal.Max(b.high, price)
+	b.low = decimal.Min(b.low, price)
+	b.close = price
+	b.ticks++
+}
+
+// parseInterval parses a candle interval, such as 1m or 1h.
+func parseInterval(value string) (time.Duration, error) {
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid candle interval %q", value)
+	}
+
+	if interval <= 0 {
+		return 0, fmt.Errorf("candle interval %q must be positive", value)
+	}
+
+	return interval, nil
+}
+
+// candleTopic returns the topic the bars of a symbol for an interval are broadcast to.
+func candleTopic(symbol, interval string) string {
+	return "candles:" + symbol + ":" + interval
+}
+
+// candleTopics returns the topics of the bars of the symbols for the intervals.
+func candleTopics(symbols, intervals []string) []string {
+	topics := make([]string, 0, len(symbols)*len(intervals))
+
+	for _, symbol := range symbols {
+		for _, interval := range intervals {
+			topics = append(topics, candleTopic(symbol, interval))
+		}
+	}
+
+	return topics
+}
+
+// isCandleTopic reports whether the topic is the one of the bars of a symbol.
+func isCandleTopic(topic string) bool {
+	return strings.HasPrefix(topic, "candles:")
+}
+
+// formatInterval formats a candle interval in its largest whole unit, such as 1m instead of 1m0s.
+func formatInterval(interval time.Duration) string {
+	switch {
+	case interval%time.Hour == 0:
+		return fmt.Sprintf("%dh", interval/time.Hour)
+	case interval%time.Minute == 0:
+		return fmt.Sprintf("%dm", interval/time.Minute)
+	default:
+		return interval.String()
+	}
+}
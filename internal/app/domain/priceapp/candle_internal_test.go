@@ -0,0 +1,322 @@
+package priceapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+func TestCandles_Add(t *testing.T) {
+	c := newCandles([]time.Duration{time.Minute, 5 * time.Minute}, 2)
+
+	add := func(ts, price string) []Candle {
+		return c.add(Price{Symbol: "BTC", UpdatedAt: ts, Price: decimal.RequireFromString(price)})
+	}
+
+	add("2025-01-01T10:00:10Z", "100")
+	add("2025-01-01T10:00:20Z", "105")
+	add("2025-01-01T10:00:30Z", "100")
+	add("2025-01-01T10:00:35Z", "95")
+	bars := add("2025-01-01T10:00:40Z", "101")
+
+	require.Len(t, bars, 2)
+	assert.Equal(t, Candle{
+		Symbol:    "BTC",
+		Interval:  "1m",
+		OpenTime:  "2025-01-01T10:00:00Z",
+		CloseTime: "2025-01-01T10:01:00Z",
+		Open:      decimal.RequireFromString("100"),
+		High:      decimal.RequireFromString("105"),
+		Low:       decimal.RequireFromString("95"),
+		Close:     decimal.RequireFromString("101"),
+		Ticks:     5,
+	}, bars[0])
+	assert.Equal(t, "5m", bars[1].Interval)
+	assert.Equal(t, "2025-01-01T10:05:00Z", bars[1].CloseTime)
+	assert.False(t, bars[1].Closed)
+
+	assert.Empty(t, c.completed("BTC", time.Minute, 0))
+
+	// the next minute completes the bar, and older prices are skipped
+	bars = add("2025-01-01T10:01:05Z", "110")
+	late := add("2025-01-01T10:00:50Z", "1")
+
+	require.Len(t, bars, 3)
+	assert.Equal(t, "2025-01-01T10:00:00Z", bars[0].OpenTime)
+	assert.True(t, bars[0].Closed)
+	assert.Equal(t, 5, bars[0].Ticks)
+	assert.Equal(t, "2025-01-01T10:01:00Z", bars[1].OpenTime)
+	assert.False(t, bars[1].Closed)
+	assert.Equal(t, 1, bars[1].Ticks)
+	assert.Equal(t, 6, bars[2].Ticks)
+
+	require.Len(t, late, 1)
+	assert.Equal(t, "5m", late[0].Interval)
+	assert.Equal(t, 7, late[0].Ticks)
+
+	completed := c.completed("BTC", time.Minute, 0)
+	require.Len(t, completed, 1)
+	assert.Equal(t, "101", completed[0].Close.String())
+	assert.Equal(t, 5, completed[0].Ticks)
+	assert.True(t, completed[0].Closed)
+
+	// only the last bars are kept, and bars without prices are not created
+	add("2025-01-01T10:03:00Z", "120")
+	add("2025-01-01T10:04:00Z", "130")
+
+	completed = c.completed("BTC", time.Minute, 0)
+	require.Len(t, completed, 2)
+	assert.Equal(t, "2025-01-01T10:01:00Z", completed[0].OpenTime)
+	assert.Equal(t, "2025-01-01T10:03:00Z", completed[1].OpenTime)
+
+	completed = c.completed("BTC", time.Minute, 1)
+	require.Len(t, completed, 1)
+	assert.Equal(t, "2025-01-01T10:03:00Z", completed[0].OpenTime)
+
+	assert.Empty(t, c.completed("ETH", time.Minute, 0))
+	assert.Empty(t, c.completed("BTC", 5*time.Minute, 0))
+}
+
+func TestCandles_Close(t *testing.T) {
+	c := newCandles([]time.Duration{time.Minute, 5 * time.Minute}, 10)
+
+	c.add(Price{Symbol: "ETH", UpdatedAt: "2025-01-01T10:00:10Z", Price: decimal.RequireFromString("3000")})
+	c.add(Price{Symbol: "BTC", UpdatedAt: "2025-01-01T10:00:20Z", Price: decimal.RequireFromString("50000")})
+
+	assert.Empty(t, c.close(time.Date(2025, 1, 1, 10, 0, 59, 0, time.UTC)))
+
+	bars := c.close(time.Date(2025, 1, 1, 10, 1, 0, 0, time.UTC))
+	require.Len(t, bars, 2)
+	assert.Equal(t, "BTC", bars[0].Symbol)
+	assert.Equal(t, "ETH", bars[1].Symbol)
+
+	for _, bar := range bars {
+		assert.Equal(t, "1m", bar.Interval)
+		assert.True(t, bar.Closed)
+	}
+
+	// closed bars are not closed again, and a price after the end opens a new bar
+	assert.Empty(t, c.close(time.Date(2025, 1, 1, 10, 2, 0, 0, time.UTC)))
+
+	bars = c.add(Price{Symbol: "BTC", UpdatedAt: "2025-01-01T10:00:50Z", Price: decimal.RequireFromString("1")})
+	require.Len(t, bars, 1)
+	assert.Equal(t, "5m", bars[0].Interval)
+
+	bars = c.add(Price{Symbol: "BTC", UpdatedAt: "2025-01-01T10:02:10Z", Price: decimal.RequireFromString("50100")})
+	require.Len(t, bars, 2)
+	assert.Equal(t, "2025-01-01T10:02:00Z", bars[0].OpenTime)
+	assert.Equal(t, 3, bars[1].Ticks)
+
+	bars = c.close(time.Date(2025, 1, 1, 10, 5, 0, 0, time.UTC))
+	require.Len(t, bars, 3)
+	assert.Equal(t, []string{"5m", "5m", "1m"}, []string{bars[0].Interval, bars[1].Interval, bars[2].Interval})
+	assert.Equal(t, []string{"BTC", "ETH", "BTC"}, []string{bars[0].Symbol, bars[1].Symbol, bars[2].Symbol})
+
+	assert.Len(t, c.completed("BTC", time.Minute, 0), 2)
+	assert.Len(t, c.completed("BTC", 5*time.Minute, 0), 1)
+}
+
+func TestCandles_Next(t *testing.T) {
+	c := newCandles([]time.Duration{5 * time.Minute, time.Minute}, 10)
+
+	now := time.Date(2025, 1, 1, 10, 3, 20, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 1, 1, 10, 4, 0, 0, time.UTC), c.next(now))
+	assert.Equal(t, time.Date(2025, 1, 1, 10, 5, 0, 0, time.UTC), c.next(time.Date(2025, 1, 1, 10, 4, 0, 0, time.UTC)))
+}
+
+func TestCandleHistory(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+	a.cfg.CandleIntervals = []time.Duration{time.Minute, time.Hour}
+	a.candles = newCandles(a.cfg.CandleIntervals, 10)
+
+	publishTestPrice(t.Context(), a, "BTC", "2025-01-01T10:00:00Z", "50000")
+	publishTestPrice(t.Context(), a, "BTC", "2025-01-01T10:01:00Z", "50000.1")
+	publishTestPrice(t.Context(), a, "BTC", "2025-01-01T10:02:00Z", "50000.2")
+
+	server := web.NewApp()
+	server.HandlerFunc(t.Context(), http.MethodGet, "v1", "/candles", a.candleHistory)
+
+	tests := map[string]struct {
+		Path     string
+		Status   int
+		Expected string
+	}{
+		"default": {
+			Path:   "/v1/candles",
+			Status: http.StatusOK,
+			Expected: `{"symbol":"BTC","interval":"1m","candles":[` +
+				`{"symbol":"BTC","interval":"1m","open_time":"2025-01-01T10:00:00Z","close_time":"2025-01-01T10:01:00Z",` +
+				`"open":"50000","high":"50000","low":"50000","close":"50000","ticks":1,"closed":true},` +
+				`{"symbol":"BTC","interval":"1m","open_time":"2025-01-01T10:01:00Z","close_time":"2025-01-01T10:02:00Z",` +
+				`"open":"50000.1","high":"50000.1","low":"50000.1","close":"50000.1","ticks":1,"closed":true}]}`,
+		},
+		"limit": {
+			Path:   "/v1/candles?symbol=btc&interval=1m&limit=1",
+			Status: http.StatusOK,
+			Expected: `{"symbol":"BTC","interval":"1m","candles":[` +
+				`{"symbol":"BTC","interval":"1m","open_time":"2025-01-01T10:01:00Z","close_time":"2025-01-01T10:02:00Z",` +
+				`"open":"50000.1","high":"50000.1","low":"50000.1","close":"50000.1","ticks":1,"closed":true}]}`,
+		},
+		"no completed bar": {
+			Path:     "/v1/candles?interval=60m",
+			Status:   http.StatusOK,
+			Expected: `{"symbol":"BTC","interval":"1h","candles":[]}`,
+		},
+		"unsupported interval": {
+			Path:     "/v1/candles?interval=5m",
+			Status:   http.StatusBadRequest,
+			Expected: `{"error":"unsupported candle interval \"5m\""}`,
+		},
+		"invalid interval": {
+			Path:     "/v1/candles?interval=minute",
+			Status:   http.StatusBadRequest,
+			Expected: `{"error":"invalid candle interval \"minute\""}`,
+		},
+		"unsupported symbol": {
+			Path:     "/v1/candles?symbol=DOGE",
+			Status:   http.StatusBadRequest,
+			Expected: `{"error":"unsupported symbol \"DOGE\""}`,
+		},
+		"invalid limit": {
+			Path:     "/v1/candles?limit=0",
+			Status:   http.StatusBadRequest,
+			Expected: `{"error":"invalid limit \"0\""}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, test.Path, nil))
+
+			assert.Equal(t, test.Status, w.Code)
+			assert.JSONEq(t, test.Expected, w.Body.String())
+		})
+	}
+}
+
+func TestCandleHistory_Disabled(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	resp := a.candleHistory(t.Context(), httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/candles", nil))
+
+	var webErr *web.Error
+
+	require.ErrorAs(t, resp.(error), &webErr)
+	assert.Equal(t, http.StatusNotFound, webErr.StatusCode())
+}
+
+func TestPriceStream_Candles(t *testing.T) {
+	tests := map[string]struct {
+		Path     string
+		Expected string
+	}{
+		"opted in": {
+			Path: "/v1/price-stream?candles=60s",
+			Expected: "event: candles\n" +
+				`data: {"symbol":"BTC","candles":[{"symbol":"BTC","interval":"1m","open_time":"2025-01-01T10:00:00Z",` +
+				`"close_time":"2025-01-01T10:01:00Z","open":"50000","high":"50000","low":"50000","close":"50000",` +
+				`"ticks":1,"closed":false}],"timestamp":"2025-01-01T10:00:00Z"}`,
+		},
+		"not opted in": {
+			Path: "/v1/price-stream",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := newTestApp(&mockPriceBusiness{}, "BTC")
+			a.cfg.CandleIntervals = []time.Duration{time.Minute}
+			a.candles = newCandles(a.cfg.CandleIntervals, 10)
+
+			ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+			defer cancel()
+
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				publishTestPrice(t.Context(), a, "BTC", "2025-01-01T10:00:00Z", "50000")
+			}()
+
+			w := httptest.NewRecorder()
+			a.priceStream(w, httptest.NewRequestWithContext(ctx, http.MethodGet, test.Path, nil))
+
+			assert.Contains(t, w.Body.String(), "event: price\n")
+
+			if test.Expected == "" {
+				assert.NotContains(t, w.Body.String(), "event: candles")
+				return
+			}
+
+			assert.Contains(t, w.Body.String(), test.Expected)
+		})
+	}
+}
+
+func TestPriceStream_CandlesClosed(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+	a.cfg.CandleIntervals = []time.Duration{time.Minute}
+	a.candles = newCandles(a.cfg.CandleIntervals, 10)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		publishTestPrice(t.Context(), a, "BTC", "2025-01-01T10:00:00Z", "50000")
+		// the same price counts in the bar, which is closed at the end of its interval without a later price
+		publishTestPrice(t.Context(), a, "BTC", "2025-01-01T10:00:30Z", "50000")
+		a.closeCandles(t.Context(), time.Date(2025, 1, 1, 10, 1, 0, 0, time.UTC))
+	}()
+
+	w := httptest.NewRecorder()
+	a.priceStream(w, httptest.NewRequestWithContext(ctx, http.MethodGet, "/v1/price-stream?candles=1m", nil))
+
+	assert.Contains(t, w.Body.String(), `"ticks":2,"closed":false}`)
+	assert.Contains(t, w.Body.String(), `"ticks":2,"closed":true}],"timestamp":"2025-01-01T10:01:00Z"}`)
+	assert.Len(t, a.candles.completed("BTC", time.Minute, 0), 1)
+}
+
+func TestParseCandles(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	_, err := a.parseCandles("1m")
+	require.EqualError(t, err, "candles are disabled")
+
+	intervals, err := a.parseCandles("")
+	require.NoError(t, err)
+	assert.Empty(t, intervals)
+
+	a.cfg.CandleIntervals = []time.Duration{time.Minute, time.Hour}
+
+	intervals, err = a.parseCandles("1m, 60m,1h,60s")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1m", "1h"}, intervals)
+
+	_, err = a.parseCandles("5m")
+	require.EqualError(t, err, `unsupported candle interval "5m"`)
+
+	_, err = a.parseCandles("minute")
+	require.EqualError(t, err, `invalid candle interval "minute"`)
+}
+
+func TestFormatInterval(t *testing.T) {
+	assert.Equal(t, "1m", formatInterval(time.Minute))
+	assert.Equal(t, "15m", formatInterval(15*time.Minute))
+	assert.Equal(t, "4h", formatInterval(4*time.Hour))
+	assert.Equal(t, "1m30s", formatInterval(90*time.Second))
+	assert.Equal(t, "1.5s", formatInterval(1500*time.Millisecond))
+}
+
+func publishTestPrice(ctx context.Context, a *app, symbol, ts, price string) {
+	a.publish(ctx, pricebus.Price{Symbol: symbol, Timestamp: ts, Price: decimal.RequireFromString(price)})
+}
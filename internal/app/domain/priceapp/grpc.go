@@ -0,0 +1,295 @@
+package priceapp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pricepb"
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pubsub"
+	"github.com/gandarez/btc-price-service/internal/foundation/cache"
+	"github.com/gandarez/btc-price-service/internal/foundation/log"
+)
+
+// grpcServer implements the gRPC price service on top of the cache and broadcaster of the price application.
+type grpcServer struct {
+	pricepb.UnimplementedPriceServiceServer
+
+	app *app
+	ctx context.Context // context of the service, only done when shutting down
+}
+
+// StreamPrices implements pricepb.PriceServiceServer interface.
+func (s *grpcServer) StreamPrices(
+	req *pricepb.StreamPricesRequest,
+	stream grpc.ServerStreamingServer[pricepb.StreamPricesResponse],
+) error {
+	logger := log.Extract(s.ctx)
+
+	symbols, err := s.app.parseSymbols(strings.Join(req.GetSymbols(), ","))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	candles, err := s.app.parseCandles(strings.Join(req.GetCandles(), ","))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	topics := append(slices.Clone(symbols), candleTopics(symbols, candles)...)
+
+	from := replay{resume: req.LastEventId != nil, lastEventID: req.GetLastEventId()}
+
+	sub, fill := s.app.subscribe(s.ctx, s.app.cfg.Overflow, topics, symbols, from)
+	defer s.app.broadcaster.Unsubscribe(s.ctx, sub)
+
+	// ID of the last price sent by symbol, so the ones already replayed are not sent twice
+	sent := make(map[string]uint64, len(symbols))
+
+	send := func(update cache.CacheableEntity) error {
+		if price, ok := update.(Price); ok {
+			if price.ID <= sent[price.Symbol] {
+				return nil
+			}
+
+			sent[price.Symbol] = price.ID
+		}
+
+		event, ok := toPBEvent(update)
+		if !ok {
+			return nil
+		}
+
+		return stream.Send(event)
+	}
+
+	if err := s.app.sendBackfill(fill, send); err != nil {
+		return err
+	}
+
+	fill.mark(sent, symbols)
+
+	logger.Infoln("client connected to grpc price stream")
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			logger.Infoln("service shutting down, closing grpc price stream")
+
+			return send(toAppShutdown(s.app.retryHint(s.app.cfg.ShutdownRetry)))
+		case <-stream.Context().Done():
+			logger.Infoln("client disconnected from grpc price stream")
+
+			return stream.Context().Err()
+		case update, ok := <-sub.Ch:
+			if !ok {
+				// disconnected by the overflow policy, after the gap
+				return status.Errorf(codes.ResourceExhausted, "client too slow, dropped %d updates", sub.Dropped())
+			}
+
+			if gap, ok := update.(pubsub.Gap); ok {
+				update = toAppDropGap(gap, lastSent(sent), symbols)
+			}
+
+			if err := send(update); err != nil {
+				logger.Infof("client disconnected from grpc price stream (send failed): %s", err)
+
+				return err
+			}
+		}
+	}
+}
+
+// GetLatestPrice implements pricepb.PriceServiceServer interface.
+func (s *grpcServer) GetLatestPrice(
+	_ context.Context,
+	req *pricepb.GetLatestPriceRequest,
+) (*pricepb.GetLatestPriceResponse, error) {
+	symbol := normalizeSymbol(req.GetSymbol())
+	if symbol == "" && len(s.app.cfg.Symbols) > 0 {
+		symbol = s.app.cfg.Symbols[0]
+	}
+
+	buffer, ok := s.app.caches[symbol]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported symbol %q", symbol)
+	}
+
+	last, ok := buffer.Last().(Price)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no price available for %s yet", symbol)
+	}
+
+	return &pricepb.GetLatestPriceResponse{Price: toPBPrice(last)}, nil
+}
+
+// Proto implements web.Protoer interface. The price is encoded as a stream event.
+func (p Price) Proto() proto.Message {
+	return protoEvent(p)
+}
+
+// Proto implements web.Protoer interface. The status is encoded as a stream event.
+func (s Status) Proto() proto.Message {
+	return protoEvent(s)
+}
+
+// Proto implements web.Protoer interface. The gap is encoded as a stream event.
+func (g Gap) Proto() proto.Message {
+	return protoEvent(g)
+}
+
+// Proto implements web.Protoer interface. The shutdown is encoded as a stream event.
+func (s Shutdown) Proto() proto.Message {
+	return protoEvent(s)
+}
+
+// Proto implements web.Protoer interface. The candles are encoded as a stream event.
+func (c Candles) Proto() proto.Message {
+	return protoEvent(c)
+}
+
+// Proto implements web.Protoer interface. The alert is encoded as a stream event.
+func (a Alert) Proto() proto.Message {
+	return protoEvent(a)
+}
+
+// Proto implements web.Protoer interface. The snapshot is encoded as the price, without its age.
+func (s Snapshot) Proto() proto.Message {
+	return toPBPrice(s.Price)
+}
+
+// protoEvent converts an update to a stream event, or nil for updates without an event.
+func protoEvent(update cache.CacheableEntity) proto.Message {
+	event, ok := toPBEvent(update)
+	if !ok {
+		return nil
+	}
+
+	return event
+}
+
+// toPBEvent converts an update to a stream event. It reports false for updates without an event.
+func toPBEvent(update cache.CacheableEntity) (*pricepb.StreamPricesResponse, bool) {
+	var resp pricepb.StreamPricesResponse
+
+	switch u := update.(type) {
+	case Price:
+		resp.Event = &pricepb.StreamPricesResponse_Price{Price: toPBPrice(u)}
+	case Status:
+		resp.Event = &pricepb.StreamPricesResponse_Status{Status: &pricepb.Status{
+			Upstream:  u.Upstream,
+			Available: u.Available,
+			Timestamp: toPBTimestamp(u.UpdatedAt),
+		}}
+	case Gap:
+		resp.Event = &pricepb.StreamPricesResponse_Gap{Gap: &pricepb.Gap{
+			LastEventId:  u.LastEventID,
+			Symbols:      u.Symbols,
+			Timestamp:    toPBTimestamp(u.UpdatedAt),
+			Missed:       u.Missed,
+			Disconnected: u.Disconnected,
+		}}
+	case Shutdown:
+		resp.Event = &pricepb.StreamPricesResponse_Shutdown{Shutdown: &pricepb.Shutdown{
+			RetryMs:   u.Retry,
+			Timestamp: toPBTimestamp(u.UpdatedAt),
+		}}
+	case Candles:
+		resp.Event = &pricepb.StreamPricesResponse_Candles{Candles: toPBCandles(u)}
+	case Alert:
+		resp.Event = &pricepb.StreamPricesResponse_Alert{Alert: &pricepb.Alert{
+			RuleId:    u.RuleID,
+			Symbol:    u.Symbol,
+			Kind:      u.Kind,
+			Price:     u.Price.String(),
+			Message:   u.Message,
+			Timestamp: toPBTimestamp(u.UpdatedAt),
+		}}
+	default:
+		return nil, false
+	}
+
+	return &resp, true
+}
+
+func toPBPrice(p Price) *pricepb.Price {
+	sources := make([]*pricepb.SourceQuote, 0, len(p.Sources))
+
+	for _, q := range p.Sources {
+		sources = append(sources, &pricepb.SourceQuote{
+			Source:    q.Source,
+			Timestamp: toPBTimestamp(q.UpdatedAt),
+			Price:     q.Price.String(),
+		})
+	}
+
+	return &pricepb.Price{
+		Id:                p.ID,
+		Symbol:            p.Symbol,
+		Timestamp:         toPBTimestamp(p.UpdatedAt),
+		Price:             p.Price.String(),
+		MarketCap:         optionalString(p.MarketCap),
+		Volume_24H:        optionalString(p.Volume24h),
+		Change_24H:        optionalString(p.Change24h),
+		ChangePct_24H:     optionalString(p.ChangePct24h),
+		CirculatingSupply: optionalString(p.CirculatingSupply),
+		Sources:           sources,
+		PrevPrice:         optionalString(p.PrevPrice),
+		Change:            optionalString(p.Change),
+		ChangePct:         optionalString(p.ChangePct),
+		DayOpen:           optionalString(p.DayOpen),
+		DayChange:         optionalString(p.DayChange),
+		DayChangePct:      optionalString(p.DayChangePct),
+		Aggregation:       p.Aggregation,
+	}
+}
+
+func toPBCandles(c Candles) *pricepb.Candles {
+	bars := make([]*pricepb.Candle, 0, len(c.Candles))
+
+	for _, candle := range c.Candles {
+		bars = append(bars, &pricepb.Candle{
+			Interval:  candle.Interval,
+			OpenTime:  toPBTimestamp(candle.OpenTime),
+			CloseTime: toPBTimestamp(candle.CloseTime),
+			Open:      candle.Open.String(),
+			High:      candle.High.String(),
+			Low:       candle.Low.String(),
+			Close:     candle.Close.String(),
+			Ticks:     int64(candle.Ticks),
+			Closed:    candle.Closed,
+		})
+	}
+
+	return &pricepb.Candles{
+		Symbol:    c.Symbol,
+		Candles:   bars,
+		Timestamp: toPBTimestamp(c.UpdatedAt),
+	}
+}
+
+// optionalString returns the value as a string, or nil when unknown.
+func optionalString(value *decimal.Decimal) *string {
+	if value == nil {
+		return nil
+	}
+
+	s := value.String()
+
+	return &s
+}
+
+// toPBTimestamp converts an RFC3339 timestamp, as formatted by the business layer.
+func toPBTimestamp(rfc3339 string) *timestamppb.Timestamp {
+	t, _ := time.Parse(time.RFC3339, rfc3339)
+
+	return timestamppb.New(t)
+}
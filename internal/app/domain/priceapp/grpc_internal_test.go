@@ -0,0 +1,212 @@
+package priceapp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pricepb"
+)
+
+func TestGRPC_GetLatestPrice(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+
+	publishPrices(t, a, "BTC", "50000", "50000.5")
+
+	client := dialTestGRPC(t.Context(), t, a)
+
+	resp, err := client.GetLatestPrice(t.Context(), &pricepb.GetLatestPriceRequest{Symbol: "btc"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "BTC", resp.GetPrice().GetSymbol())
+	assert.Equal(t, "50000.5", resp.GetPrice().GetPrice())
+	assert.NotZero(t, resp.GetPrice().GetId())
+	assert.Nil(t, resp.GetPrice().MarketCap)
+	assert.Equal(t, "50000", resp.GetPrice().GetPrevPrice())
+	assert.Equal(t, "0.5", resp.GetPrice().GetChange())
+	assert.Equal(t, "0.001", resp.GetPrice().GetChangePct())
+	assert.Equal(t, "50000", resp.GetPrice().GetDayOpen())
+	assert.Equal(t, "0.5", resp.GetPrice().GetDayChange())
+
+	resp, err = client.GetLatestPrice(t.Context(), &pricepb.GetLatestPriceRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "BTC", resp.GetPrice().GetSymbol())
+
+	_, err = client.GetLatestPrice(t.Context(), &pricepb.GetLatestPriceRequest{Symbol: "ETH"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetLatestPrice(t.Context(), &pricepb.GetLatestPriceRequest{Symbol: "DOGE"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_StreamPrices(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+
+	publishPrices(t, a, "BTC", "50000")
+	publishPrices(t, a, "ETH", "3000")
+
+	client := dialTestGRPC(t.Context(), t, a)
+
+	stream, err := client.StreamPrices(t.Context(), &pricepb.StreamPricesRequest{Symbols: []string{"BTC"}})
+	require.NoError(t, err)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "closed", event.GetStatus().GetUpstream())
+	assert.True(t, event.GetStatus().GetAvailable())
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "50000", event.GetPrice().GetPrice())
+
+	publishPrices(t, a, "ETH", "3001")
+	publishPrices(t, a, "BTC", "50001")
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "BTC", event.GetPrice().GetSymbol())
+	assert.Equal(t, "50001", event.GetPrice().GetPrice())
+}
+
+func TestGRPC_StreamPrices_Candles(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+	a.cfg.CandleIntervals = []time.Duration{time.Minute}
+	a.candles = newCandles(a.cfg.CandleIntervals, 10)
+
+	client := dialTestGRPC(t.Context(), t, a)
+
+	stream, err := client.StreamPrices(t.Context(), &pricepb.StreamPricesRequest{
+		Symbols: []string{"BTC"},
+		Candles: []string{"1m"},
+	})
+	require.NoError(t, err)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.NotNil(t, event.GetStatus())
+
+	publishPrices(t, a, "ETH", "3000")
+	publishPrices(t, a, "BTC", "50000")
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "50000", event.GetPrice().GetPrice())
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, event.GetCandles().GetCandles(), 1)
+	assert.Equal(t, "BTC", event.GetCandles().GetSymbol())
+	assert.Equal(t, "1m", event.GetCandles().GetCandles()[0].GetInterval())
+	assert.False(t, event.GetCandles().GetCandles()[0].GetClosed())
+
+	stream, err = client.StreamPrices(t.Context(), &pricepb.StreamPricesRequest{Candles: []string{"5m"}})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_StreamPrices_Invalid(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	client := dialTestGRPC(t.Context(), t, a)
+
+	stream, err := client.StreamPrices(t.Context(), &pricepb.StreamPricesRequest{Symbols: []string{"DOGE"}})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_StreamPrices_LastEventID(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	publishPrices(t, a, "BTC", "50000")
+
+	lastEventID := a.lastID
+
+	publishPrices(t, a, "BTC", "50001", "50002")
+
+	client := dialTestGRPC(t.Context(), t, a)
+
+	stream, err := client.StreamPrices(t.Context(), &pricepb.StreamPricesRequest{LastEventId: &lastEventID})
+	require.NoError(t, err)
+
+	// missed prices are replayed before the status, and the last price is not sent again
+	for _, want := range []string{"50001", "50002"} {
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, want, event.GetPrice().GetPrice())
+	}
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.NotNil(t, event.GetStatus())
+
+	publishPrices(t, a, "BTC", "50003")
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "50003", event.GetPrice().GetPrice())
+}
+
+func TestGRPC_StreamPrices_Shutdown(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+	a.cfg.ShutdownRetry = 5 * time.Second
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	client := dialTestGRPC(ctx, t, a)
+
+	stream, err := client.StreamPrices(t.Context(), &pricepb.StreamPricesRequest{})
+	require.NoError(t, err)
+
+	_, err = stream.Recv() // status
+	require.NoError(t, err)
+
+	cancel()
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000), event.GetShutdown().GetRetryMs())
+}
+
+// dialTestGRPC serves the price service over an in-memory connection. ctx is the context of the service.
+func dialTestGRPC(ctx context.Context, t *testing.T, a *app) pricepb.PriceServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := grpc.NewServer()
+	pricepb.RegisterPriceServiceServer(server, &grpcServer{app: a, ctx: ctx})
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pricepb.NewPriceServiceClient(conn)
+}
@@ -1,17 +1,187 @@
 package priceapp
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pubsub"
+	"github.com/gandarez/btc-price-service/internal/business/domain/alertbus"
 	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
 )
 
-// Price represents the price data structure.
-type Price struct {
-	Symbol    string  `json:"symbol"`
-	UpdatedAt string  `json:"timestamp"`
-	Price     float64 `json:"price"`
-}
+type (
+	// Price represents the price data structure. Prices are encoded as JSON strings to keep them exact.
+	// Market fields are omitted when the upstream source does not report them.
+	Price struct {
+		ID                uint64           `json:"-"` // sent as the SSE event ID
+		prevID            uint64           // ID of the previous price of the same symbol
+		Symbol            string           `json:"symbol"`
+		UpdatedAt         string           `json:"timestamp"`
+		Price             decimal.Decimal  `json:"price"`
+		MarketCap         *decimal.Decimal `json:"market_cap,omitempty"`
+		Volume24h         *decimal.Decimal `json:"volume_24h,omitempty"`
+		Change24h         *decimal.Decimal `json:"change_24h,omitempty"`
+		ChangePct24h      *decimal.Decimal `json:"change_pct_24h,omitempty"`
+		CirculatingSupply *decimal.Decimal `json:"circulating_supply,omitempty"`
+		PrevPrice         *decimal.Decimal `json:"prev_price,omitempty"`     // previous price of the symbol
+		Change            *decimal.Decimal `json:"change,omitempty"`         // since the previous price
+		ChangePct         *decimal.Decimal `json:"change_pct,omitempty"`     // since the previous price, in percent
+		DayOpen           *decimal.Decimal `json:"day_open,omitempty"`       // first price of the UTC day
+		DayChange         *decimal.Decimal `json:"day_change,omitempty"`     // since the day open
+		DayChangePct      *decimal.Decimal `json:"day_change_pct,omitempty"` // since the day open, in percent
+		Sources           []SourceQuote    `json:"sources,omitempty"`
+		Aggregation       string           `json:"aggregation,omitempty"` // method the sources were aggregated with
+	}
+
+	// numericPrice is the legacy form of Price, with prices encoded as JSON numbers.
+	numericPrice struct {
+		id                uint64
+		Symbol            string               `json:"symbol"`
+		UpdatedAt         string               `json:"timestamp"`
+		Price             json.Number          `json:"price"`
+		MarketCap         json.Number          `json:"market_cap,omitempty"`
+		Volume24h         json.Number          `json:"volume_24h,omitempty"`
+		Change24h         json.Number          `json:"change_24h,omitempty"`
+		ChangePct24h      json.Number          `json:"change_pct_24h,omitempty"`
+		CirculatingSupply json.Number          `json:"circulating_supply,omitempty"`
+		PrevPrice         json.Number          `json:"prev_price,omitempty"`
+		Change            json.Number          `json:"change,omitempty"`
+		ChangePct         json.Number          `json:"change_pct,omitempty"`
+		DayOpen           json.Number          `json:"day_open,omitempty"`
+		DayChange         json.Number          `json:"day_change,omitempty"`
+		DayChangePct      json.Number          `json:"day_change_pct,omitempty"`
+		Sources           []numericSourceQuote `json:"sources,omitempty"`
+		Aggregation       string               `json:"aggregation,omitempty"`
+	}
+
+	// Snapshot represents the last cached price of a symbol, as served by the REST endpoint.
+	Snapshot struct {
+		Price   Price
+		Age     time.Duration // time elapsed since the price was updated
+		MaxAge  time.Duration // how long the snapshot can be cached
+		numeric bool          // encode prices as JSON numbers instead of strings
+	}
+
+	// PricePage represents a page of cached prices, ordered by ID. NextCursor requests the next page,
+	// and is empty on the last one.
+	PricePage struct {
+		Prices     []Price
+		NextCursor string
+		numeric    bool // encode prices as JSON numbers instead of strings
+	}
+
+	// Candle represents an OHLC bar of the prices of a symbol within an interval.
+	Candle struct {
+		Symbol    string          `json:"symbol"`
+		Interval  string          `json:"interval"`
+		OpenTime  string          `json:"open_time"`
+		CloseTime string          `json:"close_time"` // exclusive
+		Open      decimal.Decimal `json:"open"`
+		High      decimal.Decimal `json:"high"`
+		Low       decimal.Decimal `json:"low"`
+		Close     decimal.Decimal `json:"close"`
+		Ticks     int             `json:"ticks"`  // number of prices received in the bar, changed or not
+		Closed    bool            `json:"closed"` // whether the bar is completed
+	}
+
+	// Candles represents a bar of a symbol updated by a price, or completed at the end of its interval.
+	Candles struct {
+		Symbol    string   `json:"symbol"`
+		Candles   []Candle `json:"candles"`
+		UpdatedAt string   `json:"timestamp"`
+	}
+
+	// CandleHistory represents the last completed bars of a symbol for an interval, oldest first.
+	CandleHistory struct {
+		Symbol   string   `json:"symbol"`
+		Interval string   `json:"interval"`
+		Candles  []Candle `json:"candles"`
+	}
+
+	// Status represents the availability of the upstream price source.
+	Status struct {
+		Upstream  string `json:"upstream"` // circuit breaker state: closed, open or half-open
+		Available bool   `json:"available"`
+		UpdatedAt string `json:"timestamp"`
+	}
+
+	// Gap notifies a resuming client that some of the prices it missed are no longer cached, or a slow client
+	// that Missed updates sent after LastEventID were dropped. The next prices of the listed symbols follow
+	// without the missing ones, unless the slow client was Disconnected.
+	Gap struct {
+		LastEventID  uint64   `json:"last_event_id"`
+		Symbols      []string `json:"symbols"`
+		Missed       uint64   `json:"missed,omitempty"`
+		Disconnected bool     `json:"disconnected,omitempty"`
+		UpdatedAt    string   `json:"timestamp"`
+	}
+
+	// Alert notifies the subscribers of an alert rule that it fired.
+	Alert struct {
+		RuleID    string          `json:"rule_id"`
+		Symbol    string          `json:"symbol"`
+		Kind      string          `json:"kind"` // crosses, move or stale
+		Price     decimal.Decimal `json:"price"`
+		Message   string          `json:"message"`
+		UpdatedAt string          `json:"timestamp"`
+	}
+
+	// Shutdown notifies the client that the service is shutting down, and when to reconnect.
+	Shutdown struct {
+		Retry     int64  `json:"retry_ms"`
+		UpdatedAt string `json:"timestamp"`
+	}
+
+	// wsControl represents a control message sent by a WebSocket client.
+	wsControl struct {
+		Action  string   `json:"action"` // subscribe, unsubscribe, ping or throttle
+		Symbols []string `json:"symbols,omitempty"`
+		// throttle settings, each left unchanged when omitted
+		IntervalMS   *int64       `json:"interval_ms,omitempty"`    // minimum time between prices of a symbol, 0 disables
+		MinChangePct *json.Number `json:"min_change_pct,omitempty"` // minimum move in percent, 0 disables
+	}
+
+	// wsMessage represents a message sent to a WebSocket client. Its type is either the name of the
+	// event in data, the same as streamed over SSE, or the reply to a control message.
+	wsMessage struct {
+		Type  string `json:"type"`
+		ID    uint64 `json:"id,omitempty"` // ID of the price, as the SSE event ID
+		Error string `json:"error,omitempty"`
+		Data  any    `json:"data,omitempty"`
+	}
+
+	// wsSubscription is the reply to subscribe and unsubscribe control messages.
+	wsSubscription struct {
+		Symbols []string `json:"symbols"`
+	}
+
+	// wsThrottle is the reply to throttle control messages.
+	wsThrottle struct {
+		IntervalMS   int64           `json:"interval_ms"`
+		MinChangePct decimal.Decimal `json:"min_change_pct"`
+	}
+
+	// SourceQuote represents the quote of a single source that contributed to an aggregated price.
+	SourceQuote struct {
+		Source    string          `json:"source"`
+		UpdatedAt string          `json:"timestamp"`
+		Price     decimal.Decimal `json:"price"`
+	}
+
+	// numericSourceQuote is the legacy form of SourceQuote, with the price encoded as a JSON number.
+	numericSourceQuote struct {
+		Source    string      `json:"source"`
+		UpdatedAt string      `json:"timestamp"`
+		Price     json.Number `json:"price"`
+	}
+)
 
 // Timestamp returns the time when the price was last updated.
 // It parses the UpdatedAt field which is expected to be in RFC3339 format.
@@ -22,10 +192,346 @@ func (p Price) Timestamp() time.Time {
 	return t
 }
 
+// Encode implements web.Encoder interface.
+// The price is sent with its ID, the same as the SSE event ID, and its age in milliseconds.
+func (s Snapshot) Encode() ([]byte, string, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if s.numeric {
+		data, err = json.Marshal(struct {
+			numericPrice
+			ID    uint64 `json:"id"`
+			AgeMS int64  `json:"age_ms"`
+		}{s.Price.numeric(), s.Price.ID, s.Age.Milliseconds()})
+	} else {
+		data, err = json.Marshal(struct {
+			Price
+			ID    uint64 `json:"id"`
+			AgeMS int64  `json:"age_ms"`
+		}{s.Price, s.Price.ID, s.Age.Milliseconds()})
+	}
+
+	return data, "application/json", err
+}
+
+// Headers implements web.Headerer interface. The ETag and Last-Modified headers are derived from the
+// update, so polling clients get 304 Not Modified until the price changes.
+func (s Snapshot) Headers() http.Header {
+	updatedAt := s.Price.Timestamp()
+
+	h := make(http.Header)
+	// weak, since the age in the body changes while the price does not
+	h.Set("ETag", fmt.Sprintf(`W/"%s-%d-%d"`, s.Price.Symbol, updatedAt.Unix(), s.Price.ID))
+	h.Set("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+	h.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(max(s.MaxAge, 0).Seconds())))
+
+	return h
+}
+
+// Encode implements web.Encoder interface. Each price is sent with its ID, the same as the SSE event ID.
+func (p PricePage) Encode() ([]byte, string, error) {
+	type (
+		price struct {
+			Price
+			ID uint64 `json:"id"`
+		}
+
+		numeric struct {
+			numericPrice
+			ID uint64 `json:"id"`
+		}
+	)
+
+	prices := make([]any, 0, len(p.Prices))
+
+	for _, pr := range p.Prices {
+		if p.numeric {
+			prices = append(prices, numeric{pr.numeric(), pr.ID})
+			continue
+		}
+
+		prices = append(prices, price{pr, pr.ID})
+	}
+
+	data, err := json.Marshal(struct {
+		Prices     []any  `json:"prices"`
+		NextCursor string `json:"next_cursor,omitempty"`
+	}{prices, p.NextCursor})
+
+	return data, "application/json", err
+}
+
+// numeric returns the legacy form of the price, with prices encoded as JSON numbers.
+// The numbers keep every digit of the decimal, but clients decoding them as floats may lose precision.
+func (p Price) numeric() numericPrice {
+	var sources []numericSourceQuote
+
+	for _, q := range p.Sources {
+		sources = append(sources, numericSourceQuote{
+			Source:    q.Source,
+			UpdatedAt: q.UpdatedAt,
+			Price:     json.Number(q.Price.String()),
+		})
+	}
+
+	return numericPrice{
+		id:                p.ID,
+		Symbol:            p.Symbol,
+		UpdatedAt:         p.UpdatedAt,
+		Price:             json.Number(p.Price.String()),
+		MarketCap:         numberOrEmpty(p.MarketCap),
+		Volume24h:         numberOrEmpty(p.Volume24h),
+		Change24h:         numberOrEmpty(p.Change24h),
+		ChangePct24h:      numberOrEmpty(p.ChangePct24h),
+		CirculatingSupply: numberOrEmpty(p.CirculatingSupply),
+		PrevPrice:         numberOrEmpty(p.PrevPrice),
+		Change:            numberOrEmpty(p.Change),
+		ChangePct:         numberOrEmpty(p.ChangePct),
+		DayOpen:           numberOrEmpty(p.DayOpen),
+		DayChange:         numberOrEmpty(p.DayChange),
+		DayChangePct:      numberOrEmpty(p.DayChangePct),
+		Sources:           sources,
+		Aggregation:       p.Aggregation,
+	}
+}
+
+// Compact implements web.Compacter interface. The price is encoded as [id, symbol, timestamp, price].
+func (p Price) Compact() any {
+	return []any{p.ID, p.Symbol, p.UpdatedAt, p.Price}
+}
+
+// Compact implements web.Compacter interface. The price is encoded as [id, symbol, timestamp, price].
+func (p numericPrice) Compact() any {
+	return []any{p.id, p.Symbol, p.UpdatedAt, p.Price}
+}
+
+// Compact implements web.Compacter interface. The price is encoded as [id, symbol, timestamp, price, age_ms].
+func (s Snapshot) Compact() any {
+	price := s.Price.Compact()
+	if s.numeric {
+		price = s.Price.numeric().Compact()
+	}
+
+	return append(price.([]any), s.Age.Milliseconds())
+}
+
+// Compact implements web.Compacter interface. The message is encoded as [type, id, data], with data compacted.
+func (m wsMessage) Compact() any {
+	data := m.Data
+	if c, ok := data.(web.Compacter); ok {
+		data = c.Compact()
+	}
+
+	return []any{m.Type, m.ID, data}
+}
+
+// Proto implements web.Protoer interface. The message is encoded as its data, the type being implied by the event.
+func (m wsMessage) Proto() proto.Message {
+	if p, ok := m.Data.(web.Protoer); ok {
+		return p.Proto()
+	}
+
+	return nil
+}
+
+// numberOrEmpty returns the value as a JSON number, or an empty one to be omitted when nil.
+func numberOrEmpty(value *decimal.Decimal) json.Number {
+	if value == nil {
+		return ""
+	}
+
+	return json.Number(value.String())
+}
+
+// optional returns a pointer to the value, or nil when it is unknown.
+func optional(value decimal.NullDecimal) *decimal.Decimal {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Decimal
+}
+
+// Timestamp returns the time of the price that updated the candles.
+func (c Candles) Timestamp() time.Time {
+	t, _ := time.Parse(time.RFC3339, c.UpdatedAt)
+	return t
+}
+
+// Compact implements web.Compacter interface. The candles are encoded as [symbol, timestamp, bars], where
+// each bar is [interval, open_time, close_time, open, high, low, close, ticks, closed].
+func (c Candles) Compact() any {
+	bars := make([]any, 0, len(c.Candles))
+	for _, candle := range c.Candles {
+		bars = append(bars, []any{
+			candle.Interval, candle.OpenTime, candle.CloseTime,
+			candle.Open, candle.High, candle.Low, candle.Close, candle.Ticks, candle.Closed,
+		})
+	}
+
+	return []any{c.Symbol, c.UpdatedAt, bars}
+}
+
+// Encode implements web.Encoder interface.
+func (h CandleHistory) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(h)
+	return data, "application/json", err
+}
+
+// Timestamp returns the time when the status changed.
+func (s Status) Timestamp() time.Time {
+	t, _ := time.Parse(time.RFC3339, s.UpdatedAt)
+	return t
+}
+
+// Compact implements web.Compacter interface. The status is encoded as [upstream, available, timestamp].
+func (s Status) Compact() any {
+	return []any{s.Upstream, s.Available, s.UpdatedAt}
+}
+
+// Timestamp returns the time when the gap was detected.
+func (g Gap) Timestamp() time.Time {
+	t, _ := time.Parse(time.RFC3339, g.UpdatedAt)
+	return t
+}
+
+// Compact implements web.Compacter interface.
+// The gap is encoded as [last_event_id, symbols, timestamp, missed, disconnected].
+func (g Gap) Compact() any {
+	return []any{g.LastEventID, g.Symbols, g.UpdatedAt, g.Missed, g.Disconnected}
+}
+
+// Timestamp returns the time when the alert fired.
+func (a Alert) Timestamp() time.Time {
+	t, _ := time.Parse(time.RFC3339, a.UpdatedAt)
+	return t
+}
+
+// Compact implements web.Compacter interface.
+// The alert is encoded as [rule_id, symbol, kind, price, message, timestamp].
+func (a Alert) Compact() any {
+	return []any{a.RuleID, a.Symbol, a.Kind, a.Price, a.Message, a.UpdatedAt}
+}
+
+// Timestamp returns the time when the shutdown started.
+func (s Shutdown) Timestamp() time.Time {
+	t, _ := time.Parse(time.RFC3339, s.UpdatedAt)
+	return t
+}
+
+// Compact implements web.Compacter interface. The shutdown is encoded as [retry_ms, timestamp].
+func (s Shutdown) Compact() any {
+	return []any{s.Retry, s.UpdatedAt}
+}
+
+func toAppCandle(symbol string, interval time.Duration, bar candleBar, closed bool) Candle {
+	return Candle{
+		Symbol:    symbol,
+		Interval:  formatInterval(interval),
+		OpenTime:  bar.start.UTC().Format(time.RFC3339),
+		CloseTime: bar.start.Add(interval).UTC().Format(time.RFC3339),
+		Open:      bar.open,
+		High:      bar.high,
+		Low:       bar.low,
+		Close:     bar.close,
+		Ticks:     bar.ticks,
+		Closed:    closed,
+	}
+}
+
+func toAppCandles(bar Candle, updatedAt string) Candles {
+	return Candles{
+		Symbol:    bar.Symbol,
+		Candles:   []Candle{bar},
+		UpdatedAt: updatedAt,
+	}
+}
+
+func toAppPricePage(prices []Price, next string, numeric bool) PricePage {
+	return PricePage{
+		Prices:     prices,
+		NextCursor: next,
+		numeric:    numeric,
+	}
+}
+
+func toAppSnapshot(price Price, now, expiresAt time.Time, numeric bool) Snapshot {
+	return Snapshot{
+		Price:   price,
+		Age:     max(now.Sub(price.Timestamp()), 0),
+		MaxAge:  max(expiresAt.Sub(now), 0),
+		numeric: numeric,
+	}
+}
+
+func toAppShutdown(retry time.Duration) Shutdown {
+	return Shutdown{
+		Retry:     retry.Milliseconds(),
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func toAppGap(lastEventID uint64, symbols []string) Gap {
+	return Gap{
+		LastEventID: lastEventID,
+		Symbols:     symbols,
+		UpdatedAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func toAppDropGap(gap pubsub.Gap, lastEventID uint64, symbols []string) Gap {
+	return Gap{
+		LastEventID:  lastEventID,
+		Symbols:      symbols,
+		Missed:       gap.Missed,
+		Disconnected: gap.Disconnected,
+		UpdatedAt:    gap.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toAppAlert(alert alertbus.Alert) Alert {
+	return Alert{
+		RuleID:    alert.RuleID,
+		Symbol:    alert.Symbol,
+		Kind:      alert.Kind,
+		Price:     alert.Price,
+		Message:   alert.Message,
+		UpdatedAt: alert.Timestamp.UTC().Format(time.RFC3339),
+	}
+}
+
+func toAppStatus(state breaker.State) Status {
+	return Status{
+		Upstream:  state.String(),
+		Available: state != breaker.StateOpen,
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
 func toAppPrice(busPrice pricebus.Price) Price {
+	var sources []SourceQuote
+
+	for _, q := range busPrice.Sources {
+		sources = append(sources, SourceQuote{
+			Source:    q.Source,
+			UpdatedAt: q.Timestamp,
+			Price:     q.Price,
+		})
+	}
+
 	return Price{
-		Symbol:    busPrice.Symbol,
-		UpdatedAt: busPrice.Timestamp,
-		Price:     busPrice.Price,
+		Symbol:            busPrice.Symbol,
+		UpdatedAt:         busPrice.Timestamp,
+		Price:             busPrice.Price,
+		MarketCap:         optional(busPrice.Market.MarketCap),
+		Volume24h:         optional(busPrice.Market.Volume24h),
+		Change24h:         optional(busPrice.Market.Change24h),
+		ChangePct24h:      optional(busPrice.Market.ChangePct24h),
+		CirculatingSupply: optional(busPrice.Market.CirculatingSupply),
+		Sources:           sources,
+		Aggregation:       busPrice.Aggregation,
 	}
 }
@@ -1,18 +1,31 @@
 package priceapp
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pricepb"
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pubsub"
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/foundation/cache"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
 )
 
 func TestPrice_Encode(t *testing.T) {
 	price := pricebus.Price{
 		Symbol:    "BTC",
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
-		Price:     50000.0,
+		Price:     decimal.RequireFromString("50000"),
 	}
 
 	p := toAppPrice(price)
@@ -21,3 +34,299 @@ func TestPrice_Encode(t *testing.T) {
 	assert.Equal(t, p.UpdatedAt, price.Timestamp)
 	assert.Equal(t, p.Price, price.Price)
 }
+
+func TestPrice_Encode_Sources(t *testing.T) {
+	price := pricebus.Price{
+		Symbol:    "BTC",
+		Timestamp: "2021-10-01T07:20:00Z",
+		Price:     decimal.RequireFromString("50000.5"),
+		Sources: []pricebus.Quote{
+			{Source: "coindesk", Timestamp: "2021-10-01T07:20:00Z", Price: decimal.RequireFromString("50000")},
+			{
+				Source:    "kraken",
+				Timestamp: "2021-10-01T07:19:58Z",
+				Price:     decimal.RequireFromString("50001"),
+				Volume:    decimal.RequireFromString("10"),
+			},
+		},
+	}
+
+	p := toAppPrice(price)
+
+	assert.Equal(t, []SourceQuote{
+		{Source: "coindesk", UpdatedAt: "2021-10-01T07:20:00Z", Price: decimal.RequireFromString("50000")},
+		{Source: "kraken", UpdatedAt: "2021-10-01T07:19:58Z", Price: decimal.RequireFromString("50001")},
+	}, p.Sources)
+}
+
+func TestPrice_JSON(t *testing.T) {
+	p := Price{
+		Symbol:    "BTC",
+		UpdatedAt: "2021-10-01T07:20:00Z",
+		Price:     decimal.RequireFromString("113907.168087996123"),
+		Change:    optional(decimal.NewNullDecimal(decimal.RequireFromString("-1.5"))),
+		Sources: []SourceQuote{
+			{Source: "kraken", UpdatedAt: "2021-10-01T07:19:58Z", Price: decimal.RequireFromString("0.30")},
+		},
+	}
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"symbol": "BTC",
+		"timestamp": "2021-10-01T07:20:00Z",
+		"price": "113907.168087996123",
+		"change": "-1.5",
+		"sources": [{"source": "kraken", "timestamp": "2021-10-01T07:19:58Z", "price": "0.3"}]
+	}`, string(data))
+
+	data, err = json.Marshal(p.numeric())
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), `"price":113907.168087996123`)
+	assert.Contains(t, string(data), `"price":0.3`)
+	assert.Contains(t, string(data), `"change":-1.5`)
+	assert.NotContains(t, string(data), `"prev_price"`)
+}
+
+func TestSendSSE_Numeric(t *testing.T) {
+	p := Price{
+		Symbol:    "BTC",
+		UpdatedAt: "2021-10-01T07:20:00Z",
+		Price:     decimal.RequireFromString("50000.1"),
+	}
+
+	w := httptest.NewRecorder()
+	require.NoError(t, sendSSE(w, p, jsonFormat(t), false))
+	assert.Equal(t, "event: price\n"+`data: {"symbol":"BTC","timestamp":"2021-10-01T07:20:00Z","price":"50000.1"}`+"\n\n",
+		w.Body.String())
+
+	w = httptest.NewRecorder()
+	require.NoError(t, sendSSE(w, p, jsonFormat(t), true))
+	assert.Equal(t, "event: price\n"+`data: {"symbol":"BTC","timestamp":"2021-10-01T07:20:00Z","price":50000.1}`+"\n\n",
+		w.Body.String())
+}
+
+func TestPrice_Encode_Market(t *testing.T) {
+	price := pricebus.Price{
+		Symbol:    "BTC",
+		Timestamp: "2021-10-01T07:20:00Z",
+		Price:     decimal.RequireFromString("50000"),
+		Market: pricebus.Market{
+			MarketCap:    decimal.NewNullDecimal(decimal.RequireFromString("990000000000")),
+			ChangePct24h: decimal.NewNullDecimal(decimal.RequireFromString("-2.5")),
+		},
+	}
+
+	p := toAppPrice(price)
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"symbol": "BTC",
+		"timestamp": "2021-10-01T07:20:00Z",
+		"price": "50000",
+		"market_cap": "990000000000",
+		"change_pct_24h": "-2.5"
+	}`, string(data))
+
+	data, err = json.Marshal(p.numeric())
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"symbol": "BTC",
+		"timestamp": "2021-10-01T07:20:00Z",
+		"price": 50000,
+		"market_cap": 990000000000,
+		"change_pct_24h": -2.5
+	}`, string(data))
+}
+
+func TestSendSSE_Events(t *testing.T) {
+	tests := map[string]struct {
+		update   cache.CacheableEntity
+		expected string
+	}{
+		"price": {
+			update: Price{
+				ID:        42,
+				Symbol:    "BTC",
+				UpdatedAt: "2021-10-01T07:20:00Z",
+				Price:     decimal.RequireFromString("50000.1"),
+			},
+			expected: "id: 42\nevent: price\n" +
+				`data: {"symbol":"BTC","timestamp":"2021-10-01T07:20:00Z","price":"50000.1"}` + "\n\n",
+		},
+		"status": {
+			update:   Status{Upstream: "open", UpdatedAt: "2021-10-01T07:20:00Z"},
+			expected: "event: status\n" + `data: {"upstream":"open","available":false,"timestamp":"2021-10-01T07:20:00Z"}` + "\n\n",
+		},
+		"gap": {
+			update:   Gap{LastEventID: 40, Symbols: []string{"BTC"}, UpdatedAt: "2021-10-01T07:20:00Z"},
+			expected: "event: gap\n" + `data: {"last_event_id":40,"symbols":["BTC"],"timestamp":"2021-10-01T07:20:00Z"}` + "\n\n",
+		},
+		"dropped": {
+			update: toAppDropGap(pubsub.Gap{Missed: 3, UpdatedAt: time.Date(2021, 10, 1, 7, 20, 0, 0, time.UTC)},
+				42, []string{"BTC"}),
+			expected: "event: gap\n" +
+				`data: {"last_event_id":42,"symbols":["BTC"],"missed":3,"timestamp":"2021-10-01T07:20:00Z"}` + "\n\n",
+		},
+		"disconnected": {
+			update: toAppDropGap(pubsub.Gap{Missed: 5, Disconnected: true, UpdatedAt: time.Date(2021, 10, 1, 7, 20, 0, 0, time.UTC)},
+				42, []string{"BTC"}),
+			expected: "event: gap\n" + `data: {"last_event_id":42,"symbols":["BTC"],"missed":5,"disconnected":true,` +
+				`"timestamp":"2021-10-01T07:20:00Z"}` + "\n\n",
+		},
+		"shutdown": {
+			update:   Shutdown{Retry: 4500, UpdatedAt: "2021-10-01T07:20:00Z"},
+			expected: "event: shutdown\nretry: 4500\n" + `data: {"retry_ms":4500,"timestamp":"2021-10-01T07:20:00Z"}` + "\n\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			require.NoError(t, sendSSE(w, test.update, jsonFormat(t), false))
+			assert.Equal(t, test.expected, w.Body.String())
+		})
+	}
+}
+
+func TestSendSSE_Formats(t *testing.T) {
+	p := Price{
+		ID:        42,
+		Symbol:    "BTC",
+		UpdatedAt: "2021-10-01T07:20:00Z",
+		Price:     decimal.RequireFromString("50000.1"),
+	}
+
+	w := httptest.NewRecorder()
+	require.NoError(t, sendSSE(w, p, testFormat(t, web.FormatCompact), false))
+	assert.Equal(t, "id: 42\nevent: price\n"+`data: [42,"BTC","2021-10-01T07:20:00Z","50000.1"]`+"\n\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	require.NoError(t, sendSSE(w, p, testFormat(t, web.FormatCompact), true))
+	assert.Equal(t, "id: 42\nevent: price\n"+`data: [42,"BTC","2021-10-01T07:20:00Z",50000.1]`+"\n\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	require.NoError(t, sendSSE(w, p, testFormat(t, web.FormatMsgPack), false))
+
+	var decoded map[string]any
+	require.NoError(t, msgpack.Unmarshal(sseData(t, w.Body.String()), &decoded))
+	assert.Equal(t, map[string]any{"symbol": "BTC", "timestamp": "2021-10-01T07:20:00Z", "price": "50000.1"}, decoded)
+
+	// numeric does not apply to msgpack either, which keeps decimals exact
+	w = httptest.NewRecorder()
+	require.NoError(t, sendSSE(w, p, testFormat(t, web.FormatMsgPack), true))
+
+	decoded = nil
+	require.NoError(t, msgpack.Unmarshal(sseData(t, w.Body.String()), &decoded))
+	assert.Equal(t, "50000.1", decoded["price"])
+
+	// numeric does not apply to protobuf, which encodes prices as strings
+	w = httptest.NewRecorder()
+	require.NoError(t, sendSSE(w, p, testFormat(t, web.FormatProtobuf), true))
+
+	var event pricepb.StreamPricesResponse
+	require.NoError(t, proto.Unmarshal(sseData(t, w.Body.String()), &event))
+	assert.Equal(t, uint64(42), event.GetPrice().GetId())
+	assert.Equal(t, "50000.1", event.GetPrice().GetPrice())
+
+	candles := Candles{
+		Symbol: "BTC",
+		Candles: []Candle{{
+			Symbol:    "BTC",
+			Interval:  "1m",
+			OpenTime:  "2021-10-01T07:20:00Z",
+			CloseTime: "2021-10-01T07:21:00Z",
+			Open:      decimal.RequireFromString("50000.1"),
+			High:      decimal.RequireFromString("50010"),
+			Low:       decimal.RequireFromString("49990"),
+			Close:     decimal.RequireFromString("50005"),
+			Ticks:     3,
+			Closed:    true,
+		}},
+		UpdatedAt: "2021-10-01T07:20:30Z",
+	}
+
+	w = httptest.NewRecorder()
+	require.NoError(t, sendSSE(w, candles, testFormat(t, web.FormatProtobuf), false))
+
+	event.Reset()
+	require.NoError(t, proto.Unmarshal(sseData(t, w.Body.String()), &event))
+	require.Len(t, event.GetCandles().GetCandles(), 1)
+	assert.Equal(t, "BTC", event.GetCandles().GetSymbol())
+
+	bar := event.GetCandles().GetCandles()[0]
+	assert.Equal(t, "1m", bar.GetInterval())
+	assert.Equal(t, "50000.1", bar.GetOpen())
+	assert.Equal(t, "49990", bar.GetLow())
+	assert.Equal(t, int64(3), bar.GetTicks())
+	assert.True(t, bar.GetClosed())
+	assert.Equal(t, int64(1633072860), bar.GetCloseTime().GetSeconds())
+
+	gap := toAppDropGap(pubsub.Gap{Missed: 7, Disconnected: true, UpdatedAt: time.Now()}, 42, []string{"BTC"})
+
+	w = httptest.NewRecorder()
+	require.NoError(t, sendSSE(w, gap, testFormat(t, web.FormatProtobuf), false))
+
+	event.Reset()
+	require.NoError(t, proto.Unmarshal(sseData(t, w.Body.String()), &event))
+	assert.Equal(t, uint64(42), event.GetGap().GetLastEventId())
+	assert.Equal(t, uint64(7), event.GetGap().GetMissed())
+	assert.True(t, event.GetGap().GetDisconnected())
+
+	alert := Alert{
+		RuleID:    "abc",
+		Symbol:    "BTC",
+		Kind:      "crosses",
+		Price:     decimal.RequireFromString("100000.5"),
+		Message:   "BTC crossed above 100000",
+		UpdatedAt: "2021-10-01T07:20:00Z",
+	}
+
+	w = httptest.NewRecorder()
+	require.NoError(t, sendSSE(w, alert, testFormat(t, web.FormatProtobuf), false))
+
+	event.Reset()
+	require.NoError(t, proto.Unmarshal(sseData(t, w.Body.String()), &event))
+	assert.Equal(t, "abc", event.GetAlert().GetRuleId())
+	assert.Equal(t, "crosses", event.GetAlert().GetKind())
+	assert.Equal(t, "100000.5", event.GetAlert().GetPrice())
+	assert.Equal(t, "BTC crossed above 100000", event.GetAlert().GetMessage())
+}
+
+func jsonFormat(t *testing.T) web.Format {
+	t.Helper()
+
+	return testFormat(t, web.FormatJSON)
+}
+
+func testFormat(t *testing.T, name string) web.Format {
+	t.Helper()
+
+	format, ok := web.LookupFormat(name)
+	require.True(t, ok)
+
+	return format
+}
+
+// sseData returns the base64 decoded data of a single event.
+func sseData(t *testing.T, event string) []byte {
+	t.Helper()
+
+	for line := range strings.SplitSeq(event, "\n") {
+		if encoded, ok := strings.CutPrefix(line, "data: "); ok {
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			require.NoError(t, err)
+
+			return data
+		}
+	}
+
+	require.Fail(t, "event without data")
+
+	return nil
+}
@@ -1,25 +1,57 @@
 package priceapp
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/shopspring/decimal"
+
 	"github.com/gandarez/btc-price-service/internal/app/sdk/pubsub"
+	"github.com/gandarez/btc-price-service/internal/business/domain/alertbus"
 	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus"
 	"github.com/gandarez/btc-price-service/internal/business/sdk/page"
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
 	"github.com/gandarez/btc-price-service/internal/foundation/cache"
 	"github.com/gandarez/btc-price-service/internal/foundation/log"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
 )
 
+// maxPricesPerPage is the default and maximum number of prices per page of the range query.
+const maxPricesPerPage = 100
+
 type (
 	app struct {
 		priceBus    PriceBusiness
+		alertBus    AlertBusiness   // nil when alerts are disabled
+		webhookBus  WebhookBusiness // nil when webhooks are disabled
 		broadcaster *pubsub.Manager
-		cache       *cache.Buffer[cache.CacheableEntity]
+		caches      map[string]*cache.Buffer[cache.CacheableEntity] // keyed by symbol
+		upstream    breaker.State                                   // last upstream state broadcast by the poller
+		streaming   atomic.Bool                                     // whether the upstream stream is connected
+		publishMu   sync.Mutex
+		startID     uint64                     // event ID the IDs of this process start after
+		lastID      uint64                     // ID of the last published price
+		latestIDs   map[string]uint64          // ID of the last published price by symbol
+		receivedAt  map[string]time.Time       // when a price was last received by symbol, changed or not
+		lastPrices  map[string]decimal.Decimal // last published price by symbol
+		dayOpens    map[string]dayOpen         // first published price of the UTC day by symbol
+		candles     *candles
 		cfg         Config
 	}
 
@@ -30,63 +62,601 @@ type (
 		DefaultExpirationInterval time.Duration
 		PollInterval              time.Duration
 		MaxPeersPerBroadcaster    int
+		Symbols                   []string
+		Streaming                 bool          // ingest prices pushed by the upstream, polling only while it is disconnected
+		Retry                     time.Duration // reconnect delay suggested to clients, zero leaves it to them
+		ShutdownRetry             time.Duration // reconnect delay suggested to clients on shutdown
+		RetryJitter               float64       // fraction between 0 and 1 by which the suggested delays are spread
+		CandleIntervals           []time.Duration
+		CandleMaxBars             int            // completed bars kept per symbol and interval
+		Overflow                  pubsub.Options // queue of the subscribers, clients may change the policy
 		PriceBus                  *pricebus.Business
+		AlertBus                  *alertbus.Business   // evaluates alert rules on each price when set
+		WebhookBus                *webhookbus.Business // delivers the broadcast updates to webhooks when set
+	}
+
+	// priceStreamParams holds the parsed query parameters and headers of the price stream.
+	priceStreamParams struct {
+		since       time.Time
+		symbols     []string
+		numeric     bool       // encode prices as JSON numbers instead of strings
+		format      web.Format // encoding of the events
+		resume      bool       // whether the client sent the ID of the last event it received
+		lastEventID uint64     // takes precedence over since when resuming
+		throttle    *throttle
+		alerts      []string // IDs of the alert rules whose alerts are sent
+		candles     []string // intervals of the bars sent
+		overflow    pubsub.Options
+	}
+
+	// replay selects the cached prices sent to a client before the live ones.
+	replay struct {
+		resume      bool      // send the prices published after lastEventID
+		lastEventID uint64    // ID of the last event the resumed client received
+		since       time.Time // otherwise send the prices since then, and the last one of the symbols without any
+	}
+
+	// dayOpen is the first price of a symbol published on a UTC day.
+	dayOpen struct {
+		day   time.Time // midnight UTC
+		price decimal.Decimal
+	}
+
+	// backfill holds the cached prices sent to a client before the live ones.
+	backfill struct {
+		from   replay
+		prices []Price  // ordered by ID
+		gaps   []string // symbols whose missed prices are no longer all cached
+		lastID uint64   // ID of the last price published when taken, the live prices follow it
 	}
 
 	// PriceBusiness defines the interface for fetching asset prices.
 	PriceBusiness interface {
-		AssetPrice(ctx context.Context, symbol string, pagination page.Page) (pricebus.Price, error)
+		AssetPrice(ctx context.Context, symbol string) (pricebus.Price, error)
+		UpstreamState() breaker.State
+		PollDelay(interval time.Duration, symbols int) time.Duration
+		StreamPrices(ctx context.Context, symbols []string, onPrice func(pricebus.Price), onState func(bool)) error
+	}
+
+	// AlertBusiness defines the interface for evaluating alert rules.
+	AlertBusiness interface {
+		Evaluate(ctx context.Context, price pricebus.Price) []alertbus.Alert
+		CheckStale(ctx context.Context, now time.Time) []alertbus.Alert
+		Rule(id string) (alertbus.Rule, error)
+	}
+
+	// WebhookBusiness defines the interface for delivering updates to webhooks.
+	WebhookBusiness interface {
+		Publish(ctx context.Context, event webhookbus.Event)
 	}
 )
 
 func newApp(cfg Config) *app {
-	return &app{
+	symbols := make([]string, 0, len(cfg.Symbols))
+	caches := make(map[string]*cache.Buffer[cache.CacheableEntity], len(cfg.Symbols))
+
+	for _, symbol := range cfg.Symbols {
+		symbol = normalizeSymbol(symbol)
+		if _, ok := caches[symbol]; ok || symbol == "" {
+			continue
+		}
+
+		symbols = append(symbols, symbol)
+		caches[symbol] = cache.NewBuffer[cache.CacheableEntity](cfg.BufferTTL, cfg.DefaultExpirationInterval, cfg.MaxCacheSize)
+	}
+
+	cfg.Symbols = symbols
+
+	// seed the IDs from the clock so they keep increasing across restarts
+	startID := uint64(max(time.Now().UnixMilli(), 0))
+
+	a := &app{
 		priceBus:    cfg.PriceBus,
 		broadcaster: pubsub.NewManager(cfg.MaxPeersPerBroadcaster),
-		cache:       cache.NewBuffer[cache.CacheableEntity](cfg.BufferTTL, cfg.DefaultExpirationInterval, cfg.MaxCacheSize),
+		caches:      caches,
+		startID:     startID,
+		lastID:      startID,
+		latestIDs:   make(map[string]uint64, len(symbols)),
+		receivedAt:  make(map[string]time.Time, len(symbols)),
+		lastPrices:  make(map[string]decimal.Decimal, len(symbols)),
+		dayOpens:    make(map[string]dayOpen, len(symbols)),
+		candles:     newCandles(cfg.CandleIntervals, cfg.CandleMaxBars),
 		cfg:         cfg,
 	}
+
+	// a nil pointer would make a non-nil interface
+	if cfg.AlertBus != nil {
+		a.alertBus = cfg.AlertBus
+	}
+
+	if cfg.WebhookBus != nil {
+		a.webhookBus = cfg.WebhookBus
+	}
+
+	return a
 }
 
+// startPolling polls the price of every symbol, spacing the polls to stay within the upstream rate limit.
 func (a *app) startPolling(ctx context.Context) {
-	ticker := time.NewTicker(a.cfg.PollInterval)
-	defer ticker.Stop()
-
 	logger := log.Extract(ctx)
 
+	delay := a.cfg.PollInterval
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			page, err := page.New(1, 100) // Default to page 1 with 100 rows per page
-			if err != nil {
-				logger.Errorf("failed to create pagination: %v", err)
-				continue
+		case <-timer.C:
+			// polling is the fallback while the upstream stream is disconnected
+			if !a.streaming.Load() {
+				for _, symbol := range a.cfg.Symbols {
+					a.poll(ctx, symbol)
+				}
 			}
 
-			price, err := a.priceBus.AssetPrice(ctx, "BTC", page)
-			if err != nil {
-				logger.Errorf("failed to fetch asset price: %v", err)
-				continue
+			a.checkUpstream(ctx)
+			a.checkStale(ctx)
+
+			next := a.priceBus.PollDelay(a.cfg.PollInterval, len(a.cfg.Symbols))
+			if next != delay {
+				logger.Infof("poll interval changed from %s to %s to stay within the upstream rate limit", delay, next)
+			}
+
+			delay = next
+			timer.Reset(delay)
+		}
+	}
+}
+
+// startStreaming ingests the prices pushed by the upstream until ctx is done.
+func (a *app) startStreaming(ctx context.Context) {
+	logger := log.Extract(ctx)
+
+	err := a.priceBus.StreamPrices(ctx, a.cfg.Symbols,
+		func(price pricebus.Price) {
+			a.publish(ctx, price)
+		},
+		func(connected bool) {
+			if connected {
+				logger.Infoln("upstream stream connected, pausing polling")
+			} else {
+				logger.Warnln("upstream stream disconnected, falling back to polling")
+			}
+
+			a.streaming.Store(connected)
+		},
+	)
+	if err != nil && ctx.Err() == nil {
+		logger.Errorf("failed to stream prices, falling back to polling: %v", err)
+	}
+}
+
+// checkUpstream broadcasts a status update to all subscribers when the upstream state changed.
+func (a *app) checkUpstream(ctx context.Context) {
+	state := a.priceBus.UpstreamState()
+	if state == a.upstream {
+		return
+	}
+
+	log.Extract(ctx).Warnf("upstream state changed from %s to %s", a.upstream, state)
+
+	a.upstream = state
+	a.broadcast(ctx, "", toAppStatus(state))
+}
+
+// checkStale broadcasts the alerts of the stale rules that fired.
+func (a *app) checkStale(ctx context.Context) {
+	if a.alertBus == nil {
+		return
+	}
+
+	a.broadcastAlerts(ctx, a.alertBus.CheckStale(ctx, time.Now()))
+}
+
+// broadcastAlerts broadcasts each alert to the subscribers of its rule.
+func (a *app) broadcastAlerts(ctx context.Context, alerts []alertbus.Alert) {
+	for _, alert := range alerts {
+		a.broadcast(ctx, alertTopic(alert.RuleID), toAppAlert(alert))
+	}
+}
+
+// broadcast sends the update to the subscribers of the topic, and queues it for the webhooks subscribed to it.
+func (a *app) broadcast(ctx context.Context, topic string, update cache.CacheableEntity) {
+	a.broadcaster.Broadcast(topic, update)
+	a.publishWebhooks(ctx, update)
+}
+
+// publishWebhooks queues the updates for the webhooks subscribed to them.
+// It must not be called with publishMu held, so slow webhooks never hold back the streams.
+func (a *app) publishWebhooks(ctx context.Context, updates ...cache.CacheableEntity) {
+	if a.webhookBus == nil {
+		return
+	}
+
+	for _, update := range updates {
+		data, err := json.Marshal(update)
+		if err != nil {
+			log.Extract(ctx).Errorf("failed to encode update for webhooks: %s", err)
+			continue
+		}
+
+		a.webhookBus.Publish(ctx, webhookbus.Event{
+			Name:      eventName(update),
+			Symbol:    eventSymbol(update),
+			Data:      data,
+			Timestamp: update.Timestamp(),
+		})
+	}
+}
+
+// alertTopic returns the topic the alerts of a rule are broadcast to.
+func alertTopic(ruleID string) string {
+	return "alert:" + ruleID
+}
+
+// poll fetches the current price of a single symbol and publishes it.
+func (a *app) poll(ctx context.Context, symbol string) {
+	logger := log.Extract(ctx)
+
+	price, err := a.priceBus.AssetPrice(ctx, symbol)
+	if errors.Is(err, breaker.ErrOpen) {
+		logger.Debugf("skipping poll for %s: %v", symbol, err)
+		return
+	}
+
+	// already logged with its reason by the business layer
+	if errors.Is(err, pricebus.ErrRejected) {
+		return
+	}
+
+	if err != nil {
+		logger.Errorf("failed to fetch asset price for %s: %v", symbol, err)
+		return
+	}
+
+	a.publish(ctx, price)
+}
+
+// publish caches the price and broadcasts it to the subscribers of its symbol if it changed.
+func (a *app) publish(ctx context.Context, price pricebus.Price) {
+	logger := log.Extract(ctx)
+
+	buffer, ok := a.caches[price.Symbol]
+	if !ok {
+		logger.Warnf("skipping price of unsupported symbol %s", price.Symbol)
+		return
+	}
+
+	update := toAppPrice(price)
+
+	// rules see every price, even unchanged ones, so stale rules know the symbol is still updated
+	if a.alertBus != nil {
+		// deferred before locking, so the alerts are broadcast once unlocked, after the price that fired them
+		defer a.broadcastAlerts(ctx, a.alertBus.Evaluate(ctx, price))
+	}
+
+	// queued for the webhooks once unlocked, before the alerts
+	var published []cache.CacheableEntity
+	defer func() { a.publishWebhooks(ctx, published...) }()
+
+	a.publishMu.Lock()
+	defer a.publishMu.Unlock()
+
+	a.receivedAt[price.Symbol] = time.Now()
+
+	// if cached item is equal to current, then do not broadcast
+	if last, ok := buffer.Last().(Price); ok && last.Price.Equal(update.Price) {
+		logger.Infof("skipping broadcast for unchanged price: %v", update)
+
+		// bars count every price received, so a bar is opened even when the price did not change
+		published = append(published, a.addCandles(update)...)
+
+		return
+	}
+
+	a.lastID++
+
+	update.ID = a.lastID
+	update.prevID = a.latestID(price.Symbol)
+	a.latestIDs[price.Symbol] = update.ID
+
+	// cached with the changes, so replayed prices carry the same ones as when broadcast
+	update = a.withChanges(update)
+
+	logger.Infof("broadcasting update: %v", update)
+
+	buffer.Add(update) // cache for reconnection if needed
+	a.broadcaster.Broadcast(price.Symbol, update)
+
+	published = append(published, update)
+	published = append(published, a.addCandles(update)...)
+}
+
+// addCandles adds the price to the bars of its symbol, and broadcasts the bars updated to their subscribers.
+// It returns the updates broadcast. It must be called with publishMu held.
+func (a *app) addCandles(price Price) []cache.CacheableEntity {
+	var updates []cache.CacheableEntity
+
+	for _, bar := range a.candles.add(price) {
+		update := toAppCandles(bar, price.UpdatedAt)
+		a.broadcaster.Broadcast(candleTopic(bar.Symbol, bar.Interval), update)
+
+		updates = append(updates, update)
+	}
+
+	return updates
+}
+
+// startCandles completes the bars in progress at the end of their interval until ctx is done.
+func (a *app) startCandles(ctx context.Context) {
+	if len(a.cfg.CandleIntervals) == 0 {
+		return
+	}
+
+	timer := time.NewTimer(time.Until(a.candles.next(time.Now())))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-timer.C:
+			a.closeCandles(ctx, now)
+
+			timer.Reset(time.Until(a.candles.next(time.Now())))
+		}
+	}
+}
+
+// closeCandles completes the bars in progress whose interval ended by now, and broadcasts them to their subscribers.
+func (a *app) closeCandles(ctx context.Context, now time.Time) {
+	var published []cache.CacheableEntity
+
+	a.publishMu.Lock()
+
+	for _, bar := range a.candles.close(now) {
+		update := toAppCandles(bar, now.UTC().Format(time.RFC3339))
+		a.broadcaster.Broadcast(candleTopic(bar.Symbol, bar.Interval), update)
+
+		published = append(published, update)
+	}
+
+	a.publishMu.Unlock()
+
+	a.publishWebhooks(ctx, published...)
+}
+
+// latestID returns the ID of the last published price of the symbol, or the start ID when none was published yet.
+// It must be called with publishMu held.
+func (a *app) latestID(symbol string) uint64 {
+	if id, ok := a.latestIDs[symbol]; ok {
+		return id
+	}
+
+	return a.startID
+}
+
+// withChanges sets the changes of the price since the previous one published of its symbol, and since the day open,
+// the first one published on the UTC day of its timestamp. It must be called with publishMu held.
+func (a *app) withChanges(price Price) Price {
+	if prev, ok := a.lastPrices[price.Symbol]; ok {
+		price.PrevPrice = &prev
+		price.Change, price.ChangePct = change(prev, price.Price)
+	}
+
+	a.lastPrices[price.Symbol] = price.Price
+
+	ts := price.Timestamp()
+	if ts.IsZero() {
+		return price
+	}
+
+	day := ts.UTC().Truncate(24 * time.Hour)
+
+	// a price late from a previous day does not reopen it, and is compared to the open of the current one
+	open, ok := a.dayOpens[price.Symbol]
+
+	switch {
+	case !ok:
+		// the day started before the first price published since a start
+		open = dayOpen{day: day, price: a.seedDayOpen(price, day)}
+		a.dayOpens[price.Symbol] = open
+	case day.After(open.day):
+		open = dayOpen{day: day, price: price.Price}
+		a.dayOpens[price.Symbol] = open
+	}
+
+	price.DayOpen = &open.price
+	price.DayChange, price.DayChangePct = change(open.price, price.Price)
+
+	return price
+}
+
+// seedDayOpen returns the open of the UTC day of a price published before any other of its symbol: the one of the
+// first cached price of the day, the price 24 hours earlier reported by upstream, or else the price itself.
+// It must be called with publishMu held.
+func (a *app) seedDayOpen(price Price, day time.Time) decimal.Decimal {
+	for _, entity := range a.caches[price.Symbol].Range(day, price.Timestamp()) {
+		cached, ok := entity.(Price)
+		if !ok {
+			continue
+		}
+
+		if cached.DayOpen != nil {
+			return *cached.DayOpen
+		}
+
+		return cached.Price
+	}
+
+	if price.Change24h != nil {
+		return price.Price.Sub(*price.Change24h)
+	}
+
+	return price.Price
+}
+
+// change returns the absolute and percent change from one price to another. The percent change, rounded to
+// 4 decimal places, is nil when from is zero.
+func change(from, to decimal.Decimal) (*decimal.Decimal, *decimal.Decimal) {
+	abs := to.Sub(from)
+
+	if from.IsZero() {
+		return &abs, nil
+	}
+
+	pct := abs.Div(from).Mul(decimal.NewFromInt(100)).Round(4)
+
+	return &abs, &pct
+}
+
+// missed returns the cached prices of the given symbols published after lastID, ordered by ID,
+// and the symbols whose missed prices are no longer all cached. It must be called with publishMu held.
+func (a *app) missed(symbols []string, lastID uint64) ([]Price, []string) {
+	// an ID that was never published comes from another timeline, e.g. before the clock was set back
+	if lastID > a.lastID {
+		return nil, symbols
+	}
+
+	var (
+		prices []Price
+		gaps   []string
+	)
+
+	for _, symbol := range symbols {
+		if a.latestID(symbol) <= lastID {
+			continue
+		}
+
+		var found []Price
+
+		for _, update := range a.caches[symbol].Since(time.Time{}) {
+			if price, ok := update.(Price); ok && price.ID > lastID {
+				found = append(found, price)
 			}
+		}
+
+		// every price links to the previous one of its symbol, so the oldest found
+		// must follow lastID for nothing to have been evicted in between
+		if len(found) == 0 || found[0].prevID > lastID {
+			gaps = append(gaps, symbol)
+		}
+
+		prices = append(prices, found...)
+	}
+
+	slices.SortFunc(prices, func(x, y Price) int {
+		return cmp.Compare(x.ID, y.ID)
+	})
+
+	return prices, gaps
+}
+
+// subscribe subscribes to the topics and takes the backfill of the symbols at once, while no price is published.
+// Every price up to the ID of the backfill was published before subscribing and every later one is received
+// from the subscriber, so sending the backfill first hands the client over to the live prices with none sent
+// twice or out of order.
+func (a *app) subscribe(
+	ctx context.Context,
+	opts pubsub.Options,
+	topics, symbols []string,
+	from replay,
+) (*pubsub.Subscriber, backfill) {
+	a.publishMu.Lock()
+	defer a.publishMu.Unlock()
 
-			update := toAppPrice(price)
+	sub := a.broadcaster.SubscribeWith(ctx, opts, topics...)
 
-			// if cached item is equal to current, then do not broadcast
-			if last, ok := a.cache.Last().(Price); ok && last.Price == update.Price {
-				logger.Infof("skipping broadcast for unchanged price: %v", update)
-				continue
+	fill := backfill{from: from, lastID: a.lastID}
+
+	if from.resume {
+		fill.prices, fill.gaps = a.missed(symbols, from.lastEventID)
+
+		return sub, fill
+	}
+
+	for _, symbol := range symbols {
+		var found []Price
+
+		if !from.since.IsZero() {
+			for _, update := range a.caches[symbol].Since(from.since) {
+				if price, ok := update.(Price); ok {
+					found = append(found, price)
+				}
 			}
+		}
+
+		// a client that got no price of the symbol gets the last one, so it knows the current price
+		if last, ok := a.caches[symbol].Last().(Price); ok && len(found) == 0 {
+			found = append(found, last)
+		}
+
+		fill.prices = append(fill.prices, found...)
+	}
+
+	slices.SortFunc(fill.prices, func(x, y Price) int {
+		return cmp.Compare(x.ID, y.ID)
+	})
+
+	return sub, fill
+}
+
+// subscribeTopics adds the symbols to the subscriber and sends it their last price, while no price is published,
+// so the last price is received before the later ones.
+func (a *app) subscribeTopics(sub *pubsub.Subscriber, symbols []string) {
+	a.publishMu.Lock()
+	defer a.publishMu.Unlock()
 
-			logger.Infof("broadcasting update: %v", update)
+	sub.AddTopics(symbols...)
 
-			a.cache.Add(update) // cache for reconnection if needed
-			a.broadcaster.Broadcast(update)
+	for _, symbol := range symbols {
+		if last, ok := a.caches[symbol].Last().(Price); ok {
+			a.broadcaster.SendOne(sub, last)
 		}
 	}
 }
 
+// sendBackfill sends the upstream status and the backfill. A resumed client gets the prices it missed first,
+// the others get the status first so they know right away whether silence means a quiet market.
+func (a *app) sendBackfill(fill backfill, send func(update cache.CacheableEntity) error) error {
+	updates := make([]cache.CacheableEntity, 0, len(fill.prices)+2)
+
+	if len(fill.gaps) > 0 {
+		updates = append(updates, toAppGap(fill.from.lastEventID, fill.gaps))
+	}
+
+	for _, price := range fill.prices {
+		updates = append(updates, price)
+	}
+
+	status := toAppStatus(a.priceBus.UpstreamState())
+
+	if fill.from.resume {
+		updates = append(updates, status)
+	} else {
+		updates = slices.Insert(updates, 0, cache.CacheableEntity(status))
+	}
+
+	for _, update := range updates {
+		if err := send(update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mark records the prices of the symbols up to the ID of the backfill as sent, as they are either in it
+// or were not meant to be sent.
+func (b backfill) mark(sent map[string]uint64, symbols []string) {
+	for _, symbol := range symbols {
+		sent[symbol] = max(sent[symbol], b.lastID)
+	}
+}
+
 func (a *app) priceStream(w http.ResponseWriter, r *http.Request) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -101,63 +671,93 @@ func (a *app) priceStream(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := log.Extract(ctx)
 
+	params, err := a.parsePriceStreamParams(r)
+	if err != nil {
+		logger.Errorf("failed to parse price-stream params: %s", err)
+
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
 	// Send initial message
-	_, err := fmt.Fprintln(w, ": connected")
+	if a.cfg.Retry > 0 {
+		_, err = fmt.Fprintf(w, "retry: %d\n: connected\n\n", a.retryHint(a.cfg.Retry).Milliseconds())
+	} else {
+		_, err = fmt.Fprint(w, ": connected\n\n")
+	}
+
 	if err != nil {
 		logger.Errorf("failed to send initial message: %s", err)
 	}
 
 	flusher.Flush()
 
-	since, err := a.parsePriceStreamParams(r)
-	if err != nil {
-		logger.Errorf("failed to parse price-stream params: %s", err)
+	topics := slices.Clone(params.symbols)
+	for _, id := range params.alerts {
+		topics = append(topics, alertTopic(id))
+	}
 
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	topics = append(topics, candleTopics(params.symbols, params.candles)...)
 
-		return
-	}
+	from := replay{resume: params.resume, lastEventID: params.lastEventID, since: params.since}
 
-	sub := a.broadcaster.Subscribe(ctx)
+	sub, fill := a.subscribe(ctx, params.overflow, topics, params.symbols, from)
 	defer a.broadcaster.Unsubscribe(ctx, sub)
 
-	if !since.IsZero() {
-		wait := make(chan struct{}, 1)
+	// ID of the last price sent by symbol, so the live ones already backfilled are not sent twice
+	sent := make(map[string]uint64, len(params.symbols))
 
-		logger.Infof("fetching prices since: %s", since)
-
-		go func() {
-			defer close(wait)
-			// Stream missed updates
-			for _, update := range a.cache.Since(since) {
-				if err := sendSSE(w, update); err != nil {
-					logger.Infof("client disconnected from price stream (send failed): %s", err)
+	switch {
+	case params.resume:
+		logger.Infof("resuming price stream after event %d", params.lastEventID)
+	case !params.since.IsZero():
+		logger.Infof("sending prices since: %s", params.since)
+	}
 
-					return
-				}
+	err = a.sendBackfill(fill, func(update cache.CacheableEntity) error {
+		if price, ok := update.(Price); ok {
+			// the throttle holds the live prices back from the replayed ones
+			params.throttle.sent(price, time.Now())
+		}
 
-				flusher.Flush()
-			}
-		}()
+		return sendSSE(w, update, params.format, params.numeric)
+	})
+	if err != nil {
+		logger.Infof("client disconnected from price stream (send failed): %s", err)
 
-		<-wait
+		return
 	}
 
-	logger.Infoln("client connected to price stream")
+	fill.mark(sent, params.symbols)
 
-	// send last price if available
-	if last, ok := a.cache.Last().(Price); ok {
-		a.broadcaster.SendOne(sub, last)
-	}
+	flusher.Flush()
+
+	logger.Infoln("client connected to price stream")
 
 	// add periodic ping to detect disconnections
 	pingTicker := time.NewTicker(2 * time.Second)
 	defer pingTicker.Stop()
 
+	// only ticks when prices may be held back by the throttle
+	var throttleTick <-chan time.Time
+
+	if tick := params.throttle.tick(); tick > 0 {
+		throttleTicker := time.NewTicker(tick)
+		defer throttleTicker.Stop()
+
+		throttleTick = throttleTicker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
-			logger.Infoln("client disconnected from price stream")
+			// streams run with the context of the service, which is only done when shutting down
+			logger.Infoln("service shutting down, closing price stream")
+
+			if err := sendSSE(w, toAppShutdown(a.retryHint(a.cfg.ShutdownRetry)), params.format, params.numeric); err == nil {
+				flusher.Flush()
+			}
 
 			return
 		case <-pingTicker.C:
@@ -169,8 +769,39 @@ func (a *app) priceStream(w http.ResponseWriter, r *http.Request) {
 			}
 
 			flusher.Flush()
-		case update := <-sub.Ch:
-			if err := sendSSE(w, update); err != nil {
+		case now := <-throttleTick:
+			for _, price := range params.throttle.due(now) {
+				sent[price.Symbol] = price.ID
+
+				if err := sendSSE(w, price, params.format, params.numeric); err != nil {
+					logger.Infof("client disconnected from price stream (send failed): %s", err)
+
+					return
+				}
+			}
+
+			flusher.Flush()
+		case update, ok := <-sub.Ch:
+			if !ok {
+				// disconnected by the overflow policy, after the gap
+				logger.Infof("client disconnected from price stream (too slow, dropped %d updates)", sub.Dropped())
+
+				return
+			}
+
+			if gap, ok := update.(pubsub.Gap); ok {
+				update = toAppDropGap(gap, lastSent(sent), params.symbols)
+			}
+
+			if price, ok := update.(Price); ok {
+				if price.ID <= sent[price.Symbol] || !params.throttle.allow(price, time.Now()) {
+					continue
+				}
+
+				sent[price.Symbol] = price.ID
+			}
+
+			if err := sendSSE(w, update, params.format, params.numeric); err != nil {
 				logger.Infof("client disconnected from price stream (send failed): %s", err)
 
 				return
@@ -181,15 +812,513 @@ func (a *app) priceStream(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// sendSSE sends a Server-Sent Event (SSE) to the client.
-func sendSSE(w http.ResponseWriter, update cache.CacheableEntity) error {
-	data, _ := json.Marshal(update)
-	_, err := fmt.Fprintf(w, "data: %s\n\n", data)
+// lastSent returns the ID of the last price sent, given the ID of the last one sent by symbol.
+func lastSent(sent map[string]uint64) uint64 {
+	var last uint64
+
+	for _, id := range sent {
+		last = max(last, id)
+	}
+
+	return last
+}
+
+// retryHint returns the reconnect delay suggested to a client, randomly spread so clients do not reconnect at once.
+func (a *app) retryHint(base time.Duration) time.Duration {
+	if a.cfg.RetryJitter <= 0 {
+		return base
+	}
+
+	jitter := math.Min(a.cfg.RetryJitter, 1)
+
+	return base + time.Duration(float64(base)*jitter*(2*rand.Float64()-1)) // nolint:gosec
+}
+
+// sendSSE sends a Server-Sent Event (SSE) to the client, named after the type of the update:
+// "price", "status", "gap", "shutdown", "candles" or "alert". Prices carry their ID so clients can resume
+// after it, and are encoded as JSON numbers when numeric is set. Shutdown notices set the reconnect delay.
+// The data is encoded in the format, base64 encoded for binary ones, and updates without an encoding in
+// the format are skipped.
+func sendSSE(w http.ResponseWriter, update cache.CacheableEntity, format web.Format, numeric bool) error {
+	data, err := format.Marshal(encodable(update, format, numeric))
+	if errors.Is(err, web.ErrUnsupportedFormat) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if format.Binary {
+		data = []byte(base64.StdEncoding.EncodeToString(data))
+	}
+
+	var event strings.Builder
+
+	if price, ok := update.(Price); ok && price.ID != 0 {
+		fmt.Fprintf(&event, "id: %d\n", price.ID)
+	}
+
+	fmt.Fprintf(&event, "event: %s\n", eventName(update))
+
+	if shutdown, ok := update.(Shutdown); ok {
+		fmt.Fprintf(&event, "retry: %d\n", shutdown.Retry)
+	}
+
+	fmt.Fprintf(&event, "data: %s\n\n", data)
+
+	_, err = io.WriteString(w, event.String())
 
 	return err
 }
 
-func (a *app) parsePriceStreamParams(r *http.Request) (time.Time, error) {
+// encodable returns the value an update is encoded from, the legacy form of prices when numeric is set.
+// Binary formats keep decimals exact, so numeric does not apply to them.
+func encodable(update cache.CacheableEntity, format web.Format, numeric bool) any {
+	if price, ok := update.(Price); ok && numeric && !format.Binary {
+		return price.numeric()
+	}
+
+	return update
+}
+
+// eventName returns the name of the event an update is sent as, to both SSE and WebSocket clients.
+func eventName(update cache.CacheableEntity) string {
+	switch update.(type) {
+	case Price:
+		return "price"
+	case Status:
+		return "status"
+	case Gap:
+		return "gap"
+	case Shutdown:
+		return "shutdown"
+	case Candles:
+		return "candles"
+	case Alert:
+		return "alert"
+	default:
+		return "message"
+	}
+}
+
+// eventSymbol returns the symbol of an update, or empty when it concerns every symbol.
+func eventSymbol(update cache.CacheableEntity) string {
+	switch u := update.(type) {
+	case Price:
+		return u.Symbol
+	case Candles:
+		return u.Symbol
+	case Alert:
+		return u.Symbol
+	default:
+		return ""
+	}
+}
+
+func (a *app) parsePriceStreamParams(r *http.Request) (priceStreamParams, error) {
+	symbols, err := a.parseSymbols(r.URL.Query().Get("symbols"))
+	if err != nil {
+		return priceStreamParams{}, err
+	}
+
+	numeric, err := parsePriceFormat(r)
+	if err != nil {
+		return priceStreamParams{}, err
+	}
+
+	conflation, err := parseThrottle(r.URL.Query())
+	if err != nil {
+		return priceStreamParams{}, err
+	}
+
+	alerts, err := a.parseAlerts(r.URL.Query().Get("alerts"))
+	if err != nil {
+		return priceStreamParams{}, err
+	}
+
+	candles, err := a.parseCandles(r.URL.Query().Get("candles"))
+	if err != nil {
+		return priceStreamParams{}, err
+	}
+
+	format, err := web.NegotiateFormat(r)
+	if err != nil {
+		return priceStreamParams{}, err
+	}
+
+	overflow, err := a.parseOverflow(r.URL.Query())
+	if err != nil {
+		return priceStreamParams{}, err
+	}
+
+	params := priceStreamParams{
+		symbols:  symbols,
+		numeric:  numeric,
+		format:   format,
+		throttle: conflation,
+		alerts:   alerts,
+		candles:  candles,
+		overflow: overflow,
+	}
+
+	// browsers reconnect to the same url, so a stale 'since' must not prevent resuming
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		params.lastEventID, err = strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			return priceStreamParams{}, fmt.Errorf("invalid 'Last-Event-ID' header: %v", err)
+		}
+
+		params.resume = true
+
+		return params, nil
+	}
+
+	params.since, err = a.parseSince(r)
+	if err != nil {
+		return priceStreamParams{}, err
+	}
+
+	return params, nil
+}
+
+// latestPrice returns the last cached price of the symbol in the path, or of the first configured symbol.
+func (a *app) latestPrice(_ context.Context, r *http.Request) web.Encoder {
+	numeric, err := parsePriceFormat(r)
+	if err != nil {
+		return web.NewError(http.StatusBadRequest, err)
+	}
+
+	symbol := normalizeSymbol(r.PathValue("symbol"))
+	if symbol == "" && len(a.cfg.Symbols) > 0 {
+		symbol = a.cfg.Symbols[0]
+	}
+
+	buffer, ok := a.caches[symbol]
+	if !ok {
+		return web.NewError(http.StatusBadRequest, fmt.Errorf("unsupported symbol %q", symbol))
+	}
+
+	last, ok := buffer.Last().(Price)
+	if !ok {
+		return web.NewError(http.StatusNotFound, fmt.Errorf("no price available for %s yet", symbol))
+	}
+
+	// streamed prices can change at any time, polled ones not before the next poll, however long ago they changed
+	var expiresAt time.Time
+	if !a.cfg.Streaming {
+		a.publishMu.Lock()
+		expiresAt = a.receivedAt[symbol].Add(a.priceBus.PollDelay(a.cfg.PollInterval, len(a.cfg.Symbols)))
+		a.publishMu.Unlock()
+	}
+
+	return toAppSnapshot(last, time.Now(), expiresAt, numeric)
+}
+
+// candleHistory returns the last completed candles of a symbol, the first configured one by default,
+// for an interval, the first configured one by default.
+func (a *app) candleHistory(_ context.Context, r *http.Request) web.Encoder {
+	query := r.URL.Query()
+
+	symbol := normalizeSymbol(query.Get("symbol"))
+	if symbol == "" && len(a.cfg.Symbols) > 0 {
+		symbol = a.cfg.Symbols[0]
+	}
+
+	if _, ok := a.caches[symbol]; !ok {
+		return web.NewError(http.StatusBadRequest, fmt.Errorf("unsupported symbol %q", symbol))
+	}
+
+	if len(a.cfg.CandleIntervals) == 0 {
+		return web.NewError(http.StatusNotFound, errors.New("candles are disabled"))
+	}
+
+	interval := a.cfg.CandleIntervals[0]
+
+	if intervalStr := query.Get("interval"); intervalStr != "" {
+		var err error
+		if interval, err = parseInterval(intervalStr); err != nil {
+			return web.NewError(http.StatusBadRequest, err)
+		}
+
+		if !slices.Contains(a.cfg.CandleIntervals, interval) {
+			return web.NewError(http.StatusBadRequest, fmt.Errorf("unsupported candle interval %q", intervalStr))
+		}
+	}
+
+	var limit int
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit <= 0 {
+			return web.NewError(http.StatusBadRequest, fmt.Errorf("invalid limit %q", limitStr))
+		}
+	}
+
+	return CandleHistory{
+		Symbol:   symbol,
+		Interval: formatInterval(interval),
+		Candles:  a.candles.completed(symbol, interval, limit),
+	}
+}
+
+// prices returns a page of the cached prices of the requested symbols updated within the requested time range.
+func (a *app) prices(_ context.Context, r *http.Request) web.Encoder {
+	query := r.URL.Query()
+
+	symbols, err := a.parseSymbols(query.Get("symbols"))
+	if err != nil {
+		return web.NewError(http.StatusBadRequest, err)
+	}
+
+	numeric, err := parsePriceFormat(r)
+	if err != nil {
+		return web.NewError(http.StatusBadRequest, err)
+	}
+
+	from, err := parseTime(query.Get("from"))
+	if err != nil {
+		return web.NewError(http.StatusBadRequest, fmt.Errorf("invalid 'from' timestamp format: %v", err))
+	}
+
+	to, err := parseTime(query.Get("to"))
+	if err != nil {
+		return web.NewError(http.StatusBadRequest, fmt.Errorf("invalid 'to' timestamp format: %v", err))
+	}
+
+	if !to.IsZero() && !from.Before(to) {
+		return web.NewError(http.StatusBadRequest, errors.New("'from' must be before 'to'"))
+	}
+
+	limit := maxPricesPerPage
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			return web.NewError(http.StatusBadRequest, fmt.Errorf("invalid limit %q", limitStr))
+		}
+	}
+
+	pg, err := page.NewCursor(query.Get("cursor"), limit)
+	if err != nil {
+		return web.NewError(http.StatusBadRequest, err)
+	}
+
+	// the cursor holds the ID of the last price of the previous page
+	var after uint64
+
+	if pg.After() != "" {
+		if after, err = strconv.ParseUint(pg.After(), 10, 64); err != nil {
+			return web.NewError(http.StatusBadRequest, fmt.Errorf("invalid cursor %q", query.Get("cursor")))
+		}
+	}
+
+	prices := a.priceRange(symbols, from, to, after)
+
+	var next string
+
+	if len(prices) > pg.RowsPerPage() {
+		prices = prices[:pg.RowsPerPage()]
+		next = page.Cursor(strconv.FormatUint(prices[len(prices)-1].ID, 10))
+	}
+
+	return toAppPricePage(prices, next, numeric)
+}
+
+// priceRange returns the cached prices of the given symbols updated from the given time until the given one,
+// with an ID greater than after, ordered by ID.
+func (a *app) priceRange(symbols []string, from, to time.Time, after uint64) []Price {
+	var prices []Price
+
+	for _, symbol := range symbols {
+		for _, update := range a.caches[symbol].Range(from, to) {
+			if price, ok := update.(Price); ok && price.ID > after {
+				prices = append(prices, price)
+			}
+		}
+	}
+
+	slices.SortFunc(prices, func(x, y Price) int {
+		return cmp.Compare(x.ID, y.ID)
+	})
+
+	return prices
+}
+
+// parseTime parses an optional RFC3339 timestamp, returning the zero time when empty.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseThrottle parses the min_interval and min_change_pct query parameters into the throttle conflating the prices
+// sent to a client. Both are optional, and prices are sent as soon as they change when omitted.
+func parseThrottle(query url.Values) (*throttle, error) {
+	var (
+		interval  time.Duration
+		minChange decimal.Decimal
+		err       error
+	)
+
+	if value := query.Get("min_interval"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval < 0 {
+			return nil, fmt.Errorf("invalid 'min_interval' %q, expected a duration such as 10s", value)
+		}
+	}
+
+	if value := query.Get("min_change_pct"); value != "" {
+		minChange, err = parseMinChange(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newThrottle(interval, minChange), nil
+}
+
+// parseMinChange parses the minimum move in percent of the throttle, as in the url and in WebSocket controls.
+func parseMinChange(value string) (decimal.Decimal, error) {
+	minChange, err := decimal.NewFromString(value)
+	if err != nil || minChange.IsNegative() {
+		return decimal.Zero, fmt.Errorf("invalid 'min_change_pct' %q, expected a positive percentage such as 0.05", value)
+	}
+
+	return minChange, nil
+}
+
+// parseOverflow parses the overflow policy of the queue of a subscriber, and the drops after which the
+// disconnect policy disconnects it. They default to the configured ones.
+func (a *app) parseOverflow(query url.Values) (pubsub.Options, error) {
+	opts := a.cfg.Overflow
+
+	if value := query.Get("overflow"); value != "" {
+		policy, err := pubsub.ParsePolicy(value)
+		if err != nil {
+			return pubsub.Options{}, err
+		}
+
+		opts.Policy = policy
+	}
+
+	if value := query.Get("max_drops"); value != "" {
+		maxDrops, err := strconv.Atoi(value)
+		if err != nil || maxDrops < 1 {
+			return pubsub.Options{}, fmt.Errorf("invalid 'max_drops' %q, expected a positive number", value)
+		}
+
+		opts.MaxDrops = maxDrops
+	}
+
+	return opts, nil
+}
+
+// parsePriceFormat parses the price_format query parameter, either "string" (default) or "number" for legacy
+// clients. It reports whether prices must be encoded as JSON numbers, which only applies to the JSON formats:
+// binary ones keep decimals as strings, so they stay exact.
+func parsePriceFormat(r *http.Request) (bool, error) {
+	var numeric bool
+
+	switch format := r.URL.Query().Get("price_format"); format {
+	case "", "string":
+	case "number":
+		numeric = true
+	default:
+		return false, fmt.Errorf("unsupported price format %q", format)
+	}
+
+	// an unknown format is answered once the response is encoded
+	if format, err := web.NegotiateFormat(r); err == nil && format.Binary {
+		return false, nil
+	}
+
+	return numeric, nil
+}
+
+// parseSymbols parses a comma separated list of symbols.
+// It returns all configured symbols if the list is empty.
+func (a *app) parseSymbols(symbolsStr string) ([]string, error) {
+	if strings.TrimSpace(symbolsStr) == "" {
+		return a.cfg.Symbols, nil
+	}
+
+	var symbols []string
+
+	for s := range strings.SplitSeq(symbolsStr, ",") {
+		symbol := normalizeSymbol(s)
+		if symbol == "" || slices.Contains(symbols, symbol) {
+			continue
+		}
+
+		if _, ok := a.caches[symbol]; !ok {
+			return nil, fmt.Errorf("unsupported symbol %q", symbol)
+		}
+
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols, nil
+}
+
+// parseAlerts parses a comma separated list of alert rule IDs, which must exist.
+func (a *app) parseAlerts(alertsStr string) ([]string, error) {
+	var ids []string
+
+	for s := range strings.SplitSeq(alertsStr, ",") {
+		id := strings.TrimSpace(s)
+		if id == "" || slices.Contains(ids, id) {
+			continue
+		}
+
+		if a.alertBus == nil {
+			return nil, errors.New("alerts are disabled")
+		}
+
+		if _, err := a.alertBus.Rule(id); err != nil {
+			return nil, fmt.Errorf("unknown alert rule %q", id)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// parseCandles parses a comma separated list of the intervals of the bars to send, which must be configured.
+// The intervals are returned formatted, as in the bars.
+func (a *app) parseCandles(candlesStr string) ([]string, error) {
+	var intervals []string
+
+	for s := range strings.SplitSeq(candlesStr, ",") {
+		value := strings.TrimSpace(s)
+		if value == "" {
+			continue
+		}
+
+		if len(a.cfg.CandleIntervals) == 0 {
+			return nil, errors.New("candles are disabled")
+		}
+
+		interval, err := parseInterval(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(a.cfg.CandleIntervals, interval) {
+			return nil, fmt.Errorf("unsupported candle interval %q", value)
+		}
+
+		if formatted := formatInterval(interval); !slices.Contains(intervals, formatted) {
+			intervals = append(intervals, formatted)
+		}
+	}
+
+	return intervals, nil
+}
+
+func (a *app) parseSince(r *http.Request) (time.Time, error) {
 	sinceStr := r.URL.Query().Get("since")
 
 	if sinceStr == "" {
@@ -212,3 +1341,7 @@ func (a *app) parsePriceStreamParams(r *http.Request) (time.Time, error) {
 
 	return sinceTime, nil
 }
+
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,1043 @@
+package priceapp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pubsub"
+	"github.com/gandarez/btc-price-service/internal/business/domain/alertbus"
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/page"
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+func TestNewApp_Symbols(t *testing.T) {
+	a := newTestApp(nil, " btc", "ETH", "btc", "")
+
+	assert.Equal(t, []string{"BTC", "ETH"}, a.cfg.Symbols)
+	assert.Len(t, a.caches, 2)
+}
+
+func TestParseSymbols(t *testing.T) {
+	a := newTestApp(nil, "BTC", "ETH", "SOL")
+
+	symbols, err := a.parseSymbols("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BTC", "ETH", "SOL"}, symbols)
+
+	symbols, err = a.parseSymbols("eth, btc,ETH")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ETH", "BTC"}, symbols)
+
+	_, err = a.parseSymbols("BTC,DOGE")
+	assert.EqualError(t, err, `unsupported symbol "DOGE"`)
+}
+
+func TestPoll_PerSymbol(t *testing.T) {
+	prices := map[string]decimal.Decimal{"BTC": decimal.NewFromInt(50000), "ETH": decimal.NewFromInt(3000)}
+
+	a := newTestApp(&mockPriceBusiness{
+		AssetPriceFn: func(_ context.Context, symbol string) (pricebus.Price, error) {
+			return pricebus.Price{
+				Symbol:    symbol,
+				Timestamp: time.Now().UTC().Format(time.RFC3339),
+				Price:     prices[symbol],
+			}, nil
+		},
+	}, "BTC", "ETH")
+
+	sub := a.broadcaster.Subscribe(t.Context(), "ETH")
+	defer a.broadcaster.Unsubscribe(t.Context(), sub)
+
+	a.poll(t.Context(), "BTC")
+	a.poll(t.Context(), "ETH")
+	a.poll(t.Context(), "ETH") // unchanged, must not be broadcast again
+
+	assert.Equal(t, 1, a.caches["BTC"].Len())
+	assert.Equal(t, 1, a.caches["ETH"].Len())
+
+	select {
+	case received := <-sub.Ch:
+		assert.Equal(t, "ETH", received.(Price).Symbol)
+	case <-time.After(100 * time.Millisecond):
+		t.Error("timeout waiting for message")
+	}
+
+	assert.Empty(t, sub.Ch)
+}
+
+func TestCheckUpstream(t *testing.T) {
+	priceBus := &mockPriceBusiness{}
+
+	a := newTestApp(priceBus, "BTC")
+
+	sub := a.broadcaster.Subscribe(t.Context(), "BTC")
+	defer a.broadcaster.Unsubscribe(t.Context(), sub)
+
+	a.checkUpstream(t.Context()) // closed, nothing changed
+
+	priceBus.State = breaker.StateOpen
+	a.checkUpstream(t.Context())
+	a.checkUpstream(t.Context()) // still open, must not be broadcast again
+
+	select {
+	case received := <-sub.Ch:
+		status, ok := received.(Status)
+		require.True(t, ok)
+		assert.Equal(t, "open", status.Upstream)
+		assert.False(t, status.Available)
+	case <-time.After(100 * time.Millisecond):
+		t.Error("timeout waiting for message")
+	}
+
+	assert.Empty(t, sub.Ch)
+}
+
+func TestStartStreaming(t *testing.T) {
+	var polls int
+
+	priceBus := &mockPriceBusiness{
+		AssetPriceFn: func(_ context.Context, _ string) (pricebus.Price, error) {
+			polls++
+			return pricebus.Price{}, nil
+		},
+	}
+
+	a := newTestApp(priceBus, "BTC")
+
+	priceBus.StreamPricesFn = func(
+		_ context.Context,
+		symbols []string,
+		onPrice func(pricebus.Price),
+		onState func(bool),
+	) error {
+		assert.Equal(t, []string{"BTC"}, symbols)
+
+		onState(true)
+		onPrice(pricebus.Price{Symbol: "BTC", Timestamp: "2021-10-01T07:20:00Z", Price: decimal.RequireFromString("50000")})
+		// not configured
+		onPrice(pricebus.Price{Symbol: "DOGE", Timestamp: "2021-10-01T07:20:00Z", Price: decimal.RequireFromString("0.25")})
+
+		assert.True(t, a.streaming.Load())
+
+		return nil
+	}
+
+	sub := a.broadcaster.Subscribe(t.Context(), pubsub.AllTopics)
+	defer a.broadcaster.Unsubscribe(t.Context(), sub)
+
+	a.startStreaming(t.Context())
+
+	select {
+	case received := <-sub.Ch:
+		assert.Equal(t, "BTC", received.(Price).Symbol)
+	case <-time.After(100 * time.Millisecond):
+		t.Error("timeout waiting for message")
+	}
+
+	assert.Empty(t, sub.Ch)
+	assert.Equal(t, 1, a.caches["BTC"].Len())
+	assert.Zero(t, polls)
+}
+
+func TestPublish_IDs(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+
+	publishPrices(t, a, "BTC", "50000", "50001")
+	publishPrices(t, a, "ETH", "3000")
+	publishPrices(t, a, "BTC", "50001") // unchanged, does not get an ID
+
+	btc := a.caches["BTC"].Last().(Price)
+	eth := a.caches["ETH"].Last().(Price)
+
+	assert.Equal(t, a.startID+2, btc.ID)
+	assert.Equal(t, a.startID+1, btc.prevID)
+	assert.Equal(t, a.startID+3, eth.ID)
+	assert.Equal(t, a.startID, eth.prevID)
+	assert.Equal(t, a.startID+3, a.lastID)
+}
+
+func TestPublish_Changes(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+
+	publishTestPrice(t.Context(), a, "BTC", "2025-01-01T23:59:00Z", "50000")
+	publishTestPrice(t.Context(), a, "ETH", "2025-01-01T23:59:30Z", "3000")
+	publishTestPrice(t.Context(), a, "BTC", "2025-01-02T00:00:10Z", "50500")
+	publishTestPrice(t.Context(), a, "BTC", "2025-01-02T00:00:15Z", "50500") // unchanged, not broadcast
+	publishTestPrice(t.Context(), a, "BTC", "2025-01-01T23:59:59Z", "50250") // late, the day is not reopened
+	publishTestPrice(t.Context(), a, "BTC", "2025-01-02T00:01:00Z", "49995")
+
+	var changes [][]string
+
+	for _, update := range a.caches["BTC"].Since(time.Time{}) {
+		p := update.(Price)
+		changes = append(changes, []string{
+			numberOrEmpty(p.PrevPrice).String(),
+			numberOrEmpty(p.Change).String(),
+			numberOrEmpty(p.ChangePct).String(),
+			numberOrEmpty(p.DayOpen).String(),
+			numberOrEmpty(p.DayChange).String(),
+			numberOrEmpty(p.DayChangePct).String(),
+		})
+	}
+
+	assert.Equal(t, [][]string{
+		{"", "", "", "50000", "0", "0"},
+		{"50000", "500", "1", "50500", "0", "0"},
+		{"50500", "-250", "-0.495", "50500", "-250", "-0.495"},
+		{"50250", "-255", "-0.5075", "50500", "-505", "-1"},
+	}, changes)
+
+	// the first price of each symbol has no previous one
+	eth := a.caches["ETH"].Last().(Price)
+	assert.Nil(t, eth.PrevPrice)
+	assert.Equal(t, "3000", eth.DayOpen.String())
+}
+
+func TestPublish_DayOpenSeed(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH", "SOL")
+
+	// started during the day, the open is the price 24 hours earlier reported by upstream
+	a.publish(t.Context(), pricebus.Price{
+		Symbol:    "BTC",
+		Timestamp: "2025-01-02T10:00:00Z",
+		Price:     decimal.RequireFromString("50500"),
+		Market:    pricebus.Market{Change24h: decimal.NewNullDecimal(decimal.RequireFromString("500"))},
+	})
+
+	btc := a.caches["BTC"].Last().(Price)
+	assert.Equal(t, "50000", btc.DayOpen.String())
+	assert.Equal(t, "500", btc.DayChange.String())
+	assert.Equal(t, "1", btc.DayChangePct.String())
+
+	// or the one of the first cached price of the day
+	a.caches["ETH"].Add(Price{Symbol: "ETH", UpdatedAt: "2025-01-01T23:00:00Z", Price: decimal.RequireFromString("2800")})
+	a.caches["ETH"].Add(Price{Symbol: "ETH", UpdatedAt: "2025-01-02T08:00:00Z", Price: decimal.RequireFromString("2900")})
+
+	publishTestPrice(t.Context(), a, "ETH", "2025-01-02T10:00:00Z", "3045")
+
+	eth := a.caches["ETH"].Last().(Price)
+	assert.Equal(t, "2900", eth.DayOpen.String())
+	assert.Equal(t, "5", eth.DayChangePct.String())
+
+	// or else the price itself
+	publishTestPrice(t.Context(), a, "SOL", "2025-01-02T10:00:00Z", "200")
+
+	sol := a.caches["SOL"].Last().(Price)
+	assert.Equal(t, "200", sol.DayOpen.String())
+	assert.Equal(t, "0", sol.DayChange.String())
+
+	// the next day opens with its first price, whatever upstream reports
+	a.publish(t.Context(), pricebus.Price{
+		Symbol:    "BTC",
+		Timestamp: "2025-01-03T00:00:05Z",
+		Price:     decimal.RequireFromString("51000"),
+		Market:    pricebus.Market{Change24h: decimal.NewNullDecimal(decimal.RequireFromString("900"))},
+	})
+
+	btc = a.caches["BTC"].Last().(Price)
+	assert.Equal(t, "51000", btc.DayOpen.String())
+	assert.Equal(t, "0", btc.DayChange.String())
+}
+
+func TestMissed(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH", "SOL")
+
+	publishPrices(t, a, "BTC", "50000")
+	publishPrices(t, a, "ETH", "3000")
+	publishPrices(t, a, "BTC", "50001")
+
+	start := a.startID
+
+	tests := map[string]struct {
+		lastID  uint64
+		symbols []string
+		ids     []uint64
+		gaps    []string
+	}{
+		"up to date": {
+			lastID:  start + 3,
+			symbols: []string{"BTC", "ETH", "SOL"},
+		},
+		"missed some": {
+			lastID:  start + 1,
+			symbols: []string{"BTC", "ETH", "SOL"},
+			ids:     []uint64{start + 2, start + 3},
+		},
+		"filtered by symbol": {
+			lastID:  start,
+			symbols: []string{"ETH"},
+			ids:     []uint64{start + 2},
+		},
+		"before restart": {
+			lastID:  start - 5,
+			symbols: []string{"BTC", "SOL"},
+			ids:     []uint64{start + 1, start + 3},
+			gaps:    []string{"BTC", "SOL"},
+		},
+		"unknown id": {
+			lastID:  start + 10,
+			symbols: []string{"BTC", "ETH"},
+			gaps:    []string{"BTC", "ETH"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prices, gaps := a.missed(test.symbols, test.lastID)
+
+			var ids []uint64
+			for _, p := range prices {
+				ids = append(ids, p.ID)
+			}
+
+			assert.Equal(t, test.ids, ids)
+			assert.Equal(t, test.gaps, gaps)
+		})
+	}
+}
+
+func TestMissed_Evicted(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	publishPrices(t, a, "BTC", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12")
+
+	// the first two were evicted from the cache of 10
+	prices, gaps := a.missed([]string{"BTC"}, a.startID+1)
+	assert.Len(t, prices, 10)
+	assert.Equal(t, []string{"BTC"}, gaps)
+
+	prices, gaps = a.missed([]string{"BTC"}, a.startID+2)
+	assert.Len(t, prices, 10)
+	assert.Empty(t, gaps)
+}
+
+func TestPriceStream_LastEventID(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+
+	publishPrices(t, a, "BTC", "50000", "50001")
+	publishPrices(t, a, "ETH", "3000")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	// 'since' is ignored when resuming, browsers reconnect to the original url
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/v1/price-stream?since=2000-01-01T00:00:00Z", nil)
+	req.Header.Set("Last-Event-ID", strconv.FormatUint(a.startID+1, 10))
+
+	w := httptest.NewRecorder()
+	a.priceStream(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+
+	assert.NotContains(t, body, "event: gap")
+	assert.NotContains(t, body, `"price":"50000"`)
+	assert.Equal(t, 1, strings.Count(body, `"price":"50001"`))
+	assert.Equal(t, 1, strings.Count(body, `"price":"3000"`))
+	assert.Contains(t, body, fmt.Sprintf("id: %d\n", a.startID+3))
+}
+
+func TestPriceStream_LastEventID_Gap(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	publishPrices(t, a, "BTC", "50000")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/v1/price-stream", nil)
+	req.Header.Set("Last-Event-ID", "42")
+
+	w := httptest.NewRecorder()
+	a.priceStream(w, req)
+
+	assert.Contains(t, w.Body.String(), `event: gap`+"\n"+`data: {"last_event_id":42,"symbols":["BTC"]`)
+	assert.Contains(t, w.Body.String(), `"price":"50000"`)
+}
+
+func TestPriceStream_LastEventID_Invalid(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/price-stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+
+	w := httptest.NewRecorder()
+	a.priceStream(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPriceStream_Handoff(t *testing.T) {
+	tests := map[string]struct {
+		query  string
+		resume bool
+		// prices of the backfill, then the ones published while it is sent
+		expected []string
+	}{
+		"last price": {
+			expected: []string{"ETH 3000", "BTC 50002", "BTC 50003", "ETH 3001"},
+		},
+		"since": {
+			query:    "?since=" + time.Now().Add(-30*time.Second).UTC().Format(time.RFC3339),
+			expected: []string{"BTC 50000", "BTC 50001", "ETH 3000", "BTC 50002", "BTC 50003", "ETH 3001"},
+		},
+		"resume": {
+			resume:   true,
+			expected: []string{"BTC 50001", "ETH 3000", "BTC 50002", "BTC 50003", "ETH 3001"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+
+			publishPrices(t, a, "BTC", "50000", "50001")
+			publishPrices(t, a, "ETH", "3000")
+			publishPrices(t, a, "BTC", "50002")
+
+			ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+			defer cancel()
+
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/v1/price-stream"+test.query, nil)
+			if test.resume {
+				req.Header.Set("Last-Event-ID", strconv.FormatUint(a.startID+1, 10))
+			}
+
+			w := &streamRecorder{
+				ResponseRecorder: httptest.NewRecorder(),
+				onPrice: func(n int) {
+					// published once subscribed, while the backfill is still being sent
+					if n == 1 {
+						publishPrices(t, a, "BTC", "50003")
+						publishPrices(t, a, "ETH", "3001")
+					}
+
+					if n == len(test.expected) {
+						cancel()
+					}
+				},
+			}
+
+			a.priceStream(w, req)
+
+			assert.Equal(t, test.expected, w.prices)
+			assertSequence(t, w.Body.String())
+		})
+	}
+}
+
+func TestPriceStream_Handoff_Concurrent(t *testing.T) {
+	const published = 200
+
+	a := newApp(Config{
+		BufferTTL:                 time.Minute,
+		MaxCacheSize:              published,
+		DefaultExpirationInterval: time.Minute,
+		MaxPeersPerBroadcaster:    10,
+		Symbols:                   []string{"BTC", "ETH"},
+		Overflow:                  pubsub.Options{BufferSize: published},
+	})
+	a.priceBus = &mockPriceBusiness{}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	// prices are published before, while and after the client subscribes
+	go func() {
+		for i := range published {
+			publishPrices(t, a, []string{"BTC", "ETH"}[i%2], strconv.Itoa(1000+i))
+		}
+	}()
+
+	since := time.Now().Add(-30 * time.Second).UTC().Format(time.RFC3339)
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/v1/price-stream?since="+since, nil)
+
+	w := &streamRecorder{
+		ResponseRecorder: httptest.NewRecorder(),
+		onPrice: func(n int) {
+			if n == published {
+				cancel()
+			}
+		},
+	}
+
+	a.priceStream(w, req)
+
+	require.Len(t, w.prices, published)
+	assertSequence(t, w.Body.String())
+}
+
+// streamRecorder records a price stream, calling onPrice right after each price is written
+// with the number of prices written so far.
+type streamRecorder struct {
+	*httptest.ResponseRecorder
+	prices  []string // symbol and price of each price written
+	onPrice func(n int)
+}
+
+func (r *streamRecorder) Write(data []byte) (int, error) {
+	n, err := r.ResponseRecorder.Write(data)
+
+	if !strings.Contains(string(data), "event: price\n") {
+		return n, err
+	}
+
+	var price struct {
+		Symbol string `json:"symbol"`
+		Price  string `json:"price"`
+	}
+
+	_, payload, _ := strings.Cut(string(data), "data: ")
+	if err := json.Unmarshal([]byte(payload), &price); err == nil {
+		r.prices = append(r.prices, price.Symbol+" "+price.Price)
+	}
+
+	r.onPrice(len(r.prices))
+
+	return n, err
+}
+
+func (r *streamRecorder) WriteString(data string) (int, error) {
+	return r.Write([]byte(data))
+}
+
+// assertSequence asserts that the IDs of the prices of the stream follow each other,
+// so none was sent twice or out of order.
+func assertSequence(t *testing.T, body string) {
+	t.Helper()
+
+	var last uint64
+
+	for line := range strings.Lines(body) {
+		value, ok := strings.CutPrefix(strings.TrimSuffix(line, "\n"), "id: ")
+		if !ok {
+			continue
+		}
+
+		id, err := strconv.ParseUint(value, 10, 64)
+		require.NoError(t, err)
+
+		assert.Greater(t, id, last, "price %d sent after %d", id, last)
+
+		last = id
+	}
+}
+
+func TestRetryHint(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	assert.Equal(t, time.Second, a.retryHint(time.Second))
+
+	a.cfg.RetryJitter = 0.5
+
+	for range 100 {
+		hint := a.retryHint(time.Second)
+		assert.GreaterOrEqual(t, hint, 500*time.Millisecond)
+		assert.LessOrEqual(t, hint, 1500*time.Millisecond)
+	}
+}
+
+func TestPriceStream_Events(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+	a.cfg.Retry = 3 * time.Second
+	a.cfg.ShutdownRetry = 10 * time.Second
+
+	publishPrices(t, a, "BTC", "50000")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/v1/price-stream", nil)
+
+	w := httptest.NewRecorder()
+	a.priceStream(w, req) // returns as if the service was shutting down
+
+	body := w.Body.String()
+
+	assert.True(t, strings.HasPrefix(body, "retry: 3000\n: connected\n\n"))
+	assert.Contains(t, body, "event: status\n"+`data: {"upstream":"closed","available":true`)
+	assert.Contains(t, body, "event: price\n"+`data: {"symbol":"BTC"`)
+	assert.True(t, strings.HasPrefix(body[strings.Index(body, "event: shutdown"):],
+		"event: shutdown\nretry: 10000\n"+`data: {"retry_ms":10000`))
+}
+
+func TestParseOverflow(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+	a.cfg.Overflow = pubsub.Options{Policy: pubsub.PolicyDropOldest, BufferSize: 50, MaxDrops: 10}
+
+	tests := map[string]struct {
+		Query    string
+		Expected pubsub.Options
+		Error    string
+	}{
+		"default": {
+			Expected: pubsub.Options{Policy: pubsub.PolicyDropOldest, BufferSize: 50, MaxDrops: 10},
+		},
+		"policy": {
+			Query:    "overflow=disconnect&max_drops=3",
+			Expected: pubsub.Options{Policy: pubsub.PolicyDisconnect, BufferSize: 50, MaxDrops: 3},
+		},
+		"invalid policy": {
+			Query: "overflow=block",
+			Error: `invalid overflow policy "block", expected drop-oldest, keep-latest or disconnect`,
+		},
+		"invalid max drops": {
+			Query: "max_drops=0",
+			Error: `invalid 'max_drops' "0", expected a positive number`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			query, err := url.ParseQuery(test.Query)
+			require.NoError(t, err)
+
+			opts, err := a.parseOverflow(query)
+			if test.Error != "" {
+				assert.EqualError(t, err, test.Error)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, opts)
+		})
+	}
+}
+
+func TestLatestPrice(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+	a.cfg.PollInterval = time.Minute
+
+	publishPrices(t, a, "BTC", "50000.5")
+
+	server := newTestWebApp(t.Context(), a)
+
+	tests := map[string]struct {
+		Path     string
+		Status   int
+		Contains string
+	}{
+		"default symbol":     {Path: "/v1/price", Status: http.StatusOK, Contains: `"symbol":"BTC"`},
+		"symbol":             {Path: "/v1/price/btc", Status: http.StatusOK, Contains: `"price":"50000.5"`},
+		"numeric":            {Path: "/v1/price/BTC?price_format=number", Status: http.StatusOK, Contains: `"price":50000.5`},
+		"not yet available":  {Path: "/v1/price/ETH", Status: http.StatusNotFound, Contains: `{"error":"no price`},
+		"unsupported symbol": {Path: "/v1/price/DOGE", Status: http.StatusBadRequest, Contains: `unsupported symbol`},
+		"invalid format":     {Path: "/v1/price?price_format=float", Status: http.StatusBadRequest},
+		"compact":            {Path: "/v1/price/BTC?format=compact", Status: http.StatusOK, Contains: `,"BTC","`},
+		"unknown format":     {Path: "/v1/price?format=xml", Status: http.StatusBadRequest, Contains: `invalid 'format'`},
+		"not acceptable":     {Path: "/v1/prices?format=compact", Status: http.StatusNotAcceptable, Contains: `{"error":`},
+		"numeric msgpack": {
+			Path:     "/v1/price/BTC?format=msgpack&price_format=number",
+			Status:   http.StatusOK,
+			Contains: "\xa750000.5", // a string, numbers would lose precision
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, test.Path, nil))
+
+			assert.Equal(t, test.Status, w.Code)
+			assert.Contains(t, w.Body.String(), test.Contains)
+		})
+	}
+}
+
+func TestLatestPrice_NotModified(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+	a.cfg.PollInterval = time.Minute
+
+	publishPrices(t, a, "BTC", "50000")
+
+	server := newTestWebApp(t.Context(), a)
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/price/BTC", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"id":%d,"age_ms":`, a.lastID))
+	assert.Regexp(t, `^public, max-age=(5[89]|60)$`, w.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+
+	etag := w.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `W/"BTC-`))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/price/BTC", nil)
+	req.Header.Set("If-None-Match", etag)
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	// a new price changes the tag
+	publishPrices(t, a, "BTC", "50001")
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestLatestPrice_MaxAge(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+	a.cfg.PollInterval = time.Minute
+
+	// unchanged for a while, but just polled
+	a.publish(t.Context(), pricebus.Price{
+		Symbol:    "BTC",
+		Timestamp: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+		Price:     decimal.RequireFromString("50000"),
+	})
+
+	server := newTestWebApp(t.Context(), a)
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/price/BTC", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `^public, max-age=(5[89]|60)$`, w.Header().Get("Cache-Control"))
+
+	a.cfg.Streaming = true
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/price/BTC", nil))
+
+	assert.Equal(t, "public, max-age=0", w.Header().Get("Cache-Control"))
+}
+
+func TestPrices(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+
+	for i, ts := range []string{"2025-01-01T10:00:00Z", "2025-01-01T10:30:00Z", "2025-01-01T11:00:00Z"} {
+		a.publish(t.Context(), pricebus.Price{Symbol: "BTC", Timestamp: ts, Price: decimal.NewFromInt(int64(50000 + i))})
+	}
+
+	a.publish(t.Context(), pricebus.Price{Symbol: "ETH", Timestamp: "2025-01-01T10:15:00Z", Price: decimal.NewFromInt(3000)})
+
+	server := newTestWebApp(t.Context(), a)
+
+	get := func(path string) (int, pricePageResponse) {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, path, nil))
+
+		var resp pricePageResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+
+		return w.Code, resp
+	}
+
+	// ordered by ID, the same as published
+	code, resp := get("/v1/prices?limit=2")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"BTC:50000", "BTC:50001"}, resp.prices())
+	require.NotEmpty(t, resp.NextCursor)
+
+	code, resp = get("/v1/prices?limit=2&cursor=" + resp.NextCursor)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"BTC:50002", "ETH:3000"}, resp.prices())
+	assert.Empty(t, resp.NextCursor)
+
+	code, resp = get("/v1/prices?symbols=btc&from=2025-01-01T10:30:00Z&to=2025-01-01T11:00:00Z")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"BTC:50001"}, resp.prices())
+	assert.Equal(t, a.lastID-2, resp.Prices[0].ID)
+
+	code, resp = get("/v1/prices?from=2025-01-02T00:00:00Z")
+	require.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, resp.Prices)
+	assert.Empty(t, resp.Prices)
+
+	for _, path := range []string{
+		"/v1/prices?symbols=DOGE",
+		"/v1/prices?from=yesterday",
+		"/v1/prices?to=2025-01-01",
+		"/v1/prices?from=2025-01-01T11:00:00Z&to=2025-01-01T10:00:00Z",
+		"/v1/prices?limit=0",
+		"/v1/prices?limit=1000",
+		"/v1/prices?limit=ten",
+		"/v1/prices?cursor=invalid!",
+		"/v1/prices?cursor=" + page.Cursor("BTC"),
+		"/v1/prices?price_format=float",
+	} {
+		code, _ := get(path)
+		assert.Equal(t, http.StatusBadRequest, code, path)
+	}
+}
+
+// pricePageResponse is the decoded response of the price range query.
+type pricePageResponse struct {
+	Prices []struct {
+		ID     uint64 `json:"id"`
+		Symbol string `json:"symbol"`
+		Price  string `json:"price"`
+	} `json:"prices"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func (r pricePageResponse) prices() []string {
+	prices := make([]string, 0, len(r.Prices))
+	for _, p := range r.Prices {
+		prices = append(prices, p.Symbol+":"+p.Price)
+	}
+
+	return prices
+}
+
+func newTestWebApp(ctx context.Context, a *app) *web.App {
+	server := web.NewApp()
+	server.HandlerFunc(ctx, http.MethodGet, "v1", "/prices", a.prices)
+	server.HandlerFunc(ctx, http.MethodGet, "v1", "/price", a.latestPrice)
+	server.HandlerFunc(ctx, http.MethodGet, "v1", "/price/{symbol}", a.latestPrice)
+
+	return server
+}
+
+func TestPriceStream_Throttle(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	publishPrices(t, a, "BTC", "50000")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 300*time.Millisecond)
+	defer cancel()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		publishPrices(t, a, "BTC", "50010", "50100", "50200", "50300")
+	}()
+
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet,
+		"/v1/price-stream?min_change_pct=0.1&min_interval=100ms", nil)
+
+	w := httptest.NewRecorder()
+	a.priceStream(w, req)
+
+	body := w.Body.String()
+
+	// the last price goes through, 50010 did not move enough and only the latest of the others follows
+	assert.Equal(t, 2, strings.Count(body, "event: price\n"))
+	assert.Contains(t, body, `"price":"50000"`)
+	assert.Contains(t, body, `"price":"50300"`)
+}
+
+func TestPriceStream_Alerts(t *testing.T) {
+	alertBus := alertbus.NewBusiness(nil)
+
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+	a.alertBus = alertBus
+
+	above, err := alertBus.Create(alertbus.NewRule{
+		Symbol:    "BTC",
+		Kind:      alertbus.KindCrosses,
+		Threshold: decimal.NewFromInt(100000),
+	})
+	require.NoError(t, err)
+
+	other, err := alertBus.Create(alertbus.NewRule{
+		Symbol:    "BTC",
+		Kind:      alertbus.KindCrosses,
+		Threshold: decimal.NewFromInt(99500),
+	})
+	require.NoError(t, err)
+
+	publishPrices(t, a, "BTC", "99000")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		publishPrices(t, a, "BTC", "100500")
+	}()
+
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/v1/price-stream?alerts="+above.ID, nil)
+
+	w := httptest.NewRecorder()
+	a.priceStream(w, req)
+
+	body := w.Body.String()
+
+	// only the alerts of the requested rules are sent, after the price that fired them
+	assert.Equal(t, 1, strings.Count(body, "event: alert\n"))
+	assert.Contains(t, body, "event: alert\n"+
+		`data: {"rule_id":"`+above.ID+`","symbol":"BTC","kind":"crosses","price":"100500","message":"BTC crossed above 100000"`)
+	assert.NotContains(t, body, other.ID)
+	assert.Less(t, strings.Index(body, `"price":"100500"`), strings.Index(body, "event: alert"))
+}
+
+func TestPriceStream_Alerts_Unknown(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	w := httptest.NewRecorder()
+	a.priceStream(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/price-stream?alerts=abc", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "alerts are disabled\n", w.Body.String())
+
+	a.alertBus = alertbus.NewBusiness(nil)
+
+	w = httptest.NewRecorder()
+	a.priceStream(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/price-stream?alerts=abc", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `unknown alert rule "abc"`)
+}
+
+func TestCheckStale(t *testing.T) {
+	alertBus := alertbus.NewBusiness(nil)
+
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+	a.alertBus = alertBus
+
+	rule, err := alertBus.Create(alertbus.NewRule{Symbol: "BTC", Kind: alertbus.KindStale, Window: time.Millisecond})
+	require.NoError(t, err)
+
+	sub := a.broadcaster.Subscribe(t.Context(), alertTopic(rule.ID))
+	defer a.broadcaster.Unsubscribe(t.Context(), sub)
+
+	time.Sleep(5 * time.Millisecond)
+
+	a.checkStale(t.Context())
+
+	select {
+	case update := <-sub.Ch:
+		alert, ok := update.(Alert)
+		require.True(t, ok)
+
+		assert.Equal(t, rule.ID, alert.RuleID)
+		assert.Equal(t, "stale", alert.Kind)
+		assert.Equal(t, "alert", eventName(alert))
+	case <-time.After(time.Second):
+		require.Fail(t, "alert not broadcast")
+	}
+}
+
+func TestBroadcast_Webhooks(t *testing.T) {
+	webhooks := &mockWebhookBusiness{}
+
+	a := newTestApp(&mockPriceBusiness{State: breaker.StateOpen}, "BTC")
+	a.webhookBus = webhooks
+
+	publishTestPrice(t.Context(), a, "BTC", "2025-01-01T10:00:00Z", "50000")
+	publishTestPrice(t.Context(), a, "BTC", "2025-01-01T10:00:05Z", "50000") // unchanged, not broadcast
+	a.checkUpstream(t.Context())
+
+	require.Len(t, webhooks.events, 2)
+
+	assert.Equal(t, "price", webhooks.events[0].Name)
+	assert.Equal(t, "BTC", webhooks.events[0].Symbol)
+	assert.JSONEq(t, `{"symbol":"BTC","timestamp":"2025-01-01T10:00:00Z","price":"50000",`+
+		`"day_open":"50000","day_change":"0","day_change_pct":"0"}`, string(webhooks.events[0].Data))
+	assert.Equal(t, time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), webhooks.events[0].Timestamp)
+
+	assert.Equal(t, "status", webhooks.events[1].Name)
+	assert.Empty(t, webhooks.events[1].Symbol)
+	assert.Contains(t, string(webhooks.events[1].Data), `"upstream":"open","available":false`)
+}
+
+func TestParseThrottle(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	for _, query := range []string{"min_interval=10", "min_interval=-1s", "min_change_pct=abc", "min_change_pct=-0.5"} {
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/price-stream?"+query, nil)
+
+		_, err := a.parsePriceStreamParams(req)
+		assert.Error(t, err, query)
+	}
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet,
+		"/v1/price-stream?min_interval=10s&min_change_pct=0.05", nil)
+
+	params, err := a.parsePriceStreamParams(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, 10*time.Second, params.throttle.interval)
+	assert.Equal(t, "0.05", params.throttle.minChange.String())
+}
+
+func publishPrices(t *testing.T, a *app, symbol string, prices ...string) {
+	t.Helper()
+
+	for _, price := range prices {
+		a.publish(t.Context(), pricebus.Price{
+			Symbol:    symbol,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Price:     decimal.RequireFromString(price),
+		})
+	}
+}
+
+func newTestApp(priceBus PriceBusiness, symbols ...string) *app {
+	a := newApp(Config{
+		BufferTTL:                 time.Minute,
+		MaxCacheSize:              10,
+		DefaultExpirationInterval: time.Minute,
+		PollInterval:              time.Second,
+		MaxPeersPerBroadcaster:    10,
+		Symbols:                   symbols,
+	})
+	a.priceBus = priceBus
+
+	return a
+}
+
+type mockPriceBusiness struct {
+	AssetPriceFn   func(ctx context.Context, symbol string) (pricebus.Price, error)
+	State          breaker.State
+	StreamPricesFn func(ctx context.Context, symbols []string, onPrice func(pricebus.Price), onState func(bool)) error
+}
+
+func (m *mockPriceBusiness) AssetPrice(ctx context.Context, symbol string) (pricebus.Price, error) {
+	return m.AssetPriceFn(ctx, symbol)
+}
+
+func (m *mockPriceBusiness) UpstreamState() breaker.State {
+	return m.State
+}
+
+func (*mockPriceBusiness) PollDelay(interval time.Duration, _ int) time.Duration {
+	return interval
+}
+
+func (m *mockPriceBusiness) StreamPrices(
+	ctx context.Context,
+	symbols []string,
+	onPrice func(pricebus.Price),
+	onState func(bool),
+) error {
+	if m.StreamPricesFn == nil {
+		return pricebus.ErrNoStreamer
+	}
+
+	return m.StreamPricesFn(ctx, symbols, onPrice, onState)
+}
+
+type mockWebhookBusiness struct {
+	events []webhookbus.Event
+}
+
+func (m *mockWebhookBusiness) Publish(_ context.Context, event webhookbus.Event) {
+	m.events = append(m.events, event)
+}
@@ -2,8 +2,10 @@ package priceapp
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/gandarez/btc-price-service/internal/app/sdk/mux"
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pricepb"
 	"github.com/gandarez/btc-price-service/internal/foundation/web"
 )
 
@@ -16,10 +18,35 @@ func Routes(ctx context.Context, app *web.App, cfg mux.Config) {
 		MaxCacheSize:              cfg.PriceConfig.MaxCacheSize,
 		DefaultExpirationInterval: cfg.PriceConfig.DefaultExpirationInterval,
 		PollInterval:              cfg.PriceConfig.PollInterval,
+		MaxPeersPerBroadcaster:    cfg.PriceConfig.MaxPeersPerBroadcaster,
+		Overflow:                  cfg.PriceConfig.Overflow,
+		Symbols:                   cfg.PriceConfig.Symbols,
+		Streaming:                 cfg.PriceConfig.Streaming,
+		Retry:                     cfg.PriceConfig.Retry,
+		ShutdownRetry:             cfg.PriceConfig.ShutdownRetry,
+		RetryJitter:               cfg.PriceConfig.RetryJitter,
+		CandleIntervals:           cfg.PriceConfig.CandleIntervals,
+		CandleMaxBars:             cfg.PriceConfig.CandleMaxBars,
 		PriceBus:                  cfg.PriceConfig.PriceBus,
+		AlertBus:                  cfg.PriceConfig.AlertBus,
+		WebhookBus:                cfg.PriceConfig.WebhookBus,
 	})
 
 	go api.startPolling(ctx)
+	go api.startCandles(ctx)
 
+	if api.cfg.Streaming {
+		go api.startStreaming(ctx)
+	}
+
+	app.HandlerFunc(ctx, http.MethodGet, version, "/price", api.latestPrice)
+	app.HandlerFunc(ctx, http.MethodGet, version, "/price/{symbol}", api.latestPrice)
+	app.HandlerFunc(ctx, http.MethodGet, version, "/prices", api.prices)
+	app.HandlerFunc(ctx, http.MethodGet, version, "/candles", api.candleHistory)
 	app.HandlerFuncStream(ctx, version, "/price-stream", api.priceStream)
+	app.HandlerFuncStream(ctx, version, "/price-ws", api.priceWS)
+
+	if cfg.PriceConfig.GRPC != nil {
+		pricepb.RegisterPriceServiceServer(cfg.PriceConfig.GRPC, &grpcServer{app: api, ctx: ctx})
+	}
 }
@@ -0,0 +1,114 @@
+package priceapp
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// throttle conflates the prices sent to a client. It limits how often the prices of a symbol are sent,
+// and skips the ones that did not move enough from the last one sent.
+// Prices arriving in between are held back, and only the latest one of each symbol is sent once due.
+type throttle struct {
+	interval  time.Duration
+	minChange decimal.Decimal            // minimum move in percent from the last price sent, zero sends every move
+	last      map[string]time.Time       // when a price of the symbol was last sent
+	lastPrice map[string]decimal.Decimal // last price of the symbol sent
+	pending   map[string]Price           // latest price of the symbol held back
+}
+
+func newThrottle(interval time.Duration, minChangePct decimal.Decimal) *throttle {
+	return &throttle{
+		interval:  interval,
+		minChange: minChangePct,
+		last:      make(map[string]time.Time),
+		lastPrice: make(map[string]decimal.Decimal),
+		pending:   make(map[string]Price),
+	}
+}
+
+// allow reports whether the price can be sent now, holding it back otherwise.
+// A price that did not move enough replaces the one held back, since only the latest price matters.
+func (t *throttle) allow(price Price, now time.Time) bool {
+	if !t.changed(price) {
+		delete(t.pending, price.Symbol)
+
+		return false
+	}
+
+	if t.interval <= 0 || now.Sub(t.last[price.Symbol]) >= t.interval {
+		t.sent(price, now)
+
+		return true
+	}
+
+	t.pending[price.Symbol] = price
+
+	return false
+}
+
+// due returns the prices held back that can be sent now, ordered by ID, and forgets them.
+func (t *throttle) due(now time.Time) []Price {
+	var prices []Price
+
+	for symbol, price := range t.pending {
+		if now.Sub(t.last[symbol]) >= t.interval {
+			prices = append(prices, price)
+			t.sent(price, now)
+		}
+	}
+
+	slices.SortFunc(prices, func(a, b Price) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return prices
+}
+
+// changed reports whether the price moved at least the minimum change from the last price of the symbol sent.
+func (t *throttle) changed(price Price) bool {
+	last, ok := t.lastPrice[price.Symbol]
+	if !ok || !t.minChange.IsPositive() || last.IsZero() {
+		return true
+	}
+
+	change := price.Price.Sub(last).Abs().Div(last.Abs()).Mul(decimal.NewFromInt(100))
+
+	return change.GreaterThanOrEqual(t.minChange)
+}
+
+func (t *throttle) sent(price Price, now time.Time) {
+	t.last[price.Symbol] = now
+	t.lastPrice[price.Symbol] = price.Price
+
+	delete(t.pending, price.Symbol)
+}
+
+// drop forgets the prices held back for the given symbols, and the last ones sent.
+func (t *throttle) drop(symbols ...string) {
+	for _, symbol := range symbols {
+		delete(t.pending, symbol)
+		delete(t.lastPrice, symbol)
+	}
+}
+
+// setInterval changes the interval. Prices held back are sent on the next check.
+func (t *throttle) setInterval(interval time.Duration) {
+	t.interval = interval
+}
+
+// setMinChange changes the minimum move in percent. It applies from the next price received.
+func (t *throttle) setMinChange(minChangePct decimal.Decimal) {
+	t.minChange = minChangePct
+}
+
+// tick returns how often prices held back must be checked, or zero when none can be.
+func (t *throttle) tick() time.Duration {
+	if t.interval <= 0 {
+		return 0
+	}
+
+	return max(t.interval/4, 10*time.Millisecond)
+}
@@ -0,0 +1,38 @@
+package priceapp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle_Due_OrderedByID(t *testing.T) {
+	now := time.Now()
+
+	price := func(id uint64, symbol, value string) Price {
+		return Price{ID: id, Symbol: symbol, Price: decimal.RequireFromString(value)}
+	}
+
+	// map iteration is random, repeat to make an unordered result fail reliably
+	for range 20 {
+		th := newThrottle(time.Second, decimal.Zero)
+
+		require.True(t, th.allow(price(1, "SOL", "150"), now))
+		require.True(t, th.allow(price(2, "BTC", "50000"), now))
+
+		assert.False(t, th.allow(price(3, "BTC", "50100"), now.Add(100*time.Millisecond)))
+		assert.False(t, th.allow(price(4, "SOL", "151"), now.Add(200*time.Millisecond)))
+
+		assert.Empty(t, th.due(now.Add(500*time.Millisecond)))
+
+		due := th.due(now.Add(time.Second))
+		require.Len(t, due, 2)
+		assert.Equal(t, uint64(3), due[0].ID)
+		assert.Equal(t, uint64(4), due[1].ID)
+
+		assert.Empty(t, th.due(now.Add(2*time.Second)))
+	}
+}
@@ -0,0 +1,426 @@
+package priceapp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pubsub"
+	"github.com/gandarez/btc-price-service/internal/foundation/cache"
+	"github.com/gandarez/btc-price-service/internal/foundation/log"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+const (
+	// wsWriteTimeout is the time allowed to write a message to the client.
+	wsWriteTimeout = 10 * time.Second
+	// wsReadTimeout is the time allowed between two messages or pongs from the client.
+	wsReadTimeout = 60 * time.Second
+	// wsPingInterval is how often the connection is pinged, shorter than the read timeout.
+	wsPingInterval = wsReadTimeout * 9 / 10
+	// wsMaxMessageSize is the maximum size of a control message.
+	wsMaxMessageSize = 4096
+)
+
+// Control actions of WebSocket clients.
+const (
+	actionSubscribe   = "subscribe"
+	actionUnsubscribe = "unsubscribe"
+	actionPing        = "ping"
+	actionThrottle    = "throttle"
+)
+
+// Types of the replies to the control messages.
+const (
+	replySubscribed = "subscribed"
+	replyPong       = "pong"
+	replyThrottled  = "throttled"
+	replyError      = "error"
+)
+
+type (
+	// wsClient holds the state of a WebSocket connection. It is only used by the goroutine writing to it.
+	wsClient struct {
+		conn     *websocket.Conn
+		sub      *pubsub.Subscriber
+		numeric  bool              // encode prices as JSON numbers instead of strings
+		format   web.Format        // encoding of the events, control replies are always JSON
+		sent     map[string]uint64 // ID of the last price sent by symbol
+		throttle *throttle
+		candles  []string // intervals of the bars sent for the symbols subscribed
+	}
+)
+
+// priceWS streams the same events as the price stream over a WebSocket connection.
+// Clients change their subscriptions and throttle with JSON control messages.
+func (a *app) priceWS(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.Extract(ctx)
+
+	symbols, err := a.parseSymbols(r.URL.Query().Get("symbols"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	numeric, err := parsePriceFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conflation, err := parseThrottle(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format, err := web.NegotiateFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	overflow, err := a.parseOverflow(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	candles, err := a.parseCandles(r.URL.Query().Get("candles"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already replied with the error
+		logger.Errorf("failed to upgrade price websocket: %s", err)
+		return
+	}
+
+	defer conn.Close() // nolint:errcheck
+
+	topics := append(slices.Clone(symbols), candleTopics(symbols, candles)...)
+
+	sub, fill := a.subscribe(ctx, overflow, topics, symbols, replay{})
+	defer a.broadcaster.Unsubscribe(ctx, sub)
+
+	client := &wsClient{
+		conn:     conn,
+		sub:      sub,
+		numeric:  numeric,
+		format:   format,
+		sent:     make(map[string]uint64, len(symbols)),
+		throttle: conflation,
+		candles:  candles,
+	}
+
+	controls := make(chan []byte)
+	closed := make(chan struct{})
+	done := make(chan struct{})
+
+	defer close(done)
+
+	go readControls(conn, controls, closed, done)
+
+	logger.Infoln("client connected to price websocket")
+
+	if err := client.write(wsMessage{Type: replySubscribed, Data: wsSubscription{Symbols: client.symbols()}}); err != nil {
+		logger.Infof("client disconnected from price websocket (send failed): %s", err)
+		return
+	}
+
+	err = a.sendBackfill(fill, func(update cache.CacheableEntity) error {
+		return client.deliver(update, time.Now())
+	})
+	if err != nil {
+		logger.Infof("client disconnected from price websocket (send failed): %s", err)
+		return
+	}
+
+	fill.mark(client.sent, symbols)
+
+	pingTicker := time.NewTicker(wsPingInterval)
+	defer pingTicker.Stop()
+
+	// only ticks while prices may be held back by the throttle
+	var (
+		throttleTicker *time.Ticker
+		throttleTick   <-chan time.Time
+	)
+
+	if tick := client.throttle.tick(); tick > 0 {
+		throttleTicker = time.NewTicker(tick)
+		throttleTick = throttleTicker.C
+	}
+
+	defer func() {
+		if throttleTicker != nil {
+			throttleTicker.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// connections run with the context of the service, which is only done when shutting down
+			logger.Infoln("service shutting down, closing price websocket")
+
+			client.shutdown(a.retryHint(a.cfg.ShutdownRetry))
+
+			return
+		case <-closed:
+			logger.Infoln("client disconnected from price websocket")
+
+			return
+		case data := <-controls:
+			reply := a.control(client, data)
+
+			if reply.Type == replyThrottled {
+				if throttleTicker != nil {
+					throttleTicker.Stop()
+					throttleTicker, throttleTick = nil, nil
+				}
+
+				if tick := client.throttle.tick(); tick > 0 {
+					throttleTicker = time.NewTicker(tick)
+					throttleTick = throttleTicker.C
+				}
+			}
+
+			err = client.write(reply)
+			if err == nil && reply.Type == replyThrottled {
+				err = client.flush(time.Now())
+			}
+		case update, ok := <-sub.Ch:
+			if !ok {
+				// disconnected by the overflow policy, after the gap
+				logger.Infof("client disconnected from price websocket (too slow, dropped %d updates)", sub.Dropped())
+
+				return
+			}
+
+			err = client.deliver(update, time.Now())
+		case now := <-throttleTick:
+			err = client.flush(now)
+		case <-pingTicker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+		}
+
+		if err != nil {
+			logger.Infof("client disconnected from price websocket (send failed): %s", err)
+
+			return
+		}
+	}
+}
+
+// readControls reads the control messages of the client until the connection fails or stays silent for too long,
+// or done is closed. It closes the closed channel when it stops.
+func readControls(conn *websocket.Conn, controls chan<- []byte, closed chan<- struct{}, done <-chan struct{}) {
+	defer close(closed)
+
+	conn.SetReadLimit(wsMaxMessageSize)
+
+	extend := func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+	}
+
+	conn.SetPongHandler(extend)
+
+	for {
+		if err := extend(""); err != nil {
+			return
+		}
+
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		select {
+		case controls <- data:
+		case <-done:
+			return
+		}
+	}
+}
+
+// control applies a control message of the client and returns the reply.
+func (a *app) control(client *wsClient, data []byte) wsMessage {
+	var ctl wsControl
+	if err := json.Unmarshal(data, &ctl); err != nil {
+		return wsMessage{Type: replyError, Error: fmt.Sprintf("invalid control message: %s", err)}
+	}
+
+	switch ctl.Action {
+	case actionSubscribe, actionUnsubscribe:
+		// an empty list means every configured symbol, the same as in the connection url
+		symbols, err := a.parseSymbols(strings.Join(ctl.Symbols, ","))
+		if err != nil {
+			return wsMessage{Type: replyError, Error: err.Error()}
+		}
+
+		if ctl.Action == actionUnsubscribe {
+			client.sub.RemoveTopics(symbols...)
+			client.sub.RemoveTopics(candleTopics(symbols, client.candles)...)
+			client.throttle.drop(symbols...)
+
+			// subscribing again sends the last price again
+			for _, symbol := range symbols {
+				delete(client.sent, symbol)
+			}
+
+			return wsMessage{Type: replySubscribed, Data: wsSubscription{Symbols: client.symbols()}}
+		}
+
+		subscribed := client.symbols()
+
+		added := slices.DeleteFunc(slices.Clone(symbols), func(symbol string) bool {
+			return slices.Contains(subscribed, symbol)
+		})
+
+		// newly subscribed symbols get their last price, the same as on connection
+		a.subscribeTopics(client.sub, added)
+		client.sub.AddTopics(candleTopics(added, client.candles)...)
+
+		return wsMessage{Type: replySubscribed, Data: wsSubscription{Symbols: client.symbols()}}
+	case actionPing:
+		return wsMessage{Type: replyPong}
+	case actionThrottle:
+		if ctl.IntervalMS != nil && *ctl.IntervalMS < 0 {
+			return wsMessage{Type: replyError, Error: "throttle interval must not be negative"}
+		}
+
+		minChange := client.throttle.minChange
+
+		if ctl.MinChangePct != nil {
+			var err error
+
+			minChange, err = parseMinChange(ctl.MinChangePct.String())
+			if err != nil {
+				return wsMessage{Type: replyError, Error: err.Error()}
+			}
+		}
+
+		if ctl.IntervalMS != nil {
+			client.throttle.setInterval(time.Duration(*ctl.IntervalMS) * time.Millisecond)
+		}
+
+		client.throttle.setMinChange(minChange)
+
+		return wsMessage{Type: replyThrottled, Data: wsThrottle{
+			IntervalMS:   client.throttle.interval.Milliseconds(),
+			MinChangePct: client.throttle.minChange,
+		}}
+	default:
+		return wsMessage{Type: replyError, Error: fmt.Sprintf("unsupported action %q", ctl.Action)}
+	}
+}
+
+// deliver sends an update to the client. Prices already sent or held back by the throttle are skipped.
+func (c *wsClient) deliver(update cache.CacheableEntity, now time.Time) error {
+	if gap, ok := update.(pubsub.Gap); ok {
+		update = toAppDropGap(gap, lastSent(c.sent), c.symbols())
+	}
+
+	price, ok := update.(Price)
+	if !ok {
+		return c.send(wsMessage{Type: eventName(update), Data: update})
+	}
+
+	// unsubscribed while the price was queued
+	if !c.sub.Wants(price.Symbol) || price.ID <= c.sent[price.Symbol] {
+		return nil
+	}
+
+	if !c.throttle.allow(price, now) {
+		return nil
+	}
+
+	return c.writePrice(price)
+}
+
+// flush sends the prices held back by the throttle that are due.
+func (c *wsClient) flush(now time.Time) error {
+	for _, price := range c.throttle.due(now) {
+		if err := c.writePrice(price); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// symbols returns the symbols the client is subscribed to, sorted.
+func (c *wsClient) symbols() []string {
+	return slices.DeleteFunc(c.sub.Topics(), isCandleTopic)
+}
+
+func (c *wsClient) writePrice(price Price) error {
+	c.sent[price.Symbol] = price.ID
+
+	return c.send(wsMessage{Type: eventName(price), ID: price.ID, Data: encodable(price, c.format, c.numeric)})
+}
+
+// shutdown tells the client the service is shutting down and closes the connection.
+func (c *wsClient) shutdown(retry time.Duration) {
+	update := toAppShutdown(retry)
+
+	if err := c.send(wsMessage{Type: eventName(update), Data: update}); err != nil {
+		return
+	}
+
+	_ = c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, "service shutting down"),
+		time.Now().Add(wsWriteTimeout))
+}
+
+func (c *wsClient) write(msg wsMessage) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+
+	return c.conn.WriteJSON(msg)
+}
+
+// send sends an event in the format of the client. Binary formats are sent as binary messages, and events
+// without an encoding in the format are skipped.
+func (c *wsClient) send(msg wsMessage) error {
+	if c.format.Name == web.FormatJSON {
+		return c.write(msg)
+	}
+
+	data, err := c.format.Marshal(msg)
+	if errors.Is(err, web.ErrUnsupportedFormat) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	messageType := websocket.TextMessage
+	if c.format.Binary {
+		messageType = websocket.BinaryMessage
+	}
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+
+	return c.conn.WriteMessage(messageType, data)
+}
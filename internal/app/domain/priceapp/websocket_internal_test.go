@@ -0,0 +1,286 @@
+package priceapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pricepb"
+)
+
+func TestPriceWS(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+
+	publishPrices(t, a, "BTC", "50000")
+	publishPrices(t, a, "ETH", "3000")
+
+	conn := dialTestWS(t, a, "?symbols=BTC")
+
+	assert.JSONEq(t, `{"type":"subscribed","data":{"symbols":["BTC"]}}`, readTestWS(t, conn))
+	assert.Contains(t, readTestWS(t, conn), `{"type":"status","data":{"upstream":"closed","available":true`)
+	assert.Contains(t, readTestWS(t, conn), `"type":"price"`)
+
+	// the last price of newly subscribed symbols follows the reply
+	writeTestWS(t, conn, `{"action":"subscribe","symbols":["eth"]}`)
+	assert.JSONEq(t, `{"type":"subscribed","data":{"symbols":["BTC","ETH"]}}`, readTestWS(t, conn))
+	assert.Contains(t, readTestWS(t, conn), `"data":{"symbol":"ETH","timestamp":`)
+
+	writeTestWS(t, conn, `{"action":"unsubscribe","symbols":["BTC"]}`)
+	assert.JSONEq(t, `{"type":"subscribed","data":{"symbols":["ETH"]}}`, readTestWS(t, conn))
+
+	writeTestWS(t, conn, `{"action":"subscribe","symbols":["DOGE"]}`)
+	assert.JSONEq(t, `{"type":"error","error":"unsupported symbol \"DOGE\""}`, readTestWS(t, conn))
+
+	writeTestWS(t, conn, `{"action":"ping"}`)
+	assert.JSONEq(t, `{"type":"pong"}`, readTestWS(t, conn))
+
+	writeTestWS(t, conn, `{"action":"dance"}`)
+	assert.JSONEq(t, `{"type":"error","error":"unsupported action \"dance\""}`, readTestWS(t, conn))
+
+	writeTestWS(t, conn, `not json`)
+	assert.Contains(t, readTestWS(t, conn), `"error":"invalid control message:`)
+
+	publishPrices(t, a, "BTC", "50001")
+	publishPrices(t, a, "ETH", "3001")
+
+	msg := readTestWS(t, conn)
+	assert.Contains(t, msg, `"symbol":"ETH"`)
+	assert.Contains(t, msg, `"price":"3001"`)
+	assert.Contains(t, msg, `"id":`)
+}
+
+func TestPriceWS_Candles(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC", "ETH")
+	a.cfg.CandleIntervals = []time.Duration{time.Minute}
+	a.candles = newCandles(a.cfg.CandleIntervals, 10)
+
+	conn := dialTestWS(t, a, "?symbols=BTC&candles=1m")
+
+	assert.JSONEq(t, `{"type":"subscribed","data":{"symbols":["BTC"]}}`, readTestWS(t, conn))
+	assert.Contains(t, readTestWS(t, conn), `"type":"status"`)
+
+	publishPrices(t, a, "BTC", "50000")
+
+	assert.Contains(t, readTestWS(t, conn), `"type":"price"`)
+	assert.Contains(t, readTestWS(t, conn), `{"type":"candles","data":{"symbol":"BTC","candles":[{"symbol":"BTC",`)
+
+	// the bars follow the symbols subscribed
+	writeTestWS(t, conn, `{"action":"subscribe","symbols":["ETH"]}`)
+	assert.JSONEq(t, `{"type":"subscribed","data":{"symbols":["BTC","ETH"]}}`, readTestWS(t, conn))
+
+	writeTestWS(t, conn, `{"action":"unsubscribe","symbols":["BTC"]}`)
+	assert.JSONEq(t, `{"type":"subscribed","data":{"symbols":["ETH"]}}`, readTestWS(t, conn))
+
+	publishPrices(t, a, "BTC", "50001")
+	publishPrices(t, a, "ETH", "3000")
+
+	assert.Contains(t, readTestWS(t, conn), `"symbol":"ETH","timestamp":`)
+	assert.Contains(t, readTestWS(t, conn), `{"type":"candles","data":{"symbol":"ETH"`)
+}
+
+func TestPriceWS_Throttle(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	conn := dialTestWS(t, a, "?price_format=number")
+
+	readTestWS(t, conn) // subscribed
+	readTestWS(t, conn) // status
+
+	writeTestWS(t, conn, `{"action":"throttle","interval_ms":200}`)
+	assert.JSONEq(t, `{"type":"throttled","data":{"interval_ms":200,"min_change_pct":"0"}}`, readTestWS(t, conn))
+
+	publishPrices(t, a, "BTC", "50000", "50001", "50002")
+
+	// the first price goes through, and only the latest of the ones held back follows
+	assert.Contains(t, readTestWS(t, conn), `"price":50000,`)
+
+	start := time.Now()
+
+	assert.Contains(t, readTestWS(t, conn), `"price":50002,`)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestPriceWS_ThrottleMinChange(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	conn := dialTestWS(t, a, "?price_format=number&min_interval=1s")
+
+	readTestWS(t, conn) // subscribed
+	readTestWS(t, conn) // status
+
+	// the interval set in the url is kept when only the minimum change is sent
+	writeTestWS(t, conn, `{"action":"throttle","min_change_pct":"1"}`)
+	assert.JSONEq(t, `{"type":"throttled","data":{"interval_ms":1000,"min_change_pct":"1"}}`, readTestWS(t, conn))
+
+	writeTestWS(t, conn, `{"action":"throttle","interval_ms":0,"min_change_pct":0.5}`)
+	assert.JSONEq(t, `{"type":"throttled","data":{"interval_ms":0,"min_change_pct":"0.5"}}`, readTestWS(t, conn))
+
+	writeTestWS(t, conn, `{"action":"throttle","min_change_pct":"-1"}`)
+	assert.JSONEq(t,
+		`{"type":"error","error":"invalid 'min_change_pct' \"-1\", expected a positive percentage such as 0.05"}`,
+		readTestWS(t, conn),
+	)
+
+	publishPrices(t, a, "BTC", "50000", "50100", "50300")
+
+	// 50100 moved less than 0.5% from 50000
+	assert.Contains(t, readTestWS(t, conn), `"price":50000,`)
+	assert.Contains(t, readTestWS(t, conn), `"price":50300,`)
+}
+
+func TestPriceWS_Format(t *testing.T) {
+	a := newTestApp(&mockPriceBusiness{}, "BTC")
+
+	publishPrices(t, a, "BTC", "50000")
+
+	conn := dialTestWS(t, a, "?format=protobuf")
+
+	// control replies stay JSON
+	assert.JSONEq(t, `{"type":"subscribed","data":{"symbols":["BTC"]}}`, readTestWS(t, conn))
+
+	var event pricepb.StreamPricesResponse
+
+	require.NoError(t, proto.Unmarshal(readTestWSBinary(t, conn), &event))
+	assert.Equal(t, "closed", event.GetStatus().GetUpstream())
+
+	require.NoError(t, proto.Unmarshal(readTestWSBinary(t, conn), &event))
+	assert.Equal(t, "50000", event.GetPrice().GetPrice())
+	assert.Equal(t, a.lastID, event.GetPrice().GetId())
+
+	conn = dialTestWS(t, a, "?format=compact")
+
+	readTestWS(t, conn) // subscribed
+	assert.Contains(t, readTestWS(t, conn), `["status",0,["closed",true,`)
+	assert.True(t, strings.HasPrefix(readTestWS(t, conn), fmt.Sprintf(`["price",%d,[%d,"BTC",`, a.lastID, a.lastID)))
+}
+
+func TestThrottle(t *testing.T) {
+	now := time.Now()
+	th := newThrottle(time.Second, decimal.Zero)
+
+	btc := func(price string) Price {
+		return Price{Symbol: "BTC", Price: decimal.RequireFromString(price)}
+	}
+
+	assert.True(t, th.allow(btc("1"), now))
+	assert.False(t, th.allow(btc("2"), now.Add(100*time.Millisecond)))
+	assert.False(t, th.allow(btc("3"), now.Add(200*time.Millisecond)))
+	assert.True(t, th.allow(Price{Symbol: "ETH"}, now.Add(200*time.Millisecond)))
+
+	assert.Empty(t, th.due(now.Add(500*time.Millisecond)))
+
+	due := th.due(now.Add(time.Second))
+	require.Len(t, due, 1)
+	assert.Equal(t, "3", due[0].Price.String())
+	assert.Empty(t, th.due(now.Add(time.Second)))
+
+	assert.False(t, th.allow(btc("4"), now.Add(1500*time.Millisecond)))
+	th.drop("BTC")
+	assert.Empty(t, th.due(now.Add(3*time.Second)))
+
+	th.setInterval(0)
+	assert.True(t, th.allow(btc("5"), now.Add(3*time.Second)))
+	assert.Zero(t, th.tick())
+}
+
+func TestThrottle_MinChange(t *testing.T) {
+	now := time.Now()
+	th := newThrottle(0, decimal.RequireFromString("0.5"))
+
+	btc := func(price string) Price {
+		return Price{Symbol: "BTC", Price: decimal.RequireFromString(price)}
+	}
+
+	assert.True(t, th.allow(btc("100"), now))
+	assert.False(t, th.allow(btc("100.4"), now))
+	assert.True(t, th.allow(btc("100.5"), now))
+	assert.False(t, th.allow(btc("100.1"), now)) // moved 0.4% from the last price sent, not from the previous one
+	assert.True(t, th.allow(btc("99.9"), now))
+
+	// resubscribing sends the next price again
+	th.drop("BTC")
+	assert.True(t, th.allow(btc("99.9"), now))
+}
+
+func TestThrottle_MinChange_Interval(t *testing.T) {
+	now := time.Now()
+	th := newThrottle(time.Second, decimal.RequireFromString("1"))
+
+	btc := func(price string) Price {
+		return Price{Symbol: "BTC", Price: decimal.RequireFromString(price)}
+	}
+
+	assert.True(t, th.allow(btc("100"), now))
+	assert.False(t, th.allow(btc("102"), now.Add(100*time.Millisecond)))
+
+	// the latest price replaces the one held back, even when it did not move enough
+	assert.False(t, th.allow(btc("100.5"), now.Add(200*time.Millisecond)))
+	assert.Empty(t, th.due(now.Add(time.Second)))
+
+	assert.True(t, th.allow(btc("98"), now.Add(1100*time.Millisecond)))
+	assert.False(t, th.allow(btc("96"), now.Add(1200*time.Millisecond)))
+
+	due := th.due(now.Add(2100 * time.Millisecond))
+	require.Len(t, due, 1)
+	assert.Equal(t, "96", due[0].Price.String())
+}
+
+func dialTestWS(t *testing.T, a *app, query string) *websocket.Conn {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(a.priceWS))
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/price-ws" + query
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(t.Context(), url, nil)
+	require.NoError(t, err)
+
+	_ = resp.Body.Close()
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func readTestWS(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	var msg json.RawMessage
+
+	require.NoError(t, conn.ReadJSON(&msg))
+
+	return string(msg)
+}
+
+func readTestWSBinary(t *testing.T, conn *websocket.Conn) []byte {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	messageType, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, messageType)
+
+	return data
+}
+
+func writeTestWS(t *testing.T, conn *websocket.Conn, msg string) {
+	t.Helper()
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(msg)))
+}
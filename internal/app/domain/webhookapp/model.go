@@ -0,0 +1,160 @@
+package webhookapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus"
+)
+
+type (
+	// NewEndpoint represents the request to register a webhook endpoint.
+	NewEndpoint struct {
+		URL     string   `json:"url"`     // must be https
+		Secret  string   `json:"secret"`  // generated when empty
+		Events  []string `json:"events"`  // price, status, candles or alert, every event but candles when empty
+		Symbols []string `json:"symbols"` // every symbol when empty
+	}
+
+	// Endpoint represents a registered webhook endpoint.
+	Endpoint struct {
+		ID        string   `json:"id"`
+		URL       string   `json:"url"`
+		Events    []string `json:"events"`
+		Symbols   []string `json:"symbols"`
+		CreatedAt string   `json:"created_at"`
+	}
+
+	// Endpoints represents the registered webhook endpoints.
+	Endpoints struct {
+		Endpoints []Endpoint `json:"endpoints"`
+		Pending   int        `json:"pending"` // deliveries queued, retries included
+	}
+
+	// CreatedEndpoint represents an endpoint just registered, the only time its secret is returned.
+	CreatedEndpoint struct {
+		Endpoint
+		Secret string `json:"secret"`
+	}
+
+	// DeadLetter represents a delivery given up on after its maximum attempts.
+	DeadLetter struct {
+		ID         string          `json:"id"`
+		EndpointID string          `json:"endpoint_id,omitempty"` // omitted for the webhooks of alert rules
+		URL        string          `json:"url"`
+		Event      string          `json:"event"`
+		Body       json.RawMessage `json:"body"`
+		Attempts   int             `json:"attempts"`
+		LastError  string          `json:"last_error"`
+		CreatedAt  string          `json:"created_at"`
+		FailedAt   string          `json:"failed_at"`
+	}
+
+	// DeadLetters represents the deliveries given up on, oldest first.
+	DeadLetters struct {
+		DeadLetters []DeadLetter `json:"dead_letters"`
+	}
+
+	// Replayed represents the number of dead letters queued again.
+	Replayed struct {
+		Count int `json:"replayed"`
+	}
+)
+
+// Encode implements web.Encoder interface.
+func (e Endpoint) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(e)
+	return data, "application/json", err
+}
+
+// Encode implements web.Encoder interface.
+func (e Endpoints) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(e)
+	return data, "application/json", err
+}
+
+// Encode implements web.Encoder interface.
+func (c CreatedEndpoint) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(c)
+	return data, "application/json", err
+}
+
+// StatusCode implements web.StatusCoder interface.
+func (CreatedEndpoint) StatusCode() int {
+	return http.StatusCreated
+}
+
+// Encode implements web.Encoder interface.
+func (d DeadLetters) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(d)
+	return data, "application/json", err
+}
+
+// Encode implements web.Encoder interface.
+func (r Replayed) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// StatusCode implements web.StatusCoder interface. The replayed deliveries are posted in the background.
+func (Replayed) StatusCode() int {
+	return http.StatusAccepted
+}
+
+func toBusNewEndpoint(ne NewEndpoint) webhookbus.NewEndpoint {
+	return webhookbus.NewEndpoint{
+		URL:     ne.URL,
+		Secret:  ne.Secret,
+		Events:  ne.Events,
+		Symbols: ne.Symbols,
+	}
+}
+
+func toAppEndpoint(endpoint webhookbus.Endpoint) Endpoint {
+	events := endpoint.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	symbols := endpoint.Symbols
+	if symbols == nil {
+		symbols = []string{}
+	}
+
+	return Endpoint{
+		ID:        endpoint.ID,
+		URL:       endpoint.URL,
+		Events:    events,
+		Symbols:   symbols,
+		CreatedAt: endpoint.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toAppEndpoints(endpoints []webhookbus.Endpoint, pending int) Endpoints {
+	appEndpoints := make([]Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		appEndpoints = append(appEndpoints, toAppEndpoint(endpoint))
+	}
+
+	return Endpoints{Endpoints: appEndpoints, Pending: pending}
+}
+
+func toAppDeadLetters(deliveries []webhookbus.Delivery) DeadLetters {
+	deadLetters := make([]DeadLetter, 0, len(deliveries))
+	for _, d := range deliveries {
+		deadLetters = append(deadLetters, DeadLetter{
+			ID:         d.ID,
+			EndpointID: d.EndpointID,
+			URL:        d.URL,
+			Event:      d.Event,
+			Body:       d.Body,
+			Attempts:   d.Attempts,
+			LastError:  d.LastError,
+			CreatedAt:  d.CreatedAt.Format(time.RFC3339),
+			FailedAt:   d.FailedAt.Format(time.RFC3339),
+		})
+	}
+
+	return DeadLetters{DeadLetters: deadLetters}
+}
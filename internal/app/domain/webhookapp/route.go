@@ -0,0 +1,24 @@
+package webhookapp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/mux"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+// Routes sets up the HTTP routes for the webhook application.
+func Routes(ctx context.Context, app *web.App, cfg mux.Config) {
+	const version = "v1"
+
+	api := newApp(cfg.WebhookConfig.WebhookBus)
+
+	app.HandlerFunc(ctx, http.MethodGet, version, "/webhooks", api.list)
+	app.HandlerFunc(ctx, http.MethodPost, version, "/webhooks", api.create)
+	app.HandlerFunc(ctx, http.MethodGet, version, "/webhooks/{id}", api.get)
+	app.HandlerFunc(ctx, http.MethodDelete, version, "/webhooks/{id}", api.delete)
+	app.HandlerFunc(ctx, http.MethodGet, version, "/webhooks/dead-letters", api.deadLetters)
+	app.HandlerFunc(ctx, http.MethodPost, version, "/webhooks/dead-letters/replay", api.replayAll)
+	app.HandlerFunc(ctx, http.MethodPost, version, "/webhooks/dead-letters/{id}/replay", api.replay)
+}
@@ -0,0 +1,114 @@
+package webhookapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+// maxBodySize is the maximum size of the body of a request to register an endpoint.
+const maxBodySize = 64 << 10
+
+type (
+	app struct {
+		webhookBus WebhookBusiness
+	}
+
+	// WebhookBusiness defines the interface for managing webhook endpoints and their deliveries.
+	WebhookBusiness interface {
+		CreateEndpoint(ne webhookbus.NewEndpoint) (webhookbus.Endpoint, error)
+		Endpoints() []webhookbus.Endpoint
+		Endpoint(id string) (webhookbus.Endpoint, error)
+		DeleteEndpoint(ctx context.Context, id string) error
+		Pending() int
+		DeadLetters() []webhookbus.Delivery
+		Replay(ctx context.Context, id string) error
+		ReplayAll(ctx context.Context) int
+	}
+)
+
+func newApp(webhookBus WebhookBusiness) *app {
+	return &app{
+		webhookBus: webhookBus,
+	}
+}
+
+// list returns every endpoint, oldest first, and the number of queued deliveries.
+func (a *app) list(_ context.Context, _ *http.Request) web.Encoder {
+	return toAppEndpoints(a.webhookBus.Endpoints(), a.webhookBus.Pending())
+}
+
+// create registers an endpoint. Its secret is only returned in the response.
+func (a *app) create(_ context.Context, r *http.Request) web.Encoder {
+	var ne NewEndpoint
+
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&ne); err != nil {
+		return web.NewError(http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+
+	endpoint, err := a.webhookBus.CreateEndpoint(toBusNewEndpoint(ne))
+	if err != nil {
+		if errors.Is(err, webhookbus.ErrInvalidEndpoint) {
+			return web.NewError(http.StatusBadRequest, err)
+		}
+
+		return web.NewError(http.StatusInternalServerError, err)
+	}
+
+	return CreatedEndpoint{Endpoint: toAppEndpoint(endpoint), Secret: endpoint.Secret}
+}
+
+// get returns the endpoint with the ID of the path.
+func (a *app) get(_ context.Context, r *http.Request) web.Encoder {
+	endpoint, err := a.webhookBus.Endpoint(r.PathValue("id"))
+	if err != nil {
+		return toWebError(err)
+	}
+
+	return toAppEndpoint(endpoint)
+}
+
+// delete deletes the endpoint with the ID of the path along with its deliveries, replying with no content.
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	if err := a.webhookBus.DeleteEndpoint(ctx, r.PathValue("id")); err != nil {
+		return toWebError(err)
+	}
+
+	return nil
+}
+
+// deadLetters returns the deliveries given up on, oldest first.
+func (a *app) deadLetters(_ context.Context, _ *http.Request) web.Encoder {
+	return toAppDeadLetters(a.webhookBus.DeadLetters())
+}
+
+// replay queues the dead letter with the ID of the path again.
+func (a *app) replay(ctx context.Context, r *http.Request) web.Encoder {
+	if err := a.webhookBus.Replay(ctx, r.PathValue("id")); err != nil {
+		return toWebError(err)
+	}
+
+	return Replayed{Count: 1}
+}
+
+// replayAll queues every dead letter again.
+func (a *app) replayAll(ctx context.Context, _ *http.Request) web.Encoder {
+	return Replayed{Count: a.webhookBus.ReplayAll(ctx)}
+}
+
+func toWebError(err error) *web.Error {
+	if errors.Is(err, webhookbus.ErrNotFound) {
+		return web.NewError(http.StatusNotFound, err)
+	}
+
+	return web.NewError(http.StatusInternalServerError, err)
+}
@@ -0,0 +1,163 @@
+package webhookapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus"
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus/stores/webhookfile"
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+func TestEndpoints(t *testing.T) {
+	server, _ := newTestWebApp(t, &mockSender{})
+
+	w := serve(t, server, http.MethodPost, "/v1/webhooks",
+		`{"url":"https://example.com/hook","events":["price"],"symbols":["btc"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	id, _ := created["id"].(string)
+	require.NotEmpty(t, id)
+
+	assert.Equal(t, "https://example.com/hook", created["url"])
+	assert.Equal(t, []any{"price"}, created["events"])
+	assert.Equal(t, []any{"BTC"}, created["symbols"])
+	assert.Len(t, created["secret"], 64)
+
+	// the secret is only returned on creation
+	w = serve(t, server, http.MethodGet, "/v1/webhooks/"+id, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+
+	w = serve(t, server, http.MethodGet, "/v1/webhooks", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), `{"endpoints":[{"id":"`+id+`"`))
+	assert.True(t, strings.HasSuffix(w.Body.String(), `"pending":0}`))
+
+	w = serve(t, server, http.MethodDelete, "/v1/webhooks/"+id, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = serve(t, server, http.MethodDelete, "/v1/webhooks/"+id, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"webhook not found"}`, w.Body.String())
+}
+
+func TestCreate_Invalid(t *testing.T) {
+	server, _ := newTestWebApp(t, &mockSender{})
+
+	tests := map[string]struct {
+		Body     string
+		Expected string
+	}{
+		"unknown field": {
+			Body:     `{"url":"https://example.com","foo":1}`,
+			Expected: `{"error":"invalid request body: json: unknown field \"foo\""}`,
+		},
+		"not https": {
+			Body:     `{"url":"http://example.com"}`,
+			Expected: `{"error":"invalid webhook endpoint: url \"http://example.com\" must be an absolute https url"}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := serve(t, server, http.MethodPost, "/v1/webhooks", test.Body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, test.Expected, w.Body.String())
+		})
+	}
+}
+
+func TestDeadLetters(t *testing.T) {
+	server, bus := newTestWebApp(t, &mockSender{err: errors.New("bad gateway")})
+
+	require.NoError(t, bus.Send(t.Context(), "https://example.com/alert", "s3cr3t", []byte(`{"rule_id":"1"}`)))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go bus.Run(ctx)
+
+	require.Eventually(t, func() bool { return len(bus.DeadLetters()) == 1 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+
+	w := serve(t, server, http.MethodGet, "/v1/webhooks/dead-letters", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp DeadLetters
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.DeadLetters, 1)
+
+	dead := resp.DeadLetters[0]
+	assert.Equal(t, "https://example.com/alert", dead.URL)
+	assert.Equal(t, "alert", dead.Event)
+	assert.JSONEq(t, `{"rule_id":"1"}`, string(dead.Body))
+	assert.Equal(t, 1, dead.Attempts)
+	assert.Equal(t, "bad gateway", dead.LastError)
+	assert.NotContains(t, w.Body.String(), "s3cr3t")
+
+	w = serve(t, server, http.MethodPost, "/v1/webhooks/dead-letters/unknown/replay", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(t, server, http.MethodPost, "/v1/webhooks/dead-letters/"+dead.ID+"/replay", "")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.JSONEq(t, `{"replayed":1}`, w.Body.String())
+	assert.Equal(t, 1, bus.Pending())
+
+	w = serve(t, server, http.MethodPost, "/v1/webhooks/dead-letters/replay", "")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.JSONEq(t, `{"replayed":0}`, w.Body.String())
+}
+
+func newTestWebApp(t *testing.T, sender webhookbus.Sender) (*web.App, *webhookbus.Business) {
+	t.Helper()
+
+	bus, err := webhookbus.NewBusiness(sender, webhookfile.NewStore(filepath.Join(t.TempDir(), "webhooks.json")),
+		webhookbus.Config{MaxAttempts: 1})
+	require.NoError(t, err)
+
+	a := newApp(bus)
+
+	server := web.NewApp()
+	server.HandlerFunc(t.Context(), http.MethodGet, "v1", "/webhooks", a.list)
+	server.HandlerFunc(t.Context(), http.MethodPost, "v1", "/webhooks", a.create)
+	server.HandlerFunc(t.Context(), http.MethodGet, "v1", "/webhooks/{id}", a.get)
+	server.HandlerFunc(t.Context(), http.MethodDelete, "v1", "/webhooks/{id}", a.delete)
+	server.HandlerFunc(t.Context(), http.MethodGet, "v1", "/webhooks/dead-letters", a.deadLetters)
+	server.HandlerFunc(t.Context(), http.MethodPost, "v1", "/webhooks/dead-letters/replay", a.replayAll)
+	server.HandlerFunc(t.Context(), http.MethodPost, "v1", "/webhooks/dead-letters/{id}/replay", a.replay)
+
+	return server, bus
+}
+
+func serve(t *testing.T, server *web.App, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), method, path, strings.NewReader(body)))
+
+	return w
+}
+
+type mockSender struct {
+	err error
+}
+
+func (m *mockSender) Send(_ context.Context, _, _ string, _ []byte) error {
+	return m.err
+}
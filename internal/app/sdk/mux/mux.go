@@ -5,11 +5,32 @@ import (
 	"net/http"
 	"time"
 
+	"google.golang.org/grpc"
+
+	"github.com/gandarez/btc-price-service/internal/app/sdk/pubsub"
+	"github.com/gandarez/btc-price-service/internal/business/domain/alertbus"
 	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus"
 	"github.com/gandarez/btc-price-service/internal/foundation/web"
 )
 
 type (
+	// AdminConfig holds the configuration for the admin domain.
+	AdminConfig struct {
+		PriceBus *pricebus.Business
+		Token    string // bearer token required by the admin routes, empty disables them
+	}
+
+	// AlertConfig holds the configuration for the alert domain.
+	AlertConfig struct {
+		AlertBus *alertbus.Business
+	}
+
+	// CheckConfig holds the configuration for the check domain.
+	CheckConfig struct {
+		PriceBus *pricebus.Business
+	}
+
 	// PriceConfig holds the configuration for the price domain.
 	PriceConfig struct {
 		BufferTTL                 time.Duration
@@ -17,12 +38,32 @@ type (
 		DefaultExpirationInterval time.Duration
 		PollInterval              time.Duration
 		MaxPeersPerBroadcaster    int
+		Overflow                  pubsub.Options
+		Symbols                   []string
+		Streaming                 bool
+		Retry                     time.Duration
+		ShutdownRetry             time.Duration
+		RetryJitter               float64
+		CandleIntervals           []time.Duration
+		CandleMaxBars             int
 		PriceBus                  *pricebus.Business
+		AlertBus                  *alertbus.Business    // evaluates alert rules on each price when set
+		WebhookBus                *webhookbus.Business  // delivers the broadcast updates to webhooks when set
+		GRPC                      grpc.ServiceRegistrar // registers the gRPC price service when set
+	}
+
+	// WebhookConfig holds the configuration for the webhook domain.
+	WebhookConfig struct {
+		WebhookBus *webhookbus.Business
 	}
 
 	// Config holds the configuration for the mux.
 	Config struct {
-		PriceConfig PriceConfig
+		AdminConfig   AdminConfig
+		AlertConfig   AlertConfig
+		CheckConfig   CheckConfig
+		PriceConfig   PriceConfig
+		WebhookConfig WebhookConfig
 	}
 )
 
@@ -0,0 +1,1213 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: price/v1/price.proto
+
+package pricepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Price is the price of an asset in USD. Prices are exact decimals encoded as strings.
+type Price struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID increases with every price published, the same as the SSE event ID.
+	Id        uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Symbol    string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Price     string                 `protobuf:"bytes,4,opt,name=price,proto3" json:"price,omitempty"`
+	// Market fields are only set when the upstream source reports them.
+	MarketCap         *string `protobuf:"bytes,5,opt,name=market_cap,json=marketCap,proto3,oneof" json:"market_cap,omitempty"`
+	Volume_24H        *string `protobuf:"bytes,6,opt,name=volume_24h,json=volume24h,proto3,oneof" json:"volume_24h,omitempty"`
+	Change_24H        *string `protobuf:"bytes,7,opt,name=change_24h,json=change24h,proto3,oneof" json:"change_24h,omitempty"`
+	ChangePct_24H     *string `protobuf:"bytes,8,opt,name=change_pct_24h,json=changePct24h,proto3,oneof" json:"change_pct_24h,omitempty"`
+	CirculatingSupply *string `protobuf:"bytes,9,opt,name=circulating_supply,json=circulatingSupply,proto3,oneof" json:"circulating_supply,omitempty"`
+	// Sources lists the quotes that contributed to an aggregated price.
+	Sources []*SourceQuote `protobuf:"bytes,10,rep,name=sources,proto3" json:"sources,omitempty"`
+	// PrevPrice is the previous price of the symbol, unset for its first price. Change and ChangePct,
+	// rounded to 4 decimal places, are the changes since then.
+	PrevPrice *string `protobuf:"bytes,11,opt,name=prev_price,json=prevPrice,proto3,oneof" json:"prev_price,omitempty"`
+	Change    *string `protobuf:"bytes,12,opt,name=change,proto3,oneof" json:"change,omitempty"`
+	ChangePct *string `protobuf:"bytes,13,opt,name=change_pct,json=changePct,proto3,oneof" json:"change_pct,omitempty"`
+	// DayOpen is the first price of the UTC day, seeded from the cache or upstream when the service started during it.
+	// DayChange and DayChangePct are the changes since then.
+	DayOpen      *string `protobuf:"bytes,14,opt,name=day_open,json=dayOpen,proto3,oneof" json:"day_open,omitempty"`
+	DayChange    *string `protobuf:"bytes,15,opt,name=day_change,json=dayChange,proto3,oneof" json:"day_change,omitempty"`
+	DayChangePct *string `protobuf:"bytes,16,opt,name=day_change_pct,json=dayChangePct,proto3,oneof" json:"day_change_pct,omitempty"`
+	// Aggregation is the method the sources were aggregated with, median or vwap, empty for a single source.
+	Aggregation   string `protobuf:"bytes,17,opt,name=aggregation,proto3" json:"aggregation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Price) Reset() {
+	*x = Price{}
+	mi := &file_price_v1_price_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Price) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Price) ProtoMessage() {}
+
+func (x *Price) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Price.ProtoReflect.Descriptor instead.
+func (*Price) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Price) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Price) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Price) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Price) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Price) GetMarketCap() string {
+	if x != nil && x.MarketCap != nil {
+		return *x.MarketCap
+	}
+	return ""
+}
+
+func (x *Price) GetVolume_24H() string {
+	if x != nil && x.Volume_24H != nil {
+		return *x.Volume_24H
+	}
+	return ""
+}
+
+func (x *Price) GetChange_24H() string {
+	if x != nil && x.Change_24H != nil {
+		return *x.Change_24H
+	}
+	return ""
+}
+
+func (x *Price) GetChangePct_24H() string {
+	if x != nil && x.ChangePct_24H != nil {
+		return *x.ChangePct_24H
+	}
+	return ""
+}
+
+func (x *Price) GetCirculatingSupply() string {
+	if x != nil && x.CirculatingSupply != nil {
+		return *x.CirculatingSupply
+	}
+	return ""
+}
+
+func (x *Price) GetSources() []*SourceQuote {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *Price) GetPrevPrice() string {
+	if x != nil && x.PrevPrice != nil {
+		return *x.PrevPrice
+	}
+	return ""
+}
+
+func (x *Price) GetChange() string {
+	if x != nil && x.Change != nil {
+		return *x.Change
+	}
+	return ""
+}
+
+func (x *Price) GetChangePct() string {
+	if x != nil && x.ChangePct != nil {
+		return *x.ChangePct
+	}
+	return ""
+}
+
+func (x *Price) GetDayOpen() string {
+	if x != nil && x.DayOpen != nil {
+		return *x.DayOpen
+	}
+	return ""
+}
+
+func (x *Price) GetDayChange() string {
+	if x != nil && x.DayChange != nil {
+		return *x.DayChange
+	}
+	return ""
+}
+
+func (x *Price) GetDayChangePct() string {
+	if x != nil && x.DayChangePct != nil {
+		return *x.DayChangePct
+	}
+	return ""
+}
+
+func (x *Price) GetAggregation() string {
+	if x != nil {
+		return x.Aggregation
+	}
+	return ""
+}
+
+// SourceQuote is the quote of a single source.
+type SourceQuote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Price         string                 `protobuf:"bytes,3,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SourceQuote) Reset() {
+	*x = SourceQuote{}
+	mi := &file_price_v1_price_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceQuote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceQuote) ProtoMessage() {}
+
+func (x *SourceQuote) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceQuote.ProtoReflect.Descriptor instead.
+func (*SourceQuote) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SourceQuote) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SourceQuote) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *SourceQuote) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+// Status is the availability of the upstream price source.
+type Status struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Upstream is the circuit breaker state: closed, open or half-open.
+	Upstream      string                 `protobuf:"bytes,1,opt,name=upstream,proto3" json:"upstream,omitempty"`
+	Available     bool                   `protobuf:"varint,2,opt,name=available,proto3" json:"available,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_price_v1_price_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Status) GetUpstream() string {
+	if x != nil {
+		return x.Upstream
+	}
+	return ""
+}
+
+func (x *Status) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *Status) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// Gap tells a resuming client that some of the prices it missed are no longer cached, or a slow client
+// that updates sent after last_event_id were dropped.
+type Gap struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	LastEventId uint64                 `protobuf:"varint,1,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	Symbols     []string               `protobuf:"bytes,2,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	Timestamp   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Missed is the number of updates dropped, zero when resuming.
+	Missed uint64 `protobuf:"varint,4,opt,name=missed,proto3" json:"missed,omitempty"`
+	// Disconnected is set on the last event before a slow client is disconnected.
+	Disconnected  bool `protobuf:"varint,5,opt,name=disconnected,proto3" json:"disconnected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Gap) Reset() {
+	*x = Gap{}
+	mi := &file_price_v1_price_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Gap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Gap) ProtoMessage() {}
+
+func (x *Gap) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Gap.ProtoReflect.Descriptor instead.
+func (*Gap) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Gap) GetLastEventId() uint64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+func (x *Gap) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *Gap) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Gap) GetMissed() uint64 {
+	if x != nil {
+		return x.Missed
+	}
+	return 0
+}
+
+func (x *Gap) GetDisconnected() bool {
+	if x != nil {
+		return x.Disconnected
+	}
+	return false
+}
+
+// Candle is an OHLC bar of the prices of a symbol within an interval.
+type Candle struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Interval is the duration of the bar, e.g. 1m.
+	Interval string                 `protobuf:"bytes,1,opt,name=interval,proto3" json:"interval,omitempty"`
+	OpenTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=open_time,json=openTime,proto3" json:"open_time,omitempty"`
+	// CloseTime is exclusive.
+	CloseTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=close_time,json=closeTime,proto3" json:"close_time,omitempty"`
+	Open      string                 `protobuf:"bytes,4,opt,name=open,proto3" json:"open,omitempty"`
+	High      string                 `protobuf:"bytes,5,opt,name=high,proto3" json:"high,omitempty"`
+	Low       string                 `protobuf:"bytes,6,opt,name=low,proto3" json:"low,omitempty"`
+	Close     string                 `protobuf:"bytes,7,opt,name=close,proto3" json:"close,omitempty"`
+	// Ticks is the number of prices received in the bar, changed or not.
+	Ticks int64 `protobuf:"varint,8,opt,name=ticks,proto3" json:"ticks,omitempty"`
+	// Closed is set once the bar is completed.
+	Closed        bool `protobuf:"varint,9,opt,name=closed,proto3" json:"closed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candle) Reset() {
+	*x = Candle{}
+	mi := &file_price_v1_price_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candle) ProtoMessage() {}
+
+func (x *Candle) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candle.ProtoReflect.Descriptor instead.
+func (*Candle) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Candle) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *Candle) GetOpenTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OpenTime
+	}
+	return nil
+}
+
+func (x *Candle) GetCloseTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CloseTime
+	}
+	return nil
+}
+
+func (x *Candle) GetOpen() string {
+	if x != nil {
+		return x.Open
+	}
+	return ""
+}
+
+func (x *Candle) GetHigh() string {
+	if x != nil {
+		return x.High
+	}
+	return ""
+}
+
+func (x *Candle) GetLow() string {
+	if x != nil {
+		return x.Low
+	}
+	return ""
+}
+
+func (x *Candle) GetClose() string {
+	if x != nil {
+		return x.Close
+	}
+	return ""
+}
+
+func (x *Candle) GetTicks() int64 {
+	if x != nil {
+		return x.Ticks
+	}
+	return 0
+}
+
+func (x *Candle) GetClosed() bool {
+	if x != nil {
+		return x.Closed
+	}
+	return false
+}
+
+// Candles is a bar of a symbol updated by a price, or completed at the end of its interval.
+type Candles struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Candles       []*Candle              `protobuf:"bytes,2,rep,name=candles,proto3" json:"candles,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candles) Reset() {
+	*x = Candles{}
+	mi := &file_price_v1_price_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candles) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candles) ProtoMessage() {}
+
+func (x *Candles) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candles.ProtoReflect.Descriptor instead.
+func (*Candles) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Candles) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Candles) GetCandles() []*Candle {
+	if x != nil {
+		return x.Candles
+	}
+	return nil
+}
+
+func (x *Candles) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// Alert tells the subscribers of an alert rule that it fired.
+type Alert struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	RuleId string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Symbol string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// Kind is the kind of the rule: crosses, move or stale.
+	Kind string `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Price is the price that fired the rule, the last one received for stale.
+	Price         string                 `protobuf:"bytes,4,opt,name=price,proto3" json:"price,omitempty"`
+	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_price_v1_price_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Alert) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *Alert) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Alert) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Alert) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Alert) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Alert) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// Shutdown tells the client the service is shutting down, and when to reconnect.
+type Shutdown struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RetryMs       int64                  `protobuf:"varint,1,opt,name=retry_ms,json=retryMs,proto3" json:"retry_ms,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Shutdown) Reset() {
+	*x = Shutdown{}
+	mi := &file_price_v1_price_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Shutdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Shutdown) ProtoMessage() {}
+
+func (x *Shutdown) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Shutdown.ProtoReflect.Descriptor instead.
+func (*Shutdown) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Shutdown) GetRetryMs() int64 {
+	if x != nil {
+		return x.RetryMs
+	}
+	return 0
+}
+
+func (x *Shutdown) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type StreamPricesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Symbols to stream, all configured symbols when empty.
+	Symbols []string `protobuf:"bytes,1,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	// LastEventID resumes the stream after the price with this ID, replaying the ones missed.
+	LastEventId *uint64 `protobuf:"varint,2,opt,name=last_event_id,json=lastEventId,proto3,oneof" json:"last_event_id,omitempty"`
+	// Candles are the intervals of the bars to stream, e.g. 1m, none when empty.
+	Candles       []string `protobuf:"bytes,3,rep,name=candles,proto3" json:"candles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamPricesRequest) Reset() {
+	*x = StreamPricesRequest{}
+	mi := &file_price_v1_price_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamPricesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamPricesRequest) ProtoMessage() {}
+
+func (x *StreamPricesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamPricesRequest.ProtoReflect.Descriptor instead.
+func (*StreamPricesRequest) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamPricesRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *StreamPricesRequest) GetLastEventId() uint64 {
+	if x != nil && x.LastEventId != nil {
+		return *x.LastEventId
+	}
+	return 0
+}
+
+func (x *StreamPricesRequest) GetCandles() []string {
+	if x != nil {
+		return x.Candles
+	}
+	return nil
+}
+
+type StreamPricesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*StreamPricesResponse_Price
+	//	*StreamPricesResponse_Status
+	//	*StreamPricesResponse_Gap
+	//	*StreamPricesResponse_Shutdown
+	//	*StreamPricesResponse_Candles
+	//	*StreamPricesResponse_Alert
+	Event         isStreamPricesResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamPricesResponse) Reset() {
+	*x = StreamPricesResponse{}
+	mi := &file_price_v1_price_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamPricesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamPricesResponse) ProtoMessage() {}
+
+func (x *StreamPricesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamPricesResponse.ProtoReflect.Descriptor instead.
+func (*StreamPricesResponse) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamPricesResponse) GetEvent() isStreamPricesResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *StreamPricesResponse) GetPrice() *Price {
+	if x != nil {
+		if x, ok := x.Event.(*StreamPricesResponse_Price); ok {
+			return x.Price
+		}
+	}
+	return nil
+}
+
+func (x *StreamPricesResponse) GetStatus() *Status {
+	if x != nil {
+		if x, ok := x.Event.(*StreamPricesResponse_Status); ok {
+			return x.Status
+		}
+	}
+	return nil
+}
+
+func (x *StreamPricesResponse) GetGap() *Gap {
+	if x != nil {
+		if x, ok := x.Event.(*StreamPricesResponse_Gap); ok {
+			return x.Gap
+		}
+	}
+	return nil
+}
+
+func (x *StreamPricesResponse) GetShutdown() *Shutdown {
+	if x != nil {
+		if x, ok := x.Event.(*StreamPricesResponse_Shutdown); ok {
+			return x.Shutdown
+		}
+	}
+	return nil
+}
+
+func (x *StreamPricesResponse) GetCandles() *Candles {
+	if x != nil {
+		if x, ok := x.Event.(*StreamPricesResponse_Candles); ok {
+			return x.Candles
+		}
+	}
+	return nil
+}
+
+func (x *StreamPricesResponse) GetAlert() *Alert {
+	if x != nil {
+		if x, ok := x.Event.(*StreamPricesResponse_Alert); ok {
+			return x.Alert
+		}
+	}
+	return nil
+}
+
+type isStreamPricesResponse_Event interface {
+	isStreamPricesResponse_Event()
+}
+
+type StreamPricesResponse_Price struct {
+	Price *Price `protobuf:"bytes,1,opt,name=price,proto3,oneof"`
+}
+
+type StreamPricesResponse_Status struct {
+	Status *Status `protobuf:"bytes,2,opt,name=status,proto3,oneof"`
+}
+
+type StreamPricesResponse_Gap struct {
+	Gap *Gap `protobuf:"bytes,3,opt,name=gap,proto3,oneof"`
+}
+
+type StreamPricesResponse_Shutdown struct {
+	Shutdown *Shutdown `protobuf:"bytes,4,opt,name=shutdown,proto3,oneof"`
+}
+
+type StreamPricesResponse_Candles struct {
+	Candles *Candles `protobuf:"bytes,5,opt,name=candles,proto3,oneof"`
+}
+
+type StreamPricesResponse_Alert struct {
+	Alert *Alert `protobuf:"bytes,6,opt,name=alert,proto3,oneof"`
+}
+
+func (*StreamPricesResponse_Price) isStreamPricesResponse_Event() {}
+
+func (*StreamPricesResponse_Status) isStreamPricesResponse_Event() {}
+
+func (*StreamPricesResponse_Gap) isStreamPricesResponse_Event() {}
+
+func (*StreamPricesResponse_Shutdown) isStreamPricesResponse_Event() {}
+
+func (*StreamPricesResponse_Candles) isStreamPricesResponse_Event() {}
+
+func (*StreamPricesResponse_Alert) isStreamPricesResponse_Event() {}
+
+type GetLatestPriceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Defaults to the first configured symbol.
+	Symbol        string `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestPriceRequest) Reset() {
+	*x = GetLatestPriceRequest{}
+	mi := &file_price_v1_price_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestPriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestPriceRequest) ProtoMessage() {}
+
+func (x *GetLatestPriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestPriceRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestPriceRequest) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetLatestPriceRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+type GetLatestPriceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         *Price                 `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestPriceResponse) Reset() {
+	*x = GetLatestPriceResponse{}
+	mi := &file_price_v1_price_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestPriceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestPriceResponse) ProtoMessage() {}
+
+func (x *GetLatestPriceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_price_v1_price_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestPriceResponse.ProtoReflect.Descriptor instead.
+func (*GetLatestPriceResponse) Descriptor() ([]byte, []int) {
+	return file_price_v1_price_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetLatestPriceResponse) GetPrice() *Price {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+var File_price_v1_price_proto protoreflect.FileDescriptor
+
+const file_price_v1_price_proto_rawDesc = "" +
+	"\n" +
+	"\x14price/v1/price.proto\x12\bprice.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa0\x06\n" +
+	"\x05Price\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\tR\x05price\x12\"\n" +
+	"\n" +
+	"market_cap\x18\x05 \x01(\tH\x00R\tmarketCap\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"volume_24h\x18\x06 \x01(\tH\x01R\tvolume24h\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"change_24h\x18\a \x01(\tH\x02R\tchange24h\x88\x01\x01\x12)\n" +
+	"\x0echange_pct_24h\x18\b \x01(\tH\x03R\fchangePct24h\x88\x01\x01\x122\n" +
+	"\x12circulating_supply\x18\t \x01(\tH\x04R\x11circulatingSupply\x88\x01\x01\x12/\n" +
+	"\asources\x18\n" +
+	" \x03(\v2\x15.price.v1.SourceQuoteR\asources\x12\"\n" +
+	"\n" +
+	"prev_price\x18\v \x01(\tH\x05R\tprevPrice\x88\x01\x01\x12\x1b\n" +
+	"\x06change\x18\f \x01(\tH\x06R\x06change\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"change_pct\x18\r \x01(\tH\aR\tchangePct\x88\x01\x01\x12\x1e\n" +
+	"\bday_open\x18\x0e \x01(\tH\bR\adayOpen\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"day_change\x18\x0f \x01(\tH\tR\tdayChange\x88\x01\x01\x12)\n" +
+	"\x0eday_change_pct\x18\x10 \x01(\tH\n" +
+	"R\fdayChangePct\x88\x01\x01\x12 \n" +
+	"\vaggregation\x18\x11 \x01(\tR\vaggregationB\r\n" +
+	"\v_market_capB\r\n" +
+	"\v_volume_24hB\r\n" +
+	"\v_change_24hB\x11\n" +
+	"\x0f_change_pct_24hB\x15\n" +
+	"\x13_circulating_supplyB\r\n" +
+	"\v_prev_priceB\t\n" +
+	"\a_changeB\r\n" +
+	"\v_change_pctB\v\n" +
+	"\t_day_openB\r\n" +
+	"\v_day_changeB\x11\n" +
+	"\x0f_day_change_pct\"u\n" +
+	"\vSourceQuote\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\tR\x05price\"|\n" +
+	"\x06Status\x12\x1a\n" +
+	"\bupstream\x18\x01 \x01(\tR\bupstream\x12\x1c\n" +
+	"\tavailable\x18\x02 \x01(\bR\tavailable\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xb9\x01\n" +
+	"\x03Gap\x12\"\n" +
+	"\rlast_event_id\x18\x01 \x01(\x04R\vlastEventId\x12\x18\n" +
+	"\asymbols\x18\x02 \x03(\tR\asymbols\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x16\n" +
+	"\x06missed\x18\x04 \x01(\x04R\x06missed\x12\"\n" +
+	"\fdisconnected\x18\x05 \x01(\bR\fdisconnected\"\x96\x02\n" +
+	"\x06Candle\x12\x1a\n" +
+	"\binterval\x18\x01 \x01(\tR\binterval\x127\n" +
+	"\topen_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bopenTime\x129\n" +
+	"\n" +
+	"close_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcloseTime\x12\x12\n" +
+	"\x04open\x18\x04 \x01(\tR\x04open\x12\x12\n" +
+	"\x04high\x18\x05 \x01(\tR\x04high\x12\x10\n" +
+	"\x03low\x18\x06 \x01(\tR\x03low\x12\x14\n" +
+	"\x05close\x18\a \x01(\tR\x05close\x12\x14\n" +
+	"\x05ticks\x18\b \x01(\x03R\x05ticks\x12\x16\n" +
+	"\x06closed\x18\t \x01(\bR\x06closed\"\x87\x01\n" +
+	"\aCandles\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12*\n" +
+	"\acandles\x18\x02 \x03(\v2\x10.price.v1.CandleR\acandles\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xb6\x01\n" +
+	"\x05Alert\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\tR\x05price\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"_\n" +
+	"\bShutdown\x12\x19\n" +
+	"\bretry_ms\x18\x01 \x01(\x03R\aretryMs\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\x84\x01\n" +
+	"\x13StreamPricesRequest\x12\x18\n" +
+	"\asymbols\x18\x01 \x03(\tR\asymbols\x12'\n" +
+	"\rlast_event_id\x18\x02 \x01(\x04H\x00R\vlastEventId\x88\x01\x01\x12\x18\n" +
+	"\acandles\x18\x03 \x03(\tR\acandlesB\x10\n" +
+	"\x0e_last_event_id\"\xa1\x02\n" +
+	"\x14StreamPricesResponse\x12'\n" +
+	"\x05price\x18\x01 \x01(\v2\x0f.price.v1.PriceH\x00R\x05price\x12*\n" +
+	"\x06status\x18\x02 \x01(\v2\x10.price.v1.StatusH\x00R\x06status\x12!\n" +
+	"\x03gap\x18\x03 \x01(\v2\r.price.v1.GapH\x00R\x03gap\x120\n" +
+	"\bshutdown\x18\x04 \x01(\v2\x12.price.v1.ShutdownH\x00R\bshutdown\x12-\n" +
+	"\acandles\x18\x05 \x01(\v2\x11.price.v1.CandlesH\x00R\acandles\x12'\n" +
+	"\x05alert\x18\x06 \x01(\v2\x0f.price.v1.AlertH\x00R\x05alertB\a\n" +
+	"\x05event\"/\n" +
+	"\x15GetLatestPriceRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\"?\n" +
+	"\x16GetLatestPriceResponse\x12%\n" +
+	"\x05price\x18\x01 \x01(\v2\x0f.price.v1.PriceR\x05price2\xb4\x01\n" +
+	"\fPriceService\x12O\n" +
+	"\fStreamPrices\x12\x1d.price.v1.StreamPricesRequest\x1a\x1e.price.v1.StreamPricesResponse0\x01\x12S\n" +
+	"\x0eGetLatestPrice\x12\x1f.price.v1.GetLatestPriceRequest\x1a .price.v1.GetLatestPriceResponseBHZFgithub.com/gandarez/btc-price-service/internal/app/sdk/pricepb;pricepbb\x06proto3"
+
+var (
+	file_price_v1_price_proto_rawDescOnce sync.Once
+	file_price_v1_price_proto_rawDescData []byte
+)
+
+func file_price_v1_price_proto_rawDescGZIP() []byte {
+	file_price_v1_price_proto_rawDescOnce.Do(func() {
+		file_price_v1_price_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_price_v1_price_proto_rawDesc), len(file_price_v1_price_proto_rawDesc)))
+	})
+	return file_price_v1_price_proto_rawDescData
+}
+
+var file_price_v1_price_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_price_v1_price_proto_goTypes = []any{
+	(*Price)(nil),                  // 0: price.v1.Price
+	(*SourceQuote)(nil),            // 1: price.v1.SourceQuote
+	(*Status)(nil),                 // 2: price.v1.Status
+	(*Gap)(nil),                    // 3: price.v1.Gap
+	(*Candle)(nil),                 // 4: price.v1.Candle
+	(*Candles)(nil),                // 5: price.v1.Candles
+	(*Alert)(nil),                  // 6: price.v1.Alert
+	(*Shutdown)(nil),               // 7: price.v1.Shutdown
+	(*StreamPricesRequest)(nil),    // 8: price.v1.StreamPricesRequest
+	(*StreamPricesResponse)(nil),   // 9: price.v1.StreamPricesResponse
+	(*GetLatestPriceRequest)(nil),  // 10: price.v1.GetLatestPriceRequest
+	(*GetLatestPriceResponse)(nil), // 11: price.v1.GetLatestPriceResponse
+	(*timestamppb.Timestamp)(nil),  // 12: google.protobuf.Timestamp
+}
+var file_price_v1_price_proto_depIdxs = []int32{
+	12, // 0: price.v1.Price.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 1: price.v1.Price.sources:type_name -> price.v1.SourceQuote
+	12, // 2: price.v1.SourceQuote.timestamp:type_name -> google.protobuf.Timestamp
+	12, // 3: price.v1.Status.timestamp:type_name -> google.protobuf.Timestamp
+	12, // 4: price.v1.Gap.timestamp:type_name -> google.protobuf.Timestamp
+	12, // 5: price.v1.Candle.open_time:type_name -> google.protobuf.Timestamp
+	12, // 6: price.v1.Candle.close_time:type_name -> google.protobuf.Timestamp
+	4,  // 7: price.v1.Candles.candles:type_name -> price.v1.Candle
+	12, // 8: price.v1.Candles.timestamp:type_name -> google.protobuf.Timestamp
+	12, // 9: price.v1.Alert.timestamp:type_name -> google.protobuf.Timestamp
+	12, // 10: price.v1.Shutdown.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 11: price.v1.StreamPricesResponse.price:type_name -> price.v1.Price
+	2,  // 12: price.v1.StreamPricesResponse.status:type_name -> price.v1.Status
+	3,  // 13: price.v1.StreamPricesResponse.gap:type_name -> price.v1.Gap
+	7,  // 14: price.v1.StreamPricesResponse.shutdown:type_name -> price.v1.Shutdown
+	5,  // 15: price.v1.StreamPricesResponse.candles:type_name -> price.v1.Candles
+	6,  // 16: price.v1.StreamPricesResponse.alert:type_name -> price.v1.Alert
+	0,  // 17: price.v1.GetLatestPriceResponse.price:type_name -> price.v1.Price
+	8,  // 18: price.v1.PriceService.StreamPrices:input_type -> price.v1.StreamPricesRequest
+	10, // 19: price.v1.PriceService.GetLatestPrice:input_type -> price.v1.GetLatestPriceRequest
+	9,  // 20: price.v1.PriceService.StreamPrices:output_type -> price.v1.StreamPricesResponse
+	11, // 21: price.v1.PriceService.GetLatestPrice:output_type -> price.v1.GetLatestPriceResponse
+	20, // [20:22] is the sub-list for method output_type
+	18, // [18:20] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_price_v1_price_proto_init() }
+func file_price_v1_price_proto_init() {
+	if File_price_v1_price_proto != nil {
+		return
+	}
+	file_price_v1_price_proto_msgTypes[0].OneofWrappers = []any{}
+	file_price_v1_price_proto_msgTypes[8].OneofWrappers = []any{}
+	file_price_v1_price_proto_msgTypes[9].OneofWrappers = []any{
+		(*StreamPricesResponse_Price)(nil),
+		(*StreamPricesResponse_Status)(nil),
+		(*StreamPricesResponse_Gap)(nil),
+		(*StreamPricesResponse_Shutdown)(nil),
+		(*StreamPricesResponse_Candles)(nil),
+		(*StreamPricesResponse_Alert)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_price_v1_price_proto_rawDesc), len(file_price_v1_price_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_price_v1_price_proto_goTypes,
+		DependencyIndexes: file_price_v1_price_proto_depIdxs,
+		MessageInfos:      file_price_v1_price_proto_msgTypes,
+	}.Build()
+	File_price_v1_price_proto = out.File
+	file_price_v1_price_proto_goTypes = nil
+	file_price_v1_price_proto_depIdxs = nil
+}
@@ -24,7 +24,14 @@ func NewManager(maxPeersPerBroadcaster int) *Manager {
 }
 
 // Subscribe adds a new subscriber to the most appropriate broadcaster.
-func (m *Manager) Subscribe(ctx context.Context) *Subscriber {
+// The subscriber receives the updates for the given topics, every one with AllTopics, and those without a topic.
+func (m *Manager) Subscribe(ctx context.Context, topics ...string) *Subscriber {
+	return m.SubscribeWith(ctx, Options{}, topics...)
+}
+
+// SubscribeWith adds a new subscriber whose queue is configured by opts to the most appropriate broadcaster.
+// The subscriber receives the updates for the given topics, every one with AllTopics, and those without a topic.
+func (m *Manager) SubscribeWith(ctx context.Context, opts Options, topics ...string) *Subscriber {
 	logger := log.Extract(ctx)
 
 	m.mu.Lock()
@@ -33,7 +40,7 @@ func (m *Manager) Subscribe(ctx context.Context) *Subscriber {
 		if len(b.subscribers) < m.maxPeersPerBroadcaster {
 			logger.Infof("reusing broadcaster %s with %d subscribers", b.id, len(b.subscribers))
 
-			sub := b.Subscribe()
+			sub := b.SubscribeWith(opts, topics...)
 
 			m.mu.Unlock()
 
@@ -51,7 +58,7 @@ func (m *Manager) Subscribe(ctx context.Context) *Subscriber {
 
 	logger.Infof("created new broadcaster %s", broadcaster.id)
 
-	sub := broadcaster.Subscribe()
+	sub := broadcaster.SubscribeWith(opts, topics...)
 
 	m.redistributeSubscribers(ctx)
 
@@ -66,6 +73,10 @@ func (m *Manager) Unsubscribe(ctx context.Context, sub *Subscriber) {
 	logger := log.Extract(ctx)
 	logger.Infof("unsubscribing from broadcaster %s", sub.broadcasterID)
 
+	if dropped := sub.Dropped(); dropped > 0 {
+		logger.Warnf("subscriber of broadcaster %s dropped %d updates", sub.broadcasterID, dropped)
+	}
+
 	m.pool[sub.broadcasterID].Unsubscribe(sub)
 
 	// Clean up broadcaster if no subscribers left
@@ -78,8 +89,8 @@ func (m *Manager) Unsubscribe(ctx context.Context, sub *Subscriber) {
 	m.redistributeSubscribers(ctx)
 }
 
-// Broadcast sends an update to all subscribers across all broadcasters.
-func (m *Manager) Broadcast(update cache.CacheableEntity) {
+// Broadcast sends an update to all subscribers of the given topic across all broadcasters.
+func (m *Manager) Broadcast(topic string, update cache.CacheableEntity) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -91,7 +102,7 @@ func (m *Manager) Broadcast(update cache.CacheableEntity) {
 		go func(b *Broadcaster) {
 			defer wg.Done()
 
-			b.Broadcast(update)
+			b.Broadcast(topic, update)
 		}(b)
 	}
 
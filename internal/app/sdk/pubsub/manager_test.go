@@ -20,7 +20,7 @@ func TestManager_DataRace(t *testing.T) {
 	// Start broadcasting updates
 	go func() {
 		for {
-			m.Broadcast(mockEntity{UpdatedAt: time.Now().UTC()})
+			m.Broadcast("BTC", mockEntity{UpdatedAt: time.Now().UTC()})
 
 			time.Sleep(50 * time.Millisecond)
 		}
@@ -135,15 +135,15 @@ func TestManager_Unsubscribe(t *testing.T) {
 func TestManager_Broadcast(t *testing.T) {
 	m := pubsub.NewManager(1)
 
-	sub1 := m.Subscribe(t.Context())
+	sub1 := m.Subscribe(t.Context(), pubsub.AllTopics)
 	require.NotNil(t, sub1)
 
-	sub2 := m.Subscribe(t.Context())
+	sub2 := m.Subscribe(t.Context(), pubsub.AllTopics)
 	require.NotNil(t, sub2)
 
 	now := time.Now().UTC()
 
-	m.Broadcast(mockEntity{UpdatedAt: now})
+	m.Broadcast("BTC", mockEntity{UpdatedAt: now})
 
 	select {
 	case received := <-sub1.Ch:
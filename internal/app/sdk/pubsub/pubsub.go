@@ -1,19 +1,76 @@
 package pubsub
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/gandarez/btc-price-service/internal/foundation/cache"
 )
 
+// Overflow policies of the subscribers that read their updates slower than they are broadcast.
+const (
+	// PolicyDropOldest drops the oldest queued update to make room for the new one.
+	PolicyDropOldest Policy = "drop-oldest"
+	// PolicyKeepLatest conflates the queued updates to the latest one of each topic and type,
+	// and drops the oldest update only when they are all distinct.
+	PolicyKeepLatest Policy = "keep-latest"
+	// PolicyDisconnect drops the new update, and disconnects the subscriber after MaxDrops drops.
+	PolicyDisconnect Policy = "disconnect"
+)
+
+// defaultBufferSize is the number of updates queued for a subscriber when not configured.
+const defaultBufferSize = 100
+
+// AllTopics is the topic of the subscribers interested in the updates of every topic.
+const AllTopics = "*"
+
+// ErrInvalidPolicy is returned when parsing an unknown overflow policy.
+var ErrInvalidPolicy = errors.New("invalid overflow policy")
+
 type (
+	// Policy decides which updates are dropped when the queue of a subscriber is full.
+	Policy string
+
+	// Options configures the queue of a subscriber. The zero value queues 100 updates and drops the oldest.
+	Options struct {
+		Policy     Policy
+		BufferSize int // updates queued before the policy applies
+		MaxDrops   int // drops before a subscriber with the disconnect policy is disconnected, at least 1
+	}
+
+	// Gap is delivered to a subscriber before the next update when updates were dropped from its queue.
+	// When the subscriber was disconnected, it is the last update before Ch is closed.
+	Gap struct {
+		Missed       uint64 // updates dropped since the previous gap
+		Disconnected bool
+		UpdatedAt    time.Time
+	}
+
 	// Subscriber represents a client that subscribes to updates.
 	Subscriber struct {
-		broadcasterID string // ID of the broadcaster this subscriber belongs to
-		Ch            chan cache.CacheableEntity
-		Done          chan struct{} // signal to remove subscriber
+		broadcasterID string              // ID of the broadcaster this subscriber belongs to
+		topics        map[string]struct{} // topics the subscriber is interested in, AllTopics for all
+		topicsMu      sync.RWMutex
+		opts          Options
+		queue         []queued            // updates waiting to be received from Ch, oldest first
+		latest        map[queueKey]uint64 // sequence of the latest queued update by topic and type
+		seq           uint64              // sequence of the last queued update
+		missed        uint64              // updates dropped since the last gap
+		dropped       uint64              // updates dropped since subscribing
+		disconnected  bool
+		queueMu       sync.Mutex
+		wake          chan struct{} // signals the pump that the queue changed
+		stop          chan struct{} // closed when unsubscribed
+		stopOnce      sync.Once
+		Ch            chan cache.CacheableEntity // closed once unsubscribed or disconnected
+		Done          chan struct{}              // signal to remove subscriber
 	}
 
 	// Broadcaster is responsible for managing subscribers and broadcasting updates.
@@ -22,6 +79,18 @@ type (
 		subscribers map[*Subscriber]struct{}
 		mu          sync.RWMutex
 	}
+
+	queued struct {
+		key    queueKey
+		seq    uint64
+		update cache.CacheableEntity
+	}
+
+	// queueKey identifies the updates conflated by the keep-latest policy.
+	queueKey struct {
+		topic string
+		kind  reflect.Type
+	}
 )
 
 // NewBroadcaster creates a new Broadcaster instance.
@@ -32,16 +101,118 @@ func NewBroadcaster() *Broadcaster {
 	}
 }
 
+// ParsePolicy parses an overflow policy: drop-oldest, keep-latest or disconnect.
+func ParsePolicy(value string) (Policy, error) {
+	switch p := Policy(strings.ToLower(strings.TrimSpace(value))); p {
+	case PolicyDropOldest, PolicyKeepLatest, PolicyDisconnect:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w %q, expected drop-oldest, keep-latest or disconnect", ErrInvalidPolicy, value)
+	}
+}
+
+// Timestamp returns the time when the gap was delivered.
+func (g Gap) Timestamp() time.Time {
+	return g.UpdatedAt
+}
+
 // BroadcasterID returns the unique identifier of the broadcaster.
 func (s *Subscriber) BroadcasterID() string {
 	return s.broadcasterID
 }
 
+// Wants reports whether the subscriber is interested in updates for the given topic.
+// Updates without a topic are delivered to every subscriber.
+func (s *Subscriber) Wants(topic string) bool {
+	s.topicsMu.RLock()
+	defer s.topicsMu.RUnlock()
+
+	if topic == "" {
+		return true
+	}
+
+	_, all := s.topics[AllTopics]
+	_, ok := s.topics[topic]
+
+	return all || ok
+}
+
+// AddTopics adds topics to the subscriber. It is safe to call while updates are being broadcast.
+func (s *Subscriber) AddTopics(topics ...string) {
+	s.topicsMu.Lock()
+	defer s.topicsMu.Unlock()
+
+	for _, topic := range topics {
+		s.topics[topic] = struct{}{}
+	}
+}
+
+// RemoveTopics removes topics from the subscriber. Removing every topic leaves the subscriber with updates
+// without a topic only. It is safe to call while updates are being broadcast.
+func (s *Subscriber) RemoveTopics(topics ...string) {
+	s.topicsMu.Lock()
+	defer s.topicsMu.Unlock()
+
+	for _, topic := range topics {
+		delete(s.topics, topic)
+	}
+}
+
+// Topics returns the topics the subscriber is interested in, sorted.
+func (s *Subscriber) Topics() []string {
+	s.topicsMu.RLock()
+	defer s.topicsMu.RUnlock()
+
+	topics := make([]string, 0, len(s.topics))
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+
+	slices.Sort(topics)
+
+	return topics
+}
+
+// Dropped returns the number of updates dropped from the queue of the subscriber since it subscribed.
+func (s *Subscriber) Dropped() uint64 {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	return s.dropped
+}
+
 // Subscribe adds a new subscriber to the broadcaster and returns a channel to receive updates.
-func (b *Broadcaster) Subscribe() *Subscriber {
+// The subscriber receives the updates for the given topics, every one with AllTopics, and those without a topic.
+func (b *Broadcaster) Subscribe(topics ...string) *Subscriber {
+	return b.SubscribeWith(Options{}, topics...)
+}
+
+// SubscribeWith adds a new subscriber whose queue is configured by opts.
+// The subscriber receives the updates for the given topics, every one with AllTopics, and those without a topic.
+func (b *Broadcaster) SubscribeWith(opts Options, topics ...string) *Subscriber {
+	set := make(map[string]struct{}, len(topics))
+	for _, topic := range topics {
+		set[topic] = struct{}{}
+	}
+
+	if opts.Policy == "" {
+		opts.Policy = PolicyDropOldest
+	}
+
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultBufferSize
+	}
+
+	opts.MaxDrops = max(opts.MaxDrops, 1)
+
 	sub := &Subscriber{
 		broadcasterID: b.id,
-		Ch:            make(chan cache.CacheableEntity, 100), // buffered channel to avoid blocking
+		topics:        set,
+		opts:          opts,
+		latest:        make(map[queueKey]uint64),
+		wake:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		Ch:            make(chan cache.CacheableEntity),
 		Done:          make(chan struct{}),
 	}
 
@@ -49,39 +220,49 @@ func (b *Broadcaster) Subscribe() *Subscriber {
 	b.subscribers[sub] = struct{}{}
 	b.mu.Unlock()
 
+	go sub.pump()
+
 	go func() {
-		<-sub.Done
-		b.Unsubscribe(sub)
+		select {
+		case <-sub.Done:
+			b.Unsubscribe(sub)
+		case <-sub.stop:
+		}
 	}()
 
 	return sub
 }
 
-// Unsubscribe removes a subscriber from the broadcaster.
+// Unsubscribe removes a subscriber from the broadcaster. Its channel is closed once the update being
+// received, if any, is abandoned.
 func (b *Broadcaster) Unsubscribe(sub *Subscriber) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	delete(b.subscribers, sub)
-	close(sub.Ch)
+
+	sub.stopOnce.Do(func() {
+		close(sub.stop)
+	})
 }
 
-// Broadcast sends an update to all subscribers.
-func (b *Broadcaster) Broadcast(update cache.CacheableEntity) {
+// Broadcast sends an update to all subscribers interested in the given topic.
+func (b *Broadcaster) Broadcast(topic string, update cache.CacheableEntity) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
 	for sub := range b.subscribers {
-		b.SendOne(sub, update)
+		if !sub.Wants(topic) {
+			continue
+		}
+
+		sub.enqueue(topic, update)
 	}
 }
 
 // SendOne sends an update to a specific subscriber.
 func (*Broadcaster) SendOne(sub *Subscriber, update cache.CacheableEntity) {
-	select {
-	case sub.Ch <- update:
-	default: // skip slow clients
-	}
+	sub.enqueue("", update)
 }
 
 // ID returns the unique identifier of the broadcaster.
@@ -96,3 +277,147 @@ func (b *Broadcaster) Len() int {
 
 	return len(b.subscribers)
 }
+
+// enqueue queues the update, applying the overflow policy when the queue is full.
+func (s *Subscriber) enqueue(topic string, update cache.CacheableEntity) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	if s.disconnected {
+		return
+	}
+
+	s.seq++
+	item := queued{key: queueKey{topic: topic, kind: reflect.TypeOf(update)}, seq: s.seq, update: update}
+
+	if len(s.queue) >= s.opts.BufferSize {
+		switch s.opts.Policy {
+		case PolicyKeepLatest:
+			s.conflate(item)
+		case PolicyDisconnect:
+			s.drop(1)
+
+			// the queued updates are never received either
+			if s.dropped >= uint64(s.opts.MaxDrops) {
+				s.drop(len(s.queue))
+				s.queue = nil
+				clear(s.latest)
+				s.disconnected = true
+				s.signal()
+			}
+
+			return
+		}
+	}
+
+	// still full when every queued update was distinct, or with the drop-oldest policy
+	if len(s.queue) >= s.opts.BufferSize {
+		s.pop()
+		s.drop(1)
+	}
+
+	s.queue = append(s.queue, item)
+	s.latest[item.key] = item.seq
+	s.signal()
+}
+
+// pop removes the oldest queued update and returns it. It must be called with queueMu held.
+func (s *Subscriber) pop() cache.CacheableEntity {
+	oldest := s.queue[0]
+
+	if s.latest[oldest.key] == oldest.seq {
+		delete(s.latest, oldest.key)
+	}
+
+	s.queue[0] = queued{}
+	s.queue = s.queue[1:]
+
+	return oldest.update
+}
+
+// conflate removes the queued updates replaced by a later one of the same topic and type, including the new one.
+// It must be called with queueMu held.
+func (s *Subscriber) conflate(item queued) {
+	s.latest[item.key] = item.seq
+
+	kept := s.queue[:0]
+
+	for _, q := range s.queue {
+		if s.latest[q.key] == q.seq {
+			kept = append(kept, q)
+		}
+	}
+
+	s.drop(len(s.queue) - len(kept))
+
+	// clear the references to the conflated updates left past the end
+	clear(s.queue[len(kept):])
+
+	s.queue = kept
+}
+
+// drop counts dropped updates. It must be called with queueMu held.
+func (s *Subscriber) drop(n int) {
+	s.missed += uint64(n)
+	s.dropped += uint64(n)
+}
+
+// signal wakes the pump up. It must be called with queueMu held.
+func (s *Subscriber) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default: // already signaled
+	}
+}
+
+// pump delivers the queued updates to Ch until unsubscribed or disconnected, then closes it.
+func (s *Subscriber) pump() {
+	defer close(s.Ch)
+
+	for {
+		update, ok := s.next()
+		if !ok {
+			return
+		}
+
+		select {
+		case s.Ch <- update:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// next waits for the next update to deliver: a gap when updates were dropped, or else the oldest queued one.
+// It reports false once unsubscribed, or disconnected and the gap delivered.
+func (s *Subscriber) next() (cache.CacheableEntity, bool) {
+	for {
+		s.queueMu.Lock()
+
+		switch {
+		case s.missed > 0:
+			gap := Gap{Missed: s.missed, Disconnected: s.disconnected, UpdatedAt: time.Now().UTC()}
+			s.missed = 0
+			s.queueMu.Unlock()
+
+			return gap, true
+		case len(s.queue) > 0:
+			update := s.pop()
+			s.queueMu.Unlock()
+
+			return update, true
+		case s.disconnected:
+			s.queueMu.Unlock()
+
+			return nil, false
+		}
+
+		s.queueMu.Unlock()
+
+		select {
+		case <-s.wake:
+		case <-s.stop:
+			return nil, false
+		}
+	}
+}
@@ -1,23 +1,26 @@
 package pubsub_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/gandarez/btc-price-service/internal/app/sdk/pubsub"
+	"github.com/gandarez/btc-price-service/internal/foundation/cache"
 )
 
 func TestBroadcaster(t *testing.T) {
 	b := pubsub.NewBroadcaster()
 
-	sub := b.Subscribe()
+	sub := b.Subscribe(pubsub.AllTopics)
 	defer close(sub.Done)
 
 	now := time.Now().UTC()
 
-	b.Broadcast(mockEntity{UpdatedAt: now})
+	b.Broadcast("BTC", mockEntity{UpdatedAt: now})
 
 	// Test receiving the message
 	select {
@@ -31,9 +34,9 @@ func TestBroadcaster(t *testing.T) {
 func TestBroadcaster_Unsubscribe(t *testing.T) {
 	b := pubsub.NewBroadcaster()
 
-	sub := b.Subscribe()
+	sub := b.Subscribe(pubsub.AllTopics)
 
-	b.Broadcast(mockEntity{UpdatedAt: time.Now().UTC()})
+	b.Broadcast("BTC", mockEntity{UpdatedAt: time.Now().UTC()})
 
 	// Test receiving the message
 	select {
@@ -45,7 +48,192 @@ func TestBroadcaster_Unsubscribe(t *testing.T) {
 	}
 }
 
+func TestBroadcaster_Topics(t *testing.T) {
+	b := pubsub.NewBroadcaster()
+
+	btc := b.Subscribe("BTC")
+	defer close(btc.Done)
+
+	all := b.Subscribe(pubsub.AllTopics)
+	defer close(all.Done)
+
+	none := b.Subscribe()
+	defer close(none.Done)
+
+	now := time.Now().UTC()
+
+	b.Broadcast("ETH", mockEntity{UpdatedAt: now})
+
+	select {
+	case received := <-all.Ch:
+		assert.Equal(t, now, received.Timestamp())
+	case <-time.After(100 * time.Millisecond):
+		t.Error("timeout waiting for message")
+	}
+
+	assert.Empty(t, btc.Ch)
+	assert.Empty(t, none.Ch)
+
+	assert.True(t, btc.Wants("BTC"))
+	assert.True(t, btc.Wants(""))
+	assert.False(t, btc.Wants("ETH"))
+	assert.True(t, all.Wants("ETH"))
+	assert.True(t, all.Wants("alert:1"))
+	assert.False(t, none.Wants("ETH"))
+	assert.True(t, none.Wants(""))
+}
+
+func TestSubscriber_Topics(t *testing.T) {
+	b := pubsub.NewBroadcaster()
+
+	sub := b.Subscribe("BTC")
+	defer close(sub.Done)
+
+	sub.AddTopics("ETH", "SOL")
+	sub.RemoveTopics("BTC")
+
+	assert.Equal(t, []string{"ETH", "SOL"}, sub.Topics())
+	assert.False(t, sub.Wants("BTC"))
+	assert.True(t, sub.Wants("ETH"))
+
+	sub.RemoveTopics("ETH", "SOL")
+
+	assert.Empty(t, sub.Topics())
+	assert.NotNil(t, sub.Topics())
+	assert.False(t, sub.Wants("ETH"))
+	assert.True(t, sub.Wants(""))
+
+	all := b.Subscribe(pubsub.AllTopics)
+	defer close(all.Done)
+
+	all.AddTopics("BTC")
+	all.RemoveTopics("BTC")
+
+	assert.Equal(t, []string{pubsub.AllTopics}, all.Topics())
+	assert.True(t, all.Wants("ETH"))
+
+	all.RemoveTopics(pubsub.AllTopics)
+
+	assert.False(t, all.Wants("ETH"))
+}
+
+func TestSubscriber_DropOldest(t *testing.T) {
+	b := pubsub.NewBroadcaster()
+
+	sub := b.SubscribeWith(pubsub.Options{BufferSize: 2}, pubsub.AllTopics)
+	defer close(sub.Done)
+
+	for i := range 5 {
+		b.Broadcast("BTC", mockEntity{ID: fmt.Sprint(i + 1)})
+	}
+
+	received := receiveAll(t, sub)
+
+	// the update taken by the subscriber before its queue was full, if any, then the gap and the latest updates
+	ids, gaps := splitGaps(received)
+	require.Len(t, gaps, 1)
+	assert.Equal(t, []string{"4", "5"}, ids[len(ids)-2:])
+	assert.Equal(t, uint64(5-len(ids)), gaps[0].Missed)
+	assert.False(t, gaps[0].Disconnected)
+	assert.Equal(t, gaps[0].Missed, sub.Dropped())
+	assert.IsType(t, pubsub.Gap{}, received[len(received)-3])
+}
+
+func TestSubscriber_KeepLatest(t *testing.T) {
+	b := pubsub.NewBroadcaster()
+
+	sub := b.SubscribeWith(pubsub.Options{Policy: pubsub.PolicyKeepLatest, BufferSize: 2}, pubsub.AllTopics)
+	defer close(sub.Done)
+
+	b.Broadcast("BTC", mockEntity{ID: "BTC 1"})
+	b.Broadcast("ETH", mockEntity{ID: "ETH 1"})
+	b.Broadcast("BTC", mockEntity{ID: "BTC 2"})
+	b.Broadcast("ETH", mockEntity{ID: "ETH 2"})
+	b.Broadcast("BTC", mockEntity{ID: "BTC 3"})
+
+	ids, gaps := splitGaps(receiveAll(t, sub))
+
+	// the queue is conflated to the latest update of each topic
+	require.Len(t, gaps, 1)
+	assert.Equal(t, []string{"ETH 2", "BTC 3"}, ids[len(ids)-2:])
+	assert.Equal(t, uint64(5-len(ids)), sub.Dropped())
+}
+
+func TestSubscriber_Disconnect(t *testing.T) {
+	b := pubsub.NewBroadcaster()
+
+	sub := b.SubscribeWith(pubsub.Options{Policy: pubsub.PolicyDisconnect, BufferSize: 1, MaxDrops: 2}, pubsub.AllTopics)
+	defer close(sub.Done)
+
+	for i := range 5 {
+		b.Broadcast("BTC", mockEntity{ID: fmt.Sprint(i + 1)})
+	}
+
+	received := receiveAll(t, sub)
+
+	// the gap is the last update before the channel is closed
+	gap, ok := received[len(received)-1].(pubsub.Gap)
+	require.True(t, ok)
+	assert.True(t, gap.Disconnected)
+
+	// the updates queued when disconnected are dropped too, and the ones broadcast after are ignored
+	assert.Equal(t, gap.Missed, sub.Dropped())
+	assert.GreaterOrEqual(t, sub.Dropped(), uint64(3))
+
+	_, open := <-sub.Ch
+	assert.False(t, open)
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := pubsub.ParsePolicy(" Keep-Latest")
+	require.NoError(t, err)
+	assert.Equal(t, pubsub.PolicyKeepLatest, policy)
+
+	_, err = pubsub.ParsePolicy("drop-newest")
+	require.ErrorIs(t, err, pubsub.ErrInvalidPolicy)
+	assert.EqualError(t, err, `invalid overflow policy "drop-newest", expected drop-oldest, keep-latest or disconnect`)
+}
+
+// receiveAll receives the updates of the subscriber until its channel is closed or none is received for a while.
+func receiveAll(t *testing.T, sub *pubsub.Subscriber) []cache.CacheableEntity {
+	t.Helper()
+
+	var received []cache.CacheableEntity
+
+	for {
+		select {
+		case update, ok := <-sub.Ch:
+			if !ok {
+				return received
+			}
+
+			received = append(received, update)
+		case <-time.After(100 * time.Millisecond):
+			return received
+		}
+	}
+}
+
+func splitGaps(received []cache.CacheableEntity) ([]string, []pubsub.Gap) {
+	var (
+		ids  []string
+		gaps []pubsub.Gap
+	)
+
+	for _, update := range received {
+		switch u := update.(type) {
+		case pubsub.Gap:
+			gaps = append(gaps, u)
+		case mockEntity:
+			ids = append(ids, u.ID)
+		}
+	}
+
+	return ids, gaps
+}
+
 type mockEntity struct {
+	ID        string
 	UpdatedAt time.Time
 }
 
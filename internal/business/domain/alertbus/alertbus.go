@@ -0,0 +1,411 @@
+// Package alertbus evaluates price alert rules.
+package alertbus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/webhookclient"
+	"github.com/gandarez/btc-price-service/internal/foundation/log"
+)
+
+var (
+	// ErrNotFound is returned when a rule does not exist.
+	ErrNotFound = errors.New("alert rule not found")
+	// ErrInvalidRule is returned when a rule to create is invalid.
+	ErrInvalidRule = errors.New("invalid alert rule")
+)
+
+type (
+	// Business represents the business logic for the alert domain.
+	Business struct {
+		notifier Notifier
+		rules    map[string]*ruleState // keyed by ID
+		lastSeen map[string]lastPrice  // last price received by symbol
+		mu       sync.Mutex
+	}
+
+	// Notifier defines the interface for delivering alerts to the webhook of their rule.
+	Notifier interface {
+		Send(ctx context.Context, url, secret string, body []byte) error
+	}
+
+	// ruleState holds a rule and what it remembers of the previous prices.
+	ruleState struct {
+		Rule
+		last    decimal.NullDecimal // last price, for crosses
+		samples []sample            // prices within the window, oldest first, for move
+		fired   bool                // whether the stale rule fired since the last price
+	}
+
+	sample struct {
+		price decimal.Decimal
+		ts    time.Time
+	}
+
+	lastPrice struct {
+		price decimal.Decimal
+		ts    time.Time
+	}
+
+	// webhookPayload is the body of the webhook of an alert.
+	webhookPayload struct {
+		RuleID    string          `json:"rule_id"`
+		Symbol    string          `json:"symbol"`
+		Kind      string          `json:"kind"`
+		Price     decimal.Decimal `json:"price"`
+		Message   string          `json:"message"`
+		Timestamp string          `json:"timestamp"`
+	}
+)
+
+// NewBusiness creates a new instance of the Business struct.
+// Alerts of rules with a webhook are posted with notifier, which may be nil to disable webhooks.
+func NewBusiness(notifier Notifier) *Business {
+	return &Business{
+		notifier: notifier,
+		rules:    make(map[string]*ruleState),
+		lastSeen: make(map[string]lastPrice),
+	}
+}
+
+// Create validates and adds a new rule.
+func (b *Business) Create(nr NewRule) (Rule, error) {
+	if err := nr.validate(); err != nil {
+		return Rule{}, fmt.Errorf("%w: %s", ErrInvalidRule, err)
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return Rule{}, fmt.Errorf("failed to generate rule id: %w", err)
+	}
+
+	secret := nr.WebhookSecret
+	if nr.WebhookURL != "" && secret == "" {
+		if secret, err = randomHex(32); err != nil {
+			return Rule{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+	}
+
+	rule := Rule{
+		ID:            id,
+		Symbol:        strings.ToUpper(strings.TrimSpace(nr.Symbol)),
+		Kind:          nr.Kind,
+		Threshold:     nr.Threshold,
+		Window:        nr.Window,
+		WebhookURL:    nr.WebhookURL,
+		WebhookSecret: secret,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rules[id] = &ruleState{Rule: rule}
+
+	return rule, nil
+}
+
+// Rules returns every rule, oldest first.
+func (b *Business) Rules() []Rule {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rules := make([]Rule, 0, len(b.rules))
+	for _, r := range b.sortedRules() {
+		rules = append(rules, r.Rule)
+	}
+
+	return rules
+}
+
+// Rule returns the rule with the given ID, or ErrNotFound.
+func (b *Business) Rule(id string) (Rule, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r, ok := b.rules[id]
+	if !ok {
+		return Rule{}, ErrNotFound
+	}
+
+	return r.Rule, nil
+}
+
+// Delete removes the rule with the given ID, or returns ErrNotFound.
+func (b *Business) Delete(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.rules[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(b.rules, id)
+
+	return nil
+}
+
+// Evaluate evaluates the rules of the symbol of the price, and returns the alerts that fired.
+// Alerts of rules with a webhook are also posted to it in the background.
+func (b *Business) Evaluate(ctx context.Context, price pricebus.Price) []Alert {
+	ts, err := time.Parse(time.RFC3339, price.Timestamp)
+	if err != nil {
+		ts = time.Now().UTC()
+	}
+
+	b.mu.Lock()
+
+	// stale rules fire again once a newer price was received
+	if last, ok := b.lastSeen[price.Symbol]; !ok || ts.After(last.ts) {
+		b.lastSeen[price.Symbol] = lastPrice{price: price.Price, ts: ts}
+
+		for _, r := range b.rules {
+			if r.Symbol == price.Symbol && r.Kind == KindStale {
+				r.fired = false
+			}
+		}
+	}
+
+	var alerts []Alert
+
+	for _, r := range b.sortedRules() {
+		if r.Symbol != price.Symbol {
+			continue
+		}
+
+		var alert Alert
+
+		switch r.Kind {
+		case KindCrosses:
+			alert = r.crosses(price.Price, ts)
+		case KindMove:
+			alert = r.move(price.Price, ts)
+		}
+
+		if alert.RuleID != "" {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	b.mu.Unlock()
+
+	b.notify(ctx, alerts)
+
+	return alerts
+}
+
+// CheckStale evaluates the stale rules, and returns the alerts that fired.
+// A rule fires once until a newer price of its symbol is received.
+func (b *Business) CheckStale(ctx context.Context, now time.Time) []Alert {
+	b.mu.Lock()
+
+	var alerts []Alert
+
+	for _, r := range b.sortedRules() {
+		if r.Kind != KindStale || r.fired {
+			continue
+		}
+
+		// a symbol without prices yet is stale since the rule was created
+		last, ok := b.lastSeen[r.Symbol]
+		if !ok {
+			last.ts = r.CreatedAt
+		}
+
+		if now.Sub(last.ts) < r.Window {
+			continue
+		}
+
+		r.fired = true
+
+		alerts = append(alerts, Alert{
+			RuleID:    r.ID,
+			Symbol:    r.Symbol,
+			Kind:      r.Kind,
+			Price:     last.price,
+			Message:   fmt.Sprintf("%s price stale for %s", r.Symbol, r.Window),
+			Timestamp: now.UTC(),
+		})
+	}
+
+	b.mu.Unlock()
+
+	b.notify(ctx, alerts)
+
+	return alerts
+}
+
+// sortedRules returns the rules ordered by creation, so alerts fire in a stable order.
+// It must be called with mu held.
+func (b *Business) sortedRules() []*ruleState {
+	rules := make([]*ruleState, 0, len(b.rules))
+	for _, r := range b.rules {
+		rules = append(rules, r)
+	}
+
+	slices.SortFunc(rules, func(x, y *ruleState) int {
+		if c := x.CreatedAt.Compare(y.CreatedAt); c != 0 {
+			return c
+		}
+
+		return strings.Compare(x.ID, y.ID)
+	})
+
+	return rules
+}
+
+// notify posts the alerts of rules with a webhook in the background.
+func (b *Business) notify(ctx context.Context, alerts []Alert) {
+	if b.notifier == nil {
+		return
+	}
+
+	for _, alert := range alerts {
+		rule, err := b.Rule(alert.RuleID)
+		if err != nil || rule.WebhookURL == "" {
+			continue
+		}
+
+		body, err := json.Marshal(webhookPayload{
+			RuleID:    alert.RuleID,
+			Symbol:    alert.Symbol,
+			Kind:      alert.Kind,
+			Price:     alert.Price,
+			Message:   alert.Message,
+			Timestamp: alert.Timestamp.Format(time.RFC3339),
+		})
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			if err := b.notifier.Send(ctx, rule.WebhookURL, rule.WebhookSecret, body); err != nil {
+				log.Extract(ctx).Warnf("failed to post alert of rule %s to webhook: %s", rule.ID, err)
+			}
+		}()
+	}
+}
+
+// crosses fires when the price crossed the threshold since the last price.
+func (r *ruleState) crosses(price decimal.Decimal, ts time.Time) Alert {
+	last := r.last
+	r.last = decimal.NewNullDecimal(price)
+
+	if !last.Valid {
+		return Alert{}
+	}
+
+	var direction string
+
+	switch {
+	case last.Decimal.LessThan(r.Threshold) && price.GreaterThanOrEqual(r.Threshold):
+		direction = "above"
+	case last.Decimal.GreaterThan(r.Threshold) && price.LessThanOrEqual(r.Threshold):
+		direction = "below"
+	default:
+		return Alert{}
+	}
+
+	return r.alert(price, ts, fmt.Sprintf("%s crossed %s %s", r.Symbol, direction, r.Threshold))
+}
+
+// move fires when the price moved the threshold or more from the lowest or highest price within the window.
+// The window restarts after firing, so a move fires once.
+func (r *ruleState) move(price decimal.Decimal, ts time.Time) Alert {
+	r.samples = slices.DeleteFunc(r.samples, func(s sample) bool {
+		return ts.Sub(s.ts) > r.Window
+	})
+
+	low, high := price, price
+
+	for _, s := range r.samples {
+		low = decimal.Min(low, s.price)
+		high = decimal.Max(high, s.price)
+	}
+
+	r.samples = append(r.samples, sample{price: price, ts: ts})
+
+	var change decimal.Decimal
+
+	hundred := decimal.NewFromInt(100)
+
+	switch {
+	case low.IsPositive() && price.Sub(low).Div(low).Mul(hundred).GreaterThanOrEqual(r.Threshold):
+		change = price.Sub(low).Div(low).Mul(hundred)
+	case high.IsPositive() && high.Sub(price).Div(high).Mul(hundred).GreaterThanOrEqual(r.Threshold):
+		change = price.Sub(high).Div(high).Mul(hundred)
+	default:
+		return Alert{}
+	}
+
+	r.samples = []sample{{price: price, ts: ts}}
+
+	return r.alert(price, ts, fmt.Sprintf("%s moved %s%% in %s", r.Symbol, change.StringFixed(2), r.Window))
+}
+
+func (r *ruleState) alert(price decimal.Decimal, ts time.Time, message string) Alert {
+	return Alert{
+		RuleID:    r.ID,
+		Symbol:    r.Symbol,
+		Kind:      r.Kind,
+		Price:     price,
+		Message:   message,
+		Timestamp: ts,
+	}
+}
+
+func (nr NewRule) validate() error {
+	if strings.TrimSpace(nr.Symbol) == "" {
+		return errors.New("symbol is required")
+	}
+
+	switch nr.Kind {
+	case KindCrosses:
+		if !nr.Threshold.IsPositive() {
+			return errors.New("threshold must be a positive price")
+		}
+	case KindMove:
+		if !nr.Threshold.IsPositive() {
+			return errors.New("threshold must be a positive percentage")
+		}
+
+		if nr.Window <= 0 {
+			return errors.New("window must be positive")
+		}
+	case KindStale:
+		if nr.Window <= 0 {
+			return errors.New("window must be positive")
+		}
+	default:
+		return fmt.Errorf("unsupported kind %q, expected crosses, move or stale", nr.Kind)
+	}
+
+	if nr.WebhookURL != "" {
+		if err := webhookclient.ValidateURL(nr.WebhookURL); err != nil {
+			return fmt.Errorf("invalid webhook: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
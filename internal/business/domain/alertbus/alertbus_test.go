@@ -0,0 +1,244 @@
+package alertbus_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/alertbus"
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+)
+
+func TestBusiness_Create(t *testing.T) {
+	b := alertbus.NewBusiness(nil)
+
+	rule, err := b.Create(alertbus.NewRule{
+		Symbol:     " btc",
+		Kind:       alertbus.KindCrosses,
+		Threshold:  decimal.NewFromInt(100000),
+		WebhookURL: "https://example.com/hook",
+	})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, rule.ID)
+	assert.Equal(t, "BTC", rule.Symbol)
+	assert.Len(t, rule.WebhookSecret, 64) // generated
+
+	other, err := b.Create(alertbus.NewRule{Symbol: "ETH", Kind: alertbus.KindStale, Window: time.Minute})
+	require.NoError(t, err)
+
+	assert.Empty(t, other.WebhookSecret)
+	assert.Equal(t, []alertbus.Rule{rule, other}, b.Rules())
+
+	found, err := b.Rule(rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rule, found)
+
+	require.NoError(t, b.Delete(rule.ID))
+	assert.ErrorIs(t, b.Delete(rule.ID), alertbus.ErrNotFound)
+
+	_, err = b.Rule(rule.ID)
+	assert.ErrorIs(t, err, alertbus.ErrNotFound)
+	assert.Equal(t, []alertbus.Rule{other}, b.Rules())
+}
+
+func TestBusiness_Create_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		Rule     alertbus.NewRule
+		Expected string
+	}{
+		"missing symbol": {
+			Rule:     alertbus.NewRule{Kind: alertbus.KindStale, Window: time.Minute},
+			Expected: "invalid alert rule: symbol is required",
+		},
+		"unsupported kind": {
+			Rule:     alertbus.NewRule{Symbol: "BTC", Kind: "above"},
+			Expected: `invalid alert rule: unsupported kind "above", expected crosses, move or stale`,
+		},
+		"crosses without threshold": {
+			Rule:     alertbus.NewRule{Symbol: "BTC", Kind: alertbus.KindCrosses},
+			Expected: "invalid alert rule: threshold must be a positive price",
+		},
+		"move without window": {
+			Rule:     alertbus.NewRule{Symbol: "BTC", Kind: alertbus.KindMove, Threshold: decimal.NewFromInt(3)},
+			Expected: "invalid alert rule: window must be positive",
+		},
+		"stale without window": {
+			Rule:     alertbus.NewRule{Symbol: "BTC", Kind: alertbus.KindStale},
+			Expected: "invalid alert rule: window must be positive",
+		},
+		"invalid webhook": {
+			Rule: alertbus.NewRule{
+				Symbol:     "BTC",
+				Kind:       alertbus.KindStale,
+				Window:     time.Minute,
+				WebhookURL: "ftp://example.com",
+			},
+			Expected: `invalid alert rule: invalid webhook: url "ftp://example.com" must be an absolute https url`,
+		},
+		"http webhook": {
+			Rule: alertbus.NewRule{
+				Symbol:     "BTC",
+				Kind:       alertbus.KindStale,
+				Window:     time.Minute,
+				WebhookURL: "http://example.com/hook",
+			},
+			Expected: `invalid alert rule: invalid webhook: url "http://example.com/hook" must be an absolute https url`,
+		},
+		"private webhook": {
+			Rule: alertbus.NewRule{
+				Symbol:     "BTC",
+				Kind:       alertbus.KindStale,
+				Window:     time.Minute,
+				WebhookURL: "https://10.0.0.1/hook",
+			},
+			Expected: `invalid alert rule: invalid webhook: url "https://10.0.0.1/hook" must not target a local or private address`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := alertbus.NewBusiness(nil)
+
+			_, err := b.Create(test.Rule)
+
+			require.ErrorIs(t, err, alertbus.ErrInvalidRule)
+			assert.EqualError(t, err, test.Expected)
+		})
+	}
+}
+
+func TestBusiness_Evaluate_Crosses(t *testing.T) {
+	b := alertbus.NewBusiness(nil)
+
+	rule, err := b.Create(alertbus.NewRule{Symbol: "BTC", Kind: alertbus.KindCrosses, Threshold: decimal.NewFromInt(100000)})
+	require.NoError(t, err)
+
+	assert.Empty(t, b.Evaluate(t.Context(), btcPrice("2025-01-01T10:00:00Z", "99000")))
+	assert.Empty(t, b.Evaluate(t.Context(), btcPrice("2025-01-01T10:00:05Z", "99500")))
+	assert.Empty(t, b.Evaluate(t.Context(), ethPrice("2025-01-01T10:00:05Z", "100001")))
+
+	alerts := b.Evaluate(t.Context(), btcPrice("2025-01-01T10:00:10Z", "100000"))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, alertbus.Alert{
+		RuleID:    rule.ID,
+		Symbol:    "BTC",
+		Kind:      alertbus.KindCrosses,
+		Price:     decimal.RequireFromString("100000"),
+		Message:   "BTC crossed above 100000",
+		Timestamp: time.Date(2025, 1, 1, 10, 0, 10, 0, time.UTC),
+	}, alerts[0])
+
+	assert.Empty(t, b.Evaluate(t.Context(), btcPrice("2025-01-01T10:00:15Z", "100500")))
+
+	alerts = b.Evaluate(t.Context(), btcPrice("2025-01-01T10:00:20Z", "99999"))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "BTC crossed below 100000", alerts[0].Message)
+}
+
+func TestBusiness_Evaluate_Move(t *testing.T) {
+	b := alertbus.NewBusiness(nil)
+
+	_, err := b.Create(alertbus.NewRule{
+		Symbol:    "BTC",
+		Kind:      alertbus.KindMove,
+		Threshold: decimal.NewFromInt(3),
+		Window:    15 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, b.Evaluate(t.Context(), btcPrice("2025-01-01T10:00:00Z", "100")))
+	assert.Empty(t, b.Evaluate(t.Context(), btcPrice("2025-01-01T10:05:00Z", "102")))
+
+	alerts := b.Evaluate(t.Context(), btcPrice("2025-01-01T10:10:00Z", "103"))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "BTC moved 3.00% in 15m0s", alerts[0].Message)
+
+	// the window restarts after firing
+	assert.Empty(t, b.Evaluate(t.Context(), btcPrice("2025-01-01T10:11:00Z", "104")))
+
+	// prices older than the window are forgotten
+	assert.Empty(t, b.Evaluate(t.Context(), btcPrice("2025-01-01T10:30:00Z", "100.5")))
+
+	alerts = b.Evaluate(t.Context(), btcPrice("2025-01-01T10:35:00Z", "97.4"))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "BTC moved -3.08% in 15m0s", alerts[0].Message)
+}
+
+func TestBusiness_CheckStale(t *testing.T) {
+	notifier := &mockNotifier{sent: make(chan sentWebhook, 1)}
+	b := alertbus.NewBusiness(notifier)
+
+	rule, err := b.Create(alertbus.NewRule{
+		Symbol:        "BTC",
+		Kind:          alertbus.KindStale,
+		Window:        5 * time.Minute,
+		WebhookURL:    "https://example.com/hook",
+		WebhookSecret: "s3cr3t",
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	b.Evaluate(t.Context(), btcPrice(now.Format(time.RFC3339), "50000"))
+
+	assert.Empty(t, b.CheckStale(t.Context(), now.Add(4*time.Minute)))
+
+	alerts := b.CheckStale(t.Context(), now.Add(5*time.Minute))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "BTC price stale for 5m0s", alerts[0].Message)
+	assert.Equal(t, "50000", alerts[0].Price.String())
+
+	select {
+	case webhook := <-notifier.sent:
+		assert.Equal(t, "https://example.com/hook", webhook.url)
+		assert.Equal(t, "s3cr3t", webhook.secret)
+
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(webhook.body, &payload))
+
+		assert.Equal(t, rule.ID, payload["rule_id"])
+		assert.Equal(t, "stale", payload["kind"])
+		assert.Equal(t, "50000", payload["price"])
+	case <-time.After(time.Second):
+		require.Fail(t, "webhook not sent")
+	}
+
+	// fires once until a newer price is received
+	assert.Empty(t, b.CheckStale(t.Context(), now.Add(10*time.Minute)))
+
+	b.Evaluate(t.Context(), btcPrice(now.Add(6*time.Minute).Format(time.RFC3339), "50001"))
+
+	assert.Empty(t, b.CheckStale(t.Context(), now.Add(10*time.Minute)))
+	assert.Len(t, b.CheckStale(t.Context(), now.Add(11*time.Minute)), 1)
+}
+
+func btcPrice(ts, price string) pricebus.Price {
+	return pricebus.Price{Symbol: "BTC", Timestamp: ts, Price: decimal.RequireFromString(price)}
+}
+
+func ethPrice(ts, price string) pricebus.Price {
+	return pricebus.Price{Symbol: "ETH", Timestamp: ts, Price: decimal.RequireFromString(price)}
+}
+
+type (
+	mockNotifier struct {
+		sent chan sentWebhook
+	}
+
+	sentWebhook struct {
+		url    string
+		secret string
+		body   []byte
+	}
+)
+
+func (m *mockNotifier) Send(_ context.Context, url, secret string, body []byte) error {
+	m.sent <- sentWebhook{url: url, secret: secret, body: body}
+	return nil
+}
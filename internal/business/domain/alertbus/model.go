@@ -0,0 +1,51 @@
+package alertbus
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Kinds of alert rules.
+const (
+	// KindCrosses fires when the price crosses the threshold, in either direction.
+	KindCrosses = "crosses"
+	// KindMove fires when the price moves more than the threshold, in percent, within the window.
+	KindMove = "move"
+	// KindStale fires when no new price was received for the window.
+	KindStale = "stale"
+)
+
+type (
+	// NewRule represents the rule to create.
+	NewRule struct {
+		Symbol        string
+		Kind          string
+		Threshold     decimal.Decimal // price for crosses, percent for move, unused for stale
+		Window        time.Duration   // for move and stale
+		WebhookURL    string          // optional, the alerts are also posted to it
+		WebhookSecret string          // signs the webhooks, generated when empty
+	}
+
+	// Rule represents an alert rule.
+	Rule struct {
+		ID            string
+		Symbol        string
+		Kind          string
+		Threshold     decimal.Decimal
+		Window        time.Duration
+		WebhookURL    string
+		WebhookSecret string
+		CreatedAt     time.Time
+	}
+
+	// Alert represents a rule that fired.
+	Alert struct {
+		RuleID    string
+		Symbol    string
+		Kind      string
+		Price     decimal.Decimal // price that fired the rule, the last one received for stale
+		Message   string
+		Timestamp time.Time
+	}
+)
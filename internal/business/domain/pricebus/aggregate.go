@@ -0,0 +1,302 @@
+package pricebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/gandarez/btc-price-service/internal/foundation/log"
+)
+
+const (
+	// SourceAggregate is the name of the aggregated price source.
+	SourceAggregate = "aggregate"
+
+	// AggregationMedian computes the composite price as the median of the quotes.
+	AggregationMedian = "median"
+	// AggregationVWAP computes the composite price as the mean of the quotes reporting a volume, weighted by it
+	// in base asset units. It falls back to the median when no quote reports a volume.
+	AggregationVWAP = "vwap"
+)
+
+type (
+	// Aggregator is a PriceSource that computes a composite price from several sources.
+	// Stale quotes and quotes deviating too far from the median are dropped before aggregating.
+	// Each rate-limited source is called on its own cadence, spreading its remaining quota over the symbols,
+	// and contributes its last quote of the symbol in between.
+	Aggregator struct {
+		sources []PriceSource
+		cfg     AggregatorConfig
+		mu      sync.Mutex
+		symbols map[string]struct{}  // symbols quoted so far, sharing the quota of the rate-limited sources
+		calls   map[string]time.Time // last call to a rate-limited source by source and symbol
+		last    map[string]Quote     // last quote of a rate-limited source by source and symbol
+	}
+
+	// AggregatorConfig holds the configuration for the Aggregator.
+	AggregatorConfig struct {
+		// Method is the aggregation method, either AggregationMedian or AggregationVWAP.
+		Method string
+		// MaxDeviation is the maximum deviation from the median, in percent. Zero disables the check.
+		MaxDeviation float64
+		// MaxQuoteAge is the maximum age of a quote. Zero disables the check.
+		MaxQuoteAge time.Duration
+		// MinSources is the minimum number of quotes required to compute a price.
+		MinSources int
+	}
+)
+
+// NewAggregator creates a new instance of the Aggregator struct.
+func NewAggregator(cfg AggregatorConfig, sources ...PriceSource) (*Aggregator, error) {
+	if cfg.Method != AggregationMedian && cfg.Method != AggregationVWAP {
+		return nil, fmt.Errorf("unsupported aggregation method %q", cfg.Method)
+	}
+
+	if len(sources) == 0 {
+		return nil, errors.New("at least one price source is required")
+	}
+
+	cfg.MinSources = max(cfg.MinSources, 1)
+
+	return &Aggregator{
+		sources: sources,
+		cfg:     cfg,
+		symbols: make(map[string]struct{}),
+		calls:   make(map[string]time.Time),
+		last:    make(map[string]Quote),
+	}, nil
+}
+
+// Name implements PriceSource interface.
+func (*Aggregator) Name() string {
+	return SourceAggregate
+}
+
+// Quote implements PriceSource interface.
+// It queries all sources concurrently and returns the composite price along with the contributing quotes.
+func (a *Aggregator) Quote(ctx context.Context, symbol string) (Price, error) {
+	logger := log.Extract(ctx)
+
+	quotes := a.collect(ctx, symbol)
+
+	// drop stale quotes
+	if a.cfg.MaxQuoteAge > 0 {
+		cutoff := time.Now().UTC().Add(-a.cfg.MaxQuoteAge)
+
+		quotes = slices.DeleteFunc(quotes, func(q Quote) bool {
+			ts, err := time.Parse(time.RFC3339, q.Timestamp)
+			if err != nil || ts.Before(cutoff) {
+				logger.Warnf("dropping stale %s quote from %s: %s", symbol, q.Source, q.Timestamp)
+				return true
+			}
+
+			return false
+		})
+	}
+
+	if len(quotes) == 0 {
+		return Price{}, fmt.Errorf("no quotes available for %s", symbol)
+	}
+
+	// drop outliers
+	if a.cfg.MaxDeviation > 0 {
+		m := median(quotes)
+
+		quotes = slices.DeleteFunc(quotes, func(q Quote) bool {
+			deviation := deviationPct(q.Price, m)
+			if deviation > a.cfg.MaxDeviation {
+				logger.Warnf("dropping outlier %s quote from %s: %s deviates %.2f%% from median %s",
+					symbol, q.Source, q.Price, deviation, m)
+
+				return true
+			}
+
+			return false
+		})
+	}
+
+	if len(quotes) < a.cfg.MinSources {
+		return Price{}, fmt.Errorf("not enough quotes for %s: got %d, want at least %d",
+			symbol, len(quotes), a.cfg.MinSources)
+	}
+
+	price, method := median(quotes), AggregationMedian
+	if a.cfg.Method == AggregationVWAP {
+		if vwap, ok := volumeWeightedMean(quotes); ok {
+			price, method = vwap, AggregationVWAP
+		} else {
+			logger.Debugf("falling back to the median %s price, no quote reports a volume", symbol)
+		}
+	}
+
+	var (
+		volume decimal.Decimal
+		market Market
+	)
+
+	timestamp := quotes[0].Timestamp
+
+	for _, q := range quotes {
+		volume = volume.Add(q.Volume)
+
+		// market data is only reported by some sources, keep the first one found
+		if !market.Known() {
+			market = q.Market
+		}
+
+		// RFC3339 timestamps in UTC sort lexicographically
+		if q.Timestamp > timestamp {
+			timestamp = q.Timestamp
+		}
+	}
+
+	return Price{
+		Symbol:      symbol,
+		Timestamp:   timestamp,
+		Price:       price,
+		Volume:      volume,
+		Sources:     quotes,
+		Aggregation: method,
+		Market:      market,
+	}, nil
+}
+
+// collect queries all sources concurrently and returns the quotes of those that succeeded.
+// A rate-limited source that is not due returns its last quote of the symbol instead, if any.
+func (a *Aggregator) collect(ctx context.Context, symbol string) []Quote {
+	logger := log.Extract(ctx)
+
+	results := make([]*Quote, len(a.sources))
+
+	var wg sync.WaitGroup
+
+	for i, source := range a.sources {
+		if last, ok := a.skip(source, symbol, time.Now()); ok {
+			logger.Debugf("skipping %s quote from %s until due to stay within its rate limit", symbol, source.Name())
+
+			results[i] = last
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			price, err := source.Quote(ctx, symbol)
+			if err != nil {
+				logger.Warnf("failed to fetch %s quote from %s: %v", symbol, source.Name(), err)
+				return
+			}
+
+			results[i] = &Quote{
+				Source:    source.Name(),
+				Timestamp: price.Timestamp,
+				Price:     price.Price,
+				Volume:    price.Volume,
+				Market:    price.Market,
+			}
+
+			a.remember(source, symbol, *results[i])
+		}()
+	}
+
+	wg.Wait()
+
+	quotes := make([]Quote, 0, len(results))
+
+	for _, q := range results {
+		if q != nil {
+			quotes = append(quotes, *q)
+		}
+	}
+
+	return quotes
+}
+
+// skip reports whether a rate-limited source must not be called for the symbol yet, because its quota is exhausted
+// or was spread over the symbols until a later call, along with its last quote of the symbol, nil when none.
+// The call is counted when it is not skipped.
+func (a *Aggregator) skip(source PriceSource, symbol string, now time.Time) (*Quote, bool) {
+	limiter, ok := source.(RateLimiter)
+	if !ok {
+		return nil, false
+	}
+
+	rateLimit := limiter.RateLimit()
+	key := source.Name() + "/" + symbol
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.symbols[symbol] = struct{}{}
+
+	called, ok := a.calls[key]
+
+	due := !ok || now.Sub(called) >= rateLimit.Interval(now)*time.Duration(len(a.symbols))
+	if due && !rateLimit.Exhausted(now) {
+		a.calls[key] = now
+		return nil, false
+	}
+
+	if last, ok := a.last[key]; ok {
+		return &last, true
+	}
+
+	return nil, true
+}
+
+// remember keeps the last quote of a rate-limited source for the symbol.
+func (a *Aggregator) remember(source PriceSource, symbol string, quote Quote) {
+	if _, ok := source.(RateLimiter); !ok {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.last[source.Name()+"/"+symbol] = quote
+}
+
+func median(quotes []Quote) decimal.Decimal {
+	prices := make([]decimal.Decimal, 0, len(quotes))
+	for _, q := range quotes {
+		prices = append(prices, q.Price)
+	}
+
+	slices.SortFunc(prices, decimal.Decimal.Cmp)
+
+	n := len(prices)
+	if n%2 == 1 {
+		return prices[n/2]
+	}
+
+	return prices[n/2-1].Add(prices[n/2]).Div(decimal.NewFromInt(2))
+}
+
+// volumeWeightedMean returns the mean of the prices of the quotes reporting a volume, weighted by it.
+// It reports false when no quote reports a volume.
+func volumeWeightedMean(quotes []Quote) (decimal.Decimal, bool) {
+	var sum, volume decimal.Decimal
+
+	for _, q := range quotes {
+		if !q.Volume.IsPositive() {
+			continue
+		}
+
+		sum = sum.Add(q.Price.Mul(q.Volume))
+		volume = volume.Add(q.Volume)
+	}
+
+	if volume.IsZero() {
+		return decimal.Decimal{}, false
+	}
+
+	return sum.Div(volume), true
+}
@@ -0,0 +1,198 @@
+package pricebus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+)
+
+func TestAggregator_Quote_Median(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	agg, err := pricebus.NewAggregator(
+		pricebus.AggregatorConfig{Method: pricebus.AggregationMedian},
+		staticSource("a", "100", "", now),
+		staticSource("b", "102", "", now),
+		staticSource("c", "101", "", now),
+	)
+	require.NoError(t, err)
+
+	result, err := agg.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "BTC", result.Symbol)
+	assert.Equal(t, now, result.Timestamp)
+	assert.Equal(t, "101", result.Price.String())
+	assert.Equal(t, []pricebus.Quote{
+		{Source: "a", Timestamp: now, Price: decimal.RequireFromString("100")},
+		{Source: "b", Timestamp: now, Price: decimal.RequireFromString("102")},
+		{Source: "c", Timestamp: now, Price: decimal.RequireFromString("101")},
+	}, result.Sources)
+}
+
+func TestAggregator_Quote_Market(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	market := pricebus.Market{MarketCap: decimal.NewNullDecimal(decimal.RequireFromString("990000000000"))}
+
+	withMarket := staticSource("b", "101", "", now)
+	quote := withMarket.QuoteFn
+	withMarket.QuoteFn = func(ctx context.Context, symbol string) (pricebus.Price, error) {
+		price, err := quote(ctx, symbol)
+		price.Market = market
+
+		return price, err
+	}
+
+	agg, err := pricebus.NewAggregator(
+		pricebus.AggregatorConfig{Method: pricebus.AggregationMedian},
+		staticSource("a", "100", "", now),
+		withMarket,
+	)
+	require.NoError(t, err)
+
+	result, err := agg.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, market, result.Market)
+}
+
+func TestAggregator_Quote_VWAP(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	agg, err := pricebus.NewAggregator(
+		pricebus.AggregatorConfig{Method: pricebus.AggregationVWAP},
+		staticSource("a", "100", "3", now),
+		staticSource("b", "200", "1", now),
+	)
+	require.NoError(t, err)
+
+	result, err := agg.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "125", result.Price.String())
+	assert.Equal(t, "4", result.Volume.String())
+	assert.Equal(t, pricebus.AggregationVWAP, result.Aggregation)
+}
+
+func TestAggregator_Quote_VWAPWithoutVolume(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	agg, err := pricebus.NewAggregator(
+		pricebus.AggregatorConfig{Method: pricebus.AggregationVWAP},
+		staticSource("a", "100", "", now),
+		staticSource("b", "200", "", now),
+	)
+	require.NoError(t, err)
+
+	result, err := agg.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "150", result.Price.String())
+	assert.Equal(t, pricebus.AggregationMedian, result.Aggregation)
+}
+
+func TestAggregator_Quote_VWAPMixedSources(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	agg, err := pricebus.NewAggregator(
+		pricebus.AggregatorConfig{Method: pricebus.AggregationVWAP},
+		staticSource("a", "100", "", now),
+		staticSource("b", "110", "1", now),
+		staticSource("c", "200", "3", now),
+	)
+	require.NoError(t, err)
+
+	result, err := agg.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "177.5", result.Price.String())
+	assert.Equal(t, pricebus.AggregationVWAP, result.Aggregation)
+	assert.Len(t, result.Sources, 3)
+}
+
+func TestAggregator_Quote_DropsOutliersAndStale(t *testing.T) {
+	now := time.Now().UTC()
+	stale := now.Add(-time.Hour).Format(time.RFC3339)
+
+	agg, err := pricebus.NewAggregator(
+		pricebus.AggregatorConfig{
+			Method:       pricebus.AggregationMedian,
+			MaxDeviation: 1,
+			MaxQuoteAge:  time.Minute,
+		},
+		staticSource("a", "100", "", now.Format(time.RFC3339)),
+		staticSource("b", "100.5", "", now.Format(time.RFC3339)),
+		staticSource("c", "150", "", now.Format(time.RFC3339)), // outlier
+		staticSource("d", "99", "", stale),
+		&mockPriceSource{
+			SourceName: "e",
+			QuoteFn: func(_ context.Context, _ string) (pricebus.Price, error) {
+				return pricebus.Price{}, errors.New("fail")
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	result, err := agg.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "100.25", result.Price.String())
+	require.Len(t, result.Sources, 2)
+	assert.Equal(t, "a", result.Sources[0].Source)
+	assert.Equal(t, "b", result.Sources[1].Source)
+}
+
+func TestAggregator_Quote_NotEnoughSources(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	agg, err := pricebus.NewAggregator(
+		pricebus.AggregatorConfig{Method: pricebus.AggregationMedian, MinSources: 2},
+		staticSource("a", "100", "", now),
+		&mockPriceSource{
+			SourceName: "b",
+			QuoteFn: func(_ context.Context, _ string) (pricebus.Price, error) {
+				return pricebus.Price{}, errors.New("fail")
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	_, err = agg.Quote(t.Context(), "BTC")
+
+	assert.EqualError(t, err, "not enough quotes for BTC: got 1, want at least 2")
+}
+
+func TestNewAggregator_Err(t *testing.T) {
+	_, err := pricebus.NewAggregator(pricebus.AggregatorConfig{Method: "mean"}, staticSource("a", "1", "0", ""))
+	assert.EqualError(t, err, `unsupported aggregation method "mean"`)
+
+	_, err = pricebus.NewAggregator(pricebus.AggregatorConfig{Method: pricebus.AggregationMedian})
+	assert.EqualError(t, err, "at least one price source is required")
+}
+
+func staticSource(name, price, volume, timestamp string) *mockPriceSource {
+	var v decimal.Decimal
+	if volume != "" {
+		v = decimal.RequireFromString(volume)
+	}
+
+	return &mockPriceSource{
+		SourceName: name,
+		QuoteFn: func(_ context.Context, symbol string) (pricebus.Price, error) {
+			return pricebus.Price{
+				Symbol:    symbol,
+				Timestamp: timestamp,
+				Price:     decimal.RequireFromString(price),
+				Volume:    v,
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package pricebus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coinbaseclient"
+)
+
+// SourceCoinbase is the name of the Coinbase price source.
+const SourceCoinbase = "coinbase"
+
+type (
+	// CoinbaseSource is the PriceSource backed by the Coinbase API.
+	CoinbaseSource struct {
+		coinbasecli CoinbaseClient
+	}
+
+	// CoinbaseClient defines the interface for fetching spot prices from the Coinbase API.
+	CoinbaseClient interface {
+		SpotPrice(context.Context, string) (coinbaseclient.SpotResult, error)
+	}
+)
+
+// NewCoinbaseSource creates a new instance of the CoinbaseSource struct.
+func NewCoinbaseSource(coinbasecli CoinbaseClient) *CoinbaseSource {
+	return &CoinbaseSource{
+		coinbasecli: coinbasecli,
+	}
+}
+
+// Name implements PriceSource interface.
+func (*CoinbaseSource) Name() string {
+	return SourceCoinbase
+}
+
+// Quote implements PriceSource interface.
+func (s *CoinbaseSource) Quote(ctx context.Context, symbol string) (Price, error) {
+	result, err := s.coinbasecli.SpotPrice(ctx, symbol+"-"+quoteCurrency)
+	if err != nil {
+		return Price{}, fmt.Errorf("failed to fetch spot price: %v", err)
+	}
+
+	if result.Error != "" {
+		return Price{}, fmt.Errorf("failed to fetch spot price: %s", result.Error)
+	}
+
+	return spotToBusPrice(symbol, result.Spot)
+}
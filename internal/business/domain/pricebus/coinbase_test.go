@@ -0,0 +1,70 @@
+package pricebus_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coinbaseclient"
+)
+
+func TestCoinbaseSource_Quote(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/v2/prices/BTC-USD/spot", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"data":{"amount":"50000.25","base":"BTC","currency":"USD"}}`))
+		require.NoError(t, err)
+	})
+
+	source := pricebus.NewCoinbaseSource(coinbaseclient.NewClient(url))
+
+	result, err := source.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "coinbase", source.Name())
+	assert.Equal(t, "BTC", result.Symbol)
+	assert.Equal(t, "50000.25", result.Price.String())
+
+	_, err = time.Parse(time.RFC3339, result.Timestamp)
+	assert.NoError(t, err)
+}
+
+func TestCoinbaseSource_Quote_APIErr(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/v2/prices/FOO-USD/spot", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte(`{"errors":[{"id":"not_found","message":"Invalid base currency"}]}`))
+		require.NoError(t, err)
+	})
+
+	source := pricebus.NewCoinbaseSource(coinbaseclient.NewClient(url))
+
+	_, err := source.Quote(t.Context(), "FOO")
+
+	assert.EqualError(t, err, "failed to fetch spot price: Invalid base currency")
+}
+
+func TestCoinbaseSource_Quote_InvalidAmount(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/v2/prices/BTC-USD/spot", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"data":{"amount":"n/a","base":"BTC","currency":"USD"}}`))
+		require.NoError(t, err)
+	})
+
+	source := pricebus.NewCoinbaseSource(coinbaseclient.NewClient(url))
+
+	_, err := source.Quote(t.Context(), "BTC")
+
+	assert.ErrorContains(t, err, `invalid spot price "n/a"`)
+}
@@ -0,0 +1,101 @@
+package pricebus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/page"
+	"github.com/gandarez/btc-price-service/internal/foundation/log"
+)
+
+// SourceCoinDesk is the name of the CoinDesk price source.
+const SourceCoinDesk = "coindesk"
+
+type (
+	// CoinDeskSource is the PriceSource backed by the CoinDesk API.
+	CoinDeskSource struct {
+		coindeskcli CoinDeskClient
+	}
+
+	// CoinDeskClient defines the interface for fetching asset prices from the CoinDesk API.
+	CoinDeskClient interface {
+		AssetBySymbol(context.Context, string) (coindeskclient.AssetResult, error)
+		TopList(context.Context, page.Page) (coindeskclient.Result, error)
+		RateLimit() coindeskclient.RateLimit
+	}
+)
+
+// NewCoinDeskSource creates a new instance of the CoinDeskSource struct.
+func NewCoinDeskSource(coindeskcli CoinDeskClient) *CoinDeskSource {
+	return &CoinDeskSource{
+		coindeskcli: coindeskcli,
+	}
+}
+
+// Name implements PriceSource interface.
+func (*CoinDeskSource) Name() string {
+	return SourceCoinDesk
+}
+
+// Quote implements PriceSource interface.
+// It looks the asset up by its symbol and falls back to scanning the top list only when the symbol is not found.
+// Any other failure, such as rate limits, an exhausted budget or an unavailable upstream, is returned as is,
+// since scanning the top list would only make more calls.
+func (s *CoinDeskSource) Quote(ctx context.Context, symbol string) (Price, error) {
+	result, err := s.coindeskcli.AssetBySymbol(ctx, symbol)
+
+	switch {
+	case err != nil:
+		return Price{}, fmt.Errorf("failed to fetch asset %s by symbol: %w", symbol, err)
+	case result.NotFound:
+		log.Extract(ctx).Warnf("asset %s not found by symbol, falling back to top list: %s", symbol, result.Error)
+	case result.Error != "":
+		return Price{}, fmt.Errorf("failed to fetch asset %s by symbol: %s", symbol, result.Error)
+	case result.Asset.Symbol != symbol:
+		log.Extract(ctx).Warnf("asset %s not returned by symbol lookup, falling back to top list", symbol)
+	default:
+		return toBusPrice(result.Asset), nil
+	}
+
+	pagination, err := page.New(1, 100) // Default to page 1 with 100 rows per page
+	if err != nil {
+		return Price{}, err
+	}
+
+	return s.topListAssetPrice(ctx, symbol, pagination)
+}
+
+// topListAssetPrice retrieves the current asset price by paging through the CoinDesk top list.
+func (s *CoinDeskSource) topListAssetPrice(ctx context.Context, symbol string, pagination page.Page) (Price, error) {
+	result, err := s.coindeskcli.TopList(ctx, pagination)
+	if err != nil {
+		return Price{}, fmt.Errorf("failed to fetch top list: %w", err)
+	}
+
+	if result.Error != "" {
+		return Price{}, fmt.Errorf("failed to fetch top list: %s", result.Error)
+	}
+
+	if len(result.TopList.Data.Assets) == 0 {
+		return Price{}, fmt.Errorf("no assets found for page %d", pagination.Number())
+	}
+
+	for _, asset := range result.TopList.Data.Assets {
+		if asset.Symbol == symbol {
+			return toBusPrice(asset), nil
+		}
+	}
+
+	// recursively search through pages until we find the asset
+	if result.TopList.Data.Stats.Page*result.TopList.Data.Stats.PageSize < result.TopList.Data.Stats.TotalAssets {
+		nextPage, err := page.New(pagination.Number()+1, pagination.RowsPerPage())
+		if err != nil {
+			return Price{}, err
+		}
+
+		return s.topListAssetPrice(ctx, symbol, nextPage)
+	}
+
+	return Price{}, fmt.Errorf("asset %s not found in top list", symbol)
+}
@@ -0,0 +1,268 @@
+package pricebus_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/page"
+)
+
+func TestCoinDeskSource_Quote(t *testing.T) {
+	mockCoinDeskClient := &mockCoinDeskClient{
+		AssetBySymbolFn: func(_ context.Context, symbol string) (coindeskclient.AssetResult, error) {
+			assert.Equal(t, "BTC", symbol)
+
+			return coindeskclient.AssetResult{
+				Asset: coindeskclient.Asset{Symbol: "BTC", Price: decimal.RequireFromString("50000"), PriceLastUpdatedAt: 1633072800},
+			}, nil
+		},
+	}
+
+	source := pricebus.NewCoinDeskSource(mockCoinDeskClient)
+
+	result, err := source.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, pricebus.Price{
+		Symbol:    "BTC",
+		Timestamp: "2021-10-01T07:20:00Z",
+		Price:     decimal.RequireFromString("50000"),
+	}, result)
+	assert.Equal(t, 1, mockCoinDeskClient.AssetBySymbolFnCount)
+	assert.Zero(t, mockCoinDeskClient.TopListFnCount)
+}
+
+func TestCoinDeskSource_Quote_NoFallbackOnErr(t *testing.T) {
+	tests := map[string]func(context.Context, string) (coindeskclient.AssetResult, error){
+		"budget exhausted": func(context.Context, string) (coindeskclient.AssetResult, error) {
+			return coindeskclient.AssetResult{}, coindeskclient.ErrBudgetExhausted
+		},
+		"rate limited": func(context.Context, string) (coindeskclient.AssetResult, error) {
+			return coindeskclient.AssetResult{Error: "You are over your rate limit."}, nil
+		},
+	}
+
+	for name, assetBySymbol := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockCoinDeskClient := &mockCoinDeskClient{AssetBySymbolFn: assetBySymbol}
+
+			source := pricebus.NewCoinDeskSource(mockCoinDeskClient)
+
+			_, err := source.Quote(t.Context(), "BTC")
+			require.Error(t, err)
+
+			assert.Equal(t, 1, mockCoinDeskClient.AssetBySymbolFnCount)
+			assert.Zero(t, mockCoinDeskClient.TopListFnCount)
+		})
+	}
+}
+
+func TestCoinDeskSource_Quote_TopListFallback(t *testing.T) {
+	mockCoinDeskClient := &mockCoinDeskClient{
+		AssetBySymbolFn: assetBySymbolNotFound,
+		TopListFn: func(_ context.Context, _ page.Page) (coindeskclient.Result, error) {
+			return coindeskclient.Result{
+				TopList: coindeskclient.TopList{
+					Data: coindeskclient.Data{
+						Stats: coindeskclient.Stats{
+							Page:        1,
+							PageSize:    10,
+							TotalAssets: 1,
+						},
+						Assets: []coindeskclient.Asset{
+							{Symbol: "BTC", Price: decimal.RequireFromString("50000"), PriceLastUpdatedAt: 1633072800},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	source := pricebus.NewCoinDeskSource(mockCoinDeskClient)
+
+	result, err := source.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, pricebus.Price{
+		Symbol:    "BTC",
+		Timestamp: "2021-10-01T07:20:00Z",
+		Price:     decimal.RequireFromString("50000"),
+	}, result)
+	assert.Equal(t, 1, mockCoinDeskClient.TopListFnCount)
+}
+
+func TestCoinDeskSource_Quote_Recursive(t *testing.T) {
+	mockCoinDeskClient := &mockCoinDeskClient{
+		AssetBySymbolFn: assetBySymbolNotFound,
+		TopListFn: func(_ context.Context, p page.Page) (coindeskclient.Result, error) {
+			if p.Number() == 1 {
+				return coindeskclient.Result{
+					TopList: coindeskclient.TopList{
+						Data: coindeskclient.Data{
+							Stats: coindeskclient.Stats{
+								Page:        1,
+								PageSize:    1,
+								TotalAssets: 2,
+							},
+							Assets: []coindeskclient.Asset{
+								{Symbol: "ETH", Price: decimal.RequireFromString("3000"), PriceLastUpdatedAt: 1633072800},
+							},
+						},
+					},
+				}, nil
+			}
+
+			return coindeskclient.Result{
+				TopList: coindeskclient.TopList{
+					Data: coindeskclient.Data{
+						Stats: coindeskclient.Stats{
+							Page:        2,
+							PageSize:    1,
+							TotalAssets: 1,
+						},
+						Assets: []coindeskclient.Asset{
+							{Symbol: "BTC", Price: decimal.RequireFromString("50000"), PriceLastUpdatedAt: 1633072800},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	source := pricebus.NewCoinDeskSource(mockCoinDeskClient)
+
+	result, err := source.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, pricebus.Price{
+		Symbol:    "BTC",
+		Timestamp: "2021-10-01T07:20:00Z",
+		Price:     decimal.RequireFromString("50000"),
+	}, result)
+	assert.Equal(t, 2, mockCoinDeskClient.TopListFnCount)
+}
+
+func TestCoinDeskSource_Quote_Err(t *testing.T) {
+	mockCoinDeskClient := &mockCoinDeskClient{
+		AssetBySymbolFn: assetBySymbolNotFound,
+		TopListFn: func(_ context.Context, _ page.Page) (coindeskclient.Result, error) {
+			return coindeskclient.Result{}, errors.New("fail")
+		},
+	}
+
+	source := pricebus.NewCoinDeskSource(mockCoinDeskClient)
+
+	_, err := source.Quote(t.Context(), "BTC")
+
+	assert.EqualError(t, err, "failed to fetch top list: fail")
+	assert.Equal(t, 1, mockCoinDeskClient.TopListFnCount)
+}
+
+func TestCoinDeskSource_Quote_APIErr(t *testing.T) {
+	mockCoinDeskClient := &mockCoinDeskClient{
+		AssetBySymbolFn: assetBySymbolNotFound,
+		TopListFn: func(_ context.Context, _ page.Page) (coindeskclient.Result, error) {
+			return coindeskclient.Result{
+				Error: "bad request",
+			}, nil
+		},
+	}
+
+	source := pricebus.NewCoinDeskSource(mockCoinDeskClient)
+
+	_, err := source.Quote(t.Context(), "BTC")
+
+	assert.EqualError(t, err, "failed to fetch top list: bad request")
+	assert.Equal(t, 1, mockCoinDeskClient.TopListFnCount)
+}
+
+func TestCoinDeskSource_Quote_NoAssets(t *testing.T) {
+	mockCoinDeskClient := &mockCoinDeskClient{
+		AssetBySymbolFn: assetBySymbolNotFound,
+		TopListFn: func(_ context.Context, _ page.Page) (coindeskclient.Result, error) {
+			return coindeskclient.Result{}, nil
+		},
+	}
+
+	source := pricebus.NewCoinDeskSource(mockCoinDeskClient)
+
+	_, err := source.Quote(t.Context(), "BTC")
+
+	assert.EqualError(t, err, "no assets found for page 1")
+	assert.Equal(t, 1, mockCoinDeskClient.TopListFnCount)
+}
+
+func TestCoinDeskSource_Quote_Server(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "ETH", req.URL.Query().Get("asset_symbol"))
+
+		body := `{"Data":{"ID":2,"SYMBOL":"ETH","PRICE_USD":3483.5,"PRICE_USD_LAST_UPDATE_TS":1633072800},"Err":{}}`
+
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(body))
+		require.NoError(t, err)
+	})
+
+	source := pricebus.NewCoinDeskSource(coindeskclient.NewClient(url, "api-key"))
+
+	result, err := source.Quote(t.Context(), "ETH")
+	require.NoError(t, err)
+
+	assert.Equal(t, pricebus.Price{
+		Symbol:    "ETH",
+		Timestamp: "2021-10-01T07:20:00Z",
+		Price:     decimal.RequireFromString("3483.5"),
+	}, result)
+}
+
+func assetBySymbolNotFound(_ context.Context, _ string) (coindeskclient.AssetResult, error) {
+	return coindeskclient.AssetResult{
+		Error:    "Not found: asset_symbol parameter.",
+		NotFound: true,
+	}, nil
+}
+
+type mockCoinDeskClient struct {
+	AssetBySymbolFn      func(ctx context.Context, symbol string) (coindeskclient.AssetResult, error)
+	AssetBySymbolFnCount int
+	TopListFn            func(ctx context.Context, page page.Page) (coindeskclient.Result, error)
+	TopListFnCount       int
+	RateLimitFn          func() coindeskclient.RateLimit
+}
+
+func (m *mockCoinDeskClient) AssetBySymbol(ctx context.Context, symbol string) (coindeskclient.AssetResult, error) {
+	m.AssetBySymbolFnCount++
+	return m.AssetBySymbolFn(ctx, symbol)
+}
+
+func (m *mockCoinDeskClient) TopList(ctx context.Context, p page.Page) (coindeskclient.Result, error) {
+	m.TopListFnCount++
+	return m.TopListFn(ctx, p)
+}
+
+func (m *mockCoinDeskClient) RateLimit() coindeskclient.RateLimit {
+	if m.RateLimitFn == nil {
+		return coindeskclient.RateLimit{}
+	}
+
+	return m.RateLimitFn()
+}
+
+func setupTestServer() (string, *http.ServeMux, func()) {
+	router := http.NewServeMux()
+	srv := httptest.NewServer(router)
+
+	return srv.URL, router, func() { srv.Close() }
+}
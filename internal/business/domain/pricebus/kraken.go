@@ -0,0 +1,61 @@
+package pricebus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/krakenclient"
+)
+
+// SourceKraken is the name of the Kraken price source.
+const SourceKraken = "kraken"
+
+type (
+	// KrakenSource is the PriceSource backed by the Kraken API.
+	KrakenSource struct {
+		krakencli KrakenClient
+	}
+
+	// KrakenClient defines the interface for fetching tickers from the Kraken API.
+	KrakenClient interface {
+		Ticker(context.Context, string) (krakenclient.TickerResult, error)
+	}
+)
+
+// NewKrakenSource creates a new instance of the KrakenSource struct.
+func NewKrakenSource(krakencli KrakenClient) *KrakenSource {
+	return &KrakenSource{
+		krakencli: krakencli,
+	}
+}
+
+// Name implements PriceSource interface.
+func (*KrakenSource) Name() string {
+	return SourceKraken
+}
+
+// Quote implements PriceSource interface.
+func (s *KrakenSource) Quote(ctx context.Context, symbol string) (Price, error) {
+	result, err := s.krakencli.Ticker(ctx, krakenSymbol(symbol)+quoteCurrency)
+	if err != nil {
+		return Price{}, fmt.Errorf("failed to fetch ticker: %v", err)
+	}
+
+	if result.Error != "" {
+		return Price{}, fmt.Errorf("failed to fetch ticker: %s", result.Error)
+	}
+
+	return tickerToBusPrice(symbol, result.Ticker)
+}
+
+// krakenSymbol maps an asset symbol to the one used by Kraken.
+func krakenSymbol(symbol string) string {
+	switch symbol {
+	case "BTC":
+		return "XBT"
+	case "DOGE":
+		return "XDG"
+	default:
+		return symbol
+	}
+}
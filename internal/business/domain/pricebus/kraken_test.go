@@ -0,0 +1,55 @@
+package pricebus_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/krakenclient"
+)
+
+func TestKrakenSource_Quote(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/0/public/Ticker", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "XBTUSD", req.URL.Query().Get("pair"))
+
+		body := `{"error":[],"result":{"XXBTZUSD":{"c":["50000.10000","0.001"],"v":["10.5","42.25"],"o":"49000.0"}}}`
+
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(body))
+		require.NoError(t, err)
+	})
+
+	source := pricebus.NewKrakenSource(krakenclient.NewClient(url))
+
+	result, err := source.Quote(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "kraken", source.Name())
+	assert.Equal(t, "BTC", result.Symbol)
+	assert.Equal(t, "50000.1", result.Price.String())
+	assert.Equal(t, "42.25", result.Volume.String())
+	assert.NotEmpty(t, result.Timestamp)
+}
+
+func TestKrakenSource_Quote_APIErr(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/0/public/Ticker", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"error":["EQuery:Unknown asset pair"]}`))
+		require.NoError(t, err)
+	})
+
+	source := pricebus.NewKrakenSource(krakenclient.NewClient(url))
+
+	_, err := source.Quote(t.Context(), "FOO")
+
+	assert.EqualError(t, err, "failed to fetch ticker: EQuery:Unknown asset pair")
+}
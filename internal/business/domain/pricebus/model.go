@@ -1,16 +1,65 @@
 package pricebus
 
 import (
+	"fmt"
+	"math"
 	"time"
 
+	"github.com/shopspring/decimal"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coinbaseclient"
 	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/krakenclient"
+)
+
+// quoteCurrency is the currency all prices are quoted in.
+const quoteCurrency = "USD"
+
+type (
+	// Price represents the price data structure.
+	Price struct {
+		Symbol      string
+		Timestamp   string
+		Price       decimal.Decimal
+		Volume      decimal.Decimal // 24h volume in the base asset, zero when unknown
+		Sources     []Quote         // quotes that contributed to an aggregated price
+		Aggregation string          // method an aggregated price was computed with, AggregationMedian or AggregationVWAP
+		Market      Market
+	}
+
+	// Market holds the market data reported along with a price. Fields are invalid when unknown.
+	Market struct {
+		MarketCap         decimal.NullDecimal
+		Volume24h         decimal.NullDecimal // 24h volume in USD
+		Change24h         decimal.NullDecimal
+		ChangePct24h      decimal.NullDecimal
+		CirculatingSupply decimal.NullDecimal
+	}
+
+	// Quote represents the price reported by a single source.
+	Quote struct {
+		Source    string
+		Timestamp string
+		Price     decimal.Decimal
+		Volume    decimal.Decimal
+		Market    Market
+	}
 )
 
-// Price represents the price data structure.
-type Price struct {
-	Symbol    string
-	Timestamp string
-	Price     float64
+// Known reports whether any market field is set.
+func (m Market) Known() bool {
+	return m.MarketCap.Valid || m.Volume24h.Valid || m.Change24h.Valid || m.ChangePct24h.Valid ||
+		m.CirculatingSupply.Valid
+}
+
+// deviationPct returns how far value is from reference, in percent.
+// Only used for comparisons with configured thresholds, so it does not need to be exact.
+func deviationPct(value, reference decimal.Decimal) float64 {
+	if reference.IsZero() {
+		return math.Inf(1)
+	}
+
+	return value.Sub(reference).Abs().Div(reference).Mul(decimal.NewFromInt(100)).InexactFloat64()
 }
 
 func toBusPrice(asset coindeskclient.Asset) Price {
@@ -20,5 +69,56 @@ func toBusPrice(asset coindeskclient.Asset) Price {
 		Symbol:    asset.Symbol,
 		Timestamp: ts.Format(time.RFC3339),
 		Price:     asset.Price,
+		Market: Market{
+			MarketCap:         asset.MarketCap,
+			Volume24h:         asset.Volume24h,
+			Change24h:         asset.Change24h,
+			ChangePct24h:      asset.ChangePct24h,
+			CirculatingSupply: asset.CirculatingSupply,
+		},
+	}
+}
+
+// spotToBusPrice converts a Coinbase spot price. Coinbase does not report when the
+// price was last updated, so the time of the request is used instead.
+func spotToBusPrice(symbol string, spot coinbaseclient.Spot) (Price, error) {
+	price, err := decimal.NewFromString(spot.Data.Amount)
+	if err != nil {
+		return Price{}, fmt.Errorf("invalid spot price %q: %v", spot.Data.Amount, err)
+	}
+
+	return Price{
+		Symbol:    symbol,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Price:     price,
+	}, nil
+}
+
+// tickerToBusPrice converts a Kraken ticker using its last trade price. Kraken does not
+// report when the ticker was last updated, so the time of the request is used instead.
+func tickerToBusPrice(symbol string, ticker krakenclient.Ticker) (Price, error) {
+	if len(ticker.LastTrade) == 0 {
+		return Price{}, fmt.Errorf("no last trade found for pair %s", ticker.Pair)
 	}
+
+	price, err := decimal.NewFromString(ticker.LastTrade[0])
+	if err != nil {
+		return Price{}, fmt.Errorf("invalid last trade price %q: %v", ticker.LastTrade[0], err)
+	}
+
+	var volume decimal.Decimal
+
+	if len(ticker.Volume) > 1 {
+		volume, err = decimal.NewFromString(ticker.Volume[1])
+		if err != nil {
+			return Price{}, fmt.Errorf("invalid 24h volume %q: %v", ticker.Volume[1], err)
+		}
+	}
+
+	return Price{
+		Symbol:    symbol,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Price:     price,
+		Volume:    volume,
+	}, nil
 }
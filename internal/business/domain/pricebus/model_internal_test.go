@@ -3,6 +3,7 @@ package pricebus
 import (
 	"testing"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
@@ -11,7 +12,7 @@ import (
 func TestToBusPrice(t *testing.T) {
 	asset := coindeskclient.Asset{
 		Symbol:             "BTC",
-		Price:              50000.0,
+		Price:              decimal.RequireFromString("50000"),
 		PriceLastUpdatedAt: 1633072800,
 	}
 
@@ -19,7 +20,26 @@ func TestToBusPrice(t *testing.T) {
 
 	assert.Equal(t, Price{
 		Symbol:    "BTC",
-		Price:     50000.0,
+		Price:     decimal.RequireFromString("50000"),
 		Timestamp: "2021-10-01T07:20:00Z",
 	}, price)
 }
+
+func TestToBusPrice_Market(t *testing.T) {
+	asset := coindeskclient.Asset{
+		Symbol:             "BTC",
+		Price:              decimal.RequireFromString("50000"),
+		PriceLastUpdatedAt: 1633072800,
+		MarketCap:          decimal.NewNullDecimal(decimal.RequireFromString("990000000000")),
+		Change24h:          decimal.NewNullDecimal(decimal.RequireFromString("-1250.5")),
+	}
+
+	price := toBusPrice(asset)
+
+	assert.True(t, price.Market.Known())
+	assert.Equal(t, "990000000000", price.Market.MarketCap.Decimal.String())
+	assert.Equal(t, "-1250.5", price.Market.Change24h.Decimal.String())
+	assert.False(t, price.Market.Volume24h.Valid)
+	assert.False(t, price.Market.ChangePct24h.Valid)
+	assert.False(t, price.Market.CirculatingSupply.Valid)
+}
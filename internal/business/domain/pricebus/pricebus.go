@@ -2,61 +2,105 @@ package pricebus
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
-	"github.com/gandarez/btc-price-service/internal/business/sdk/page"
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
+	"github.com/gandarez/btc-price-service/internal/foundation/log"
 )
 
 type (
 	// Business represents the business logic for the price domain.
 	Business struct {
-		coindeskcli CoinDeskClient
+		source    PriceSource
+		streamer  Streamer
+		validator *Validator
+		breaker   *breaker.Breaker
 	}
 
-	// CoinDeskClient defines the interface for fetching top asset prices from the CoinDesk API.
-	CoinDeskClient interface {
-		TopList(context.Context, page.Page) (coindeskclient.Result, error)
+	// PriceSource defines the interface implemented by every upstream price provider.
+	PriceSource interface {
+		// Name returns the name of the provider.
+		Name() string
+		// Quote retrieves the current price of the given asset symbol.
+		Quote(ctx context.Context, symbol string) (Price, error)
 	}
 )
 
 // NewBusiness creates a new instance of the Business struct.
-func NewBusiness(coindeskcli CoinDeskClient) *Business {
-	return &Business{
-		coindeskcli: coindeskcli,
+// Calls to the price source are guarded by a circuit breaker configured with cfg.
+func NewBusiness(source PriceSource, cfg breaker.Config, opts ...Option) *Business {
+	b := &Business{
+		source:  source,
+		breaker: breaker.New(cfg),
 	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
 }
 
-// AssetPrice retrieves the current asset price from the CoinDesk API.
-func (b *Business) AssetPrice(ctx context.Context, symbol string, pagination page.Page) (Price, error) {
-	result, err := b.coindeskcli.TopList(ctx, pagination)
-	if err != nil {
-		return Price{}, fmt.Errorf("failed to fetch top list: %v", err)
+// AssetPrice retrieves the current asset price from the configured price source.
+// It returns breaker.ErrOpen without calling the source while the circuit is open,
+// and an error wrapping ErrRejected when the price fails validation.
+// An exhausted call budget or a canceled call does not count as a failure of the source.
+func (b *Business) AssetPrice(ctx context.Context, symbol string) (Price, error) {
+	if err := b.breaker.Allow(); err != nil {
+		return Price{}, fmt.Errorf("failed to fetch price from %s: %w", b.source.Name(), err)
 	}
 
-	if result.Error != "" {
-		return Price{}, fmt.Errorf("failed to fetch top list: %s", result.Error)
+	price, err := b.source.Quote(ctx, symbol)
+
+	switch {
+	case err == nil:
+		b.breaker.Success()
+	case errors.Is(err, coindeskclient.ErrBudgetExhausted), errors.Is(err, context.Canceled):
+		b.breaker.Skip()
+	default:
+		b.breaker.Failure()
 	}
 
-	if len(result.TopList.Data.Assets) == 0 {
-		return Price{}, fmt.Errorf("no assets found for page %d", pagination.Number())
+	if err != nil {
+		return Price{}, fmt.Errorf("failed to fetch price from %s: %w", b.source.Name(), err)
 	}
 
-	for _, asset := range result.TopList.Data.Assets {
-		if asset.Symbol == symbol {
-			return toBusPrice(asset), nil
-		}
+	if err := b.validate(ctx, price); err != nil {
+		return Price{}, err
 	}
 
-	// recursively search through pages until we find the BTC asset
-	if result.TopList.Data.Stats.Page*result.TopList.Data.Stats.PageSize < result.TopList.Data.Stats.TotalAssets {
-		nextPage, err := page.New(pagination.Number()+1, pagination.RowsPerPage())
-		if err != nil {
-			return Price{}, err
+	return price, nil
+}
+
+// Rejections returns the ticks rejected by the validator. It is empty when no validator is configured.
+func (b *Business) Rejections() Rejections {
+	if b.validator == nil {
+		return Rejections{
+			Counts: map[string]int{},
+			Ticks:  []Rejection{},
 		}
+	}
+
+	return b.validator.Rejections()
+}
 
-		return b.AssetPrice(ctx, symbol, nextPage)
+// validate runs the price through the validator, if any, logging the reason of a rejection.
+func (b *Business) validate(ctx context.Context, price Price) error {
+	if b.validator == nil {
+		return nil
 	}
 
-	return Price{}, fmt.Errorf("asset %s not found in top list", symbol)
+	if err := b.validator.Validate(price); err != nil {
+		log.Extract(ctx).Warnf("rejected %s tick: %v", price.Symbol, err)
+		return err
+	}
+
+	return nil
+}
+
+// UpstreamState returns the state of the circuit breaker guarding the price source.
+func (b *Business) UpstreamState() breaker.State {
+	return b.breaker.State()
 }
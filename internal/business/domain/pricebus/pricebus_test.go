@@ -3,167 +3,122 @@ package pricebus_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
 	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
-	"github.com/gandarez/btc-price-service/internal/business/sdk/page"
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
 )
 
 func TestBusiness_AssetPrice(t *testing.T) {
-	mockCoinDeskClient := &mockCoinDeskClient{
-		TopListFn: func(_ context.Context, _ page.Page) (coindeskclient.Result, error) {
-			return coindeskclient.Result{
-				TopList: coindeskclient.TopList{
-					Data: coindeskclient.Data{
-						Stats: coindeskclient.Stats{
-							Page:        1,
-							PageSize:    10,
-							TotalAssets: 1,
-						},
-						Assets: []coindeskclient.Asset{
-							{Symbol: "BTC", Price: 50000.0, PriceLastUpdatedAt: 1633072800},
-						},
-					},
-				},
+	source := &mockPriceSource{
+		SourceName: "mock",
+		QuoteFn: func(_ context.Context, symbol string) (pricebus.Price, error) {
+			return pricebus.Price{
+				Symbol:    symbol,
+				Timestamp: "2021-10-01T07:20:00Z",
+				Price:     decimal.RequireFromString("50000"),
 			}, nil
 		},
 	}
 
-	bus := pricebus.NewBusiness(mockCoinDeskClient)
-
-	page, err := page.New(1, 10)
-	require.NoError(t, err)
+	bus := pricebus.NewBusiness(source, breaker.Config{})
 
-	result, err := bus.AssetPrice(t.Context(), "BTC", page)
+	result, err := bus.AssetPrice(t.Context(), "BTC")
 	require.NoError(t, err)
 
 	assert.Equal(t, pricebus.Price{
 		Symbol:    "BTC",
 		Timestamp: "2021-10-01T07:20:00Z",
-		Price:     50000.0,
+		Price:     decimal.RequireFromString("50000"),
 	}, result)
-	assert.Equal(t, 1, mockCoinDeskClient.TopListFnCount)
+	assert.Equal(t, 1, source.QuoteFnCount)
 }
 
-func TestBusiness_AssetPrice_Recursive(t *testing.T) {
-	mockCoinDeskClient := &mockCoinDeskClient{
-		TopListFn: func(_ context.Context, p page.Page) (coindeskclient.Result, error) {
-			if p.Number() == 1 {
-				return coindeskclient.Result{
-					TopList: coindeskclient.TopList{
-						Data: coindeskclient.Data{
-							Stats: coindeskclient.Stats{
-								Page:        1,
-								PageSize:    1,
-								TotalAssets: 2,
-							},
-							Assets: []coindeskclient.Asset{
-								{Symbol: "ETH", Price: 3000.0, PriceLastUpdatedAt: 1633072800},
-							},
-						},
-					},
-				}, nil
-			}
-
-			return coindeskclient.Result{
-				TopList: coindeskclient.TopList{
-					Data: coindeskclient.Data{
-						Stats: coindeskclient.Stats{
-							Page:        2,
-							PageSize:    1,
-							TotalAssets: 1,
-						},
-						Assets: []coindeskclient.Asset{
-							{Symbol: "BTC", Price: 50000.0, PriceLastUpdatedAt: 1633072800},
-						},
-					},
-				},
-			}, nil
+func TestBusiness_AssetPrice_Err(t *testing.T) {
+	source := &mockPriceSource{
+		SourceName: "mock",
+		QuoteFn: func(_ context.Context, _ string) (pricebus.Price, error) {
+			return pricebus.Price{}, errors.New("fail")
 		},
 	}
 
-	bus := pricebus.NewBusiness(mockCoinDeskClient)
+	bus := pricebus.NewBusiness(source, breaker.Config{})
 
-	page, err := page.New(1, 1)
-	require.NoError(t, err)
+	_, err := bus.AssetPrice(t.Context(), "BTC")
 
-	result, err := bus.AssetPrice(t.Context(), "BTC", page)
-	require.NoError(t, err)
-
-	assert.Equal(t, pricebus.Price{
-		Symbol:    "BTC",
-		Timestamp: "2021-10-01T07:20:00Z",
-		Price:     50000.0,
-	}, result)
-	assert.Equal(t, 2, mockCoinDeskClient.TopListFnCount)
+	assert.EqualError(t, err, "failed to fetch price from mock: fail")
+	assert.Equal(t, 1, source.QuoteFnCount)
 }
 
-func TestBusiness_AssetPrice_Err(t *testing.T) {
-	mockCoinDeskClient := &mockCoinDeskClient{
-		TopListFn: func(_ context.Context, _ page.Page) (coindeskclient.Result, error) {
-			return coindeskclient.Result{}, errors.New("fail")
+func TestBusiness_AssetPrice_CircuitBreaker(t *testing.T) {
+	source := &mockPriceSource{
+		SourceName: "mock",
+		QuoteFn: func(_ context.Context, _ string) (pricebus.Price, error) {
+			return pricebus.Price{}, errors.New("fail")
 		},
 	}
 
-	bus := pricebus.NewBusiness(mockCoinDeskClient)
+	bus := pricebus.NewBusiness(source, breaker.Config{FailureThreshold: 2, CoolDown: time.Hour})
 
-	page, err := page.New(1, 10)
-	require.NoError(t, err)
-
-	_, err = bus.AssetPrice(t.Context(), "BTC", page)
-
-	assert.EqualError(t, err, "failed to fetch top list: fail")
-	assert.Equal(t, 1, mockCoinDeskClient.TopListFnCount)
-}
-
-func TestBusiness_AssetPrice_APIErr(t *testing.T) {
-	mockCoinDeskClient := &mockCoinDeskClient{
-		TopListFn: func(_ context.Context, _ page.Page) (coindeskclient.Result, error) {
-			return coindeskclient.Result{
-				Error: "bad request",
-			}, nil
-		},
+	for range 2 {
+		_, err := bus.AssetPrice(t.Context(), "BTC")
+		assert.EqualError(t, err, "failed to fetch price from mock: fail")
 	}
 
-	bus := pricebus.NewBusiness(mockCoinDeskClient)
-
-	page, err := page.New(1, 10)
-	require.NoError(t, err)
+	assert.Equal(t, breaker.StateOpen, bus.UpstreamState())
 
-	_, err = bus.AssetPrice(t.Context(), "BTC", page)
+	_, err := bus.AssetPrice(t.Context(), "BTC")
 
-	assert.EqualError(t, err, "failed to fetch top list: bad request")
-	assert.Equal(t, 1, mockCoinDeskClient.TopListFnCount)
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	assert.Equal(t, 2, source.QuoteFnCount)
 }
 
-func TestBusiness_AssetPrice_NoAssets(t *testing.T) {
-	mockCoinDeskClient := &mockCoinDeskClient{
-		TopListFn: func(_ context.Context, _ page.Page) (coindeskclient.Result, error) {
-			return coindeskclient.Result{}, nil
-		},
+func TestBusiness_AssetPrice_CircuitBreakerSkipsLocalErrors(t *testing.T) {
+	tests := map[string]error{
+		"budget exhausted": coindeskclient.ErrBudgetExhausted,
+		"canceled":         context.Canceled,
 	}
 
-	bus := pricebus.NewBusiness(mockCoinDeskClient)
+	for name, quoteErr := range tests {
+		t.Run(name, func(t *testing.T) {
+			source := &mockPriceSource{
+				SourceName: "mock",
+				QuoteFn: func(_ context.Context, _ string) (pricebus.Price, error) {
+					return pricebus.Price{}, fmt.Errorf("failed to fetch asset BTC by symbol: %w", quoteErr)
+				},
+			}
 
-	page, err := page.New(1, 10)
-	require.NoError(t, err)
+			bus := pricebus.NewBusiness(source, breaker.Config{FailureThreshold: 1, CoolDown: time.Hour})
 
-	_, err = bus.AssetPrice(t.Context(), "BTC", page)
+			for range 3 {
+				_, err := bus.AssetPrice(t.Context(), "BTC")
+				assert.ErrorIs(t, err, quoteErr)
+			}
+
+			assert.Equal(t, breaker.StateClosed, bus.UpstreamState())
+			assert.Equal(t, 3, source.QuoteFnCount)
+		})
+	}
+}
 
-	assert.EqualError(t, err, "no assets found for page 1")
-	assert.Equal(t, 1, mockCoinDeskClient.TopListFnCount)
+type mockPriceSource struct {
+	SourceName   string
+	QuoteFn      func(ctx context.Context, symbol string) (pricebus.Price, error)
+	QuoteFnCount int
 }
 
-type mockCoinDeskClient struct {
-	TopListFn      func(ctx context.Context, page page.Page) (coindeskclient.Result, error)
-	TopListFnCount int
+func (m *mockPriceSource) Name() string {
+	return m.SourceName
 }
 
-func (m *mockCoinDeskClient) TopList(ctx context.Context, p page.Page) (coindeskclient.Result, error) {
-	m.TopListFnCount++
-	return m.TopListFn(ctx, p)
+func (m *mockPriceSource) Quote(ctx context.Context, symbol string) (pricebus.Price, error) {
+	m.QuoteFnCount++
+	return m.QuoteFn(ctx, symbol)
 }
@@ -0,0 +1,106 @@
+package pricebus
+
+import (
+	"time"
+)
+
+type (
+	// RateLimiter is implemented by price sources whose upstream limits the number of calls.
+	RateLimiter interface {
+		// RateLimit returns the remaining quota of calls to the upstream.
+		RateLimit() RateLimit
+	}
+
+	// RateLimit represents the remaining quota of calls to an upstream. A zero value means no limit is known.
+	RateLimit struct {
+		Remaining int
+		Reset     time.Time
+	}
+)
+
+// Known reports whether the rate limit holds any information.
+func (r RateLimit) Known() bool {
+	return !r.Reset.IsZero()
+}
+
+// Exhausted reports whether no calls are left until the limit resets.
+func (r RateLimit) Exhausted(now time.Time) bool {
+	return r.Known() && r.Remaining <= 0 && r.Reset.After(now)
+}
+
+// Interval returns the average time between calls that keeps within the limit until it resets.
+func (r RateLimit) Interval(now time.Time) time.Duration {
+	if !r.Known() {
+		return 0
+	}
+
+	return max(r.Reset.Sub(now), 0) / time.Duration(max(r.Remaining, 1))
+}
+
+// RateLimit implements RateLimiter interface.
+func (s *CoinDeskSource) RateLimit() RateLimit {
+	rateLimit := s.coindeskcli.RateLimit()
+
+	return RateLimit{
+		Remaining: rateLimit.Remaining,
+		Reset:     rateLimit.Reset,
+	}
+}
+
+// RateLimit implements RateLimiter interface.
+// The rate-limited sources are called on their own cadence, so the polls only slow down when every source is
+// limited, to the pace of the least constrained one, and pause until the earliest reset once all are exhausted.
+func (a *Aggregator) RateLimit() RateLimit {
+	now := time.Now()
+
+	var (
+		fastest   RateLimit
+		exhausted RateLimit
+	)
+
+	for _, source := range a.sources {
+		limiter, ok := source.(RateLimiter)
+		if !ok {
+			// an unlimited source can always be polled
+			return RateLimit{}
+		}
+
+		rateLimit := limiter.RateLimit()
+
+		switch {
+		case !rateLimit.Known():
+			return RateLimit{}
+		case rateLimit.Exhausted(now):
+			if !exhausted.Known() || rateLimit.Reset.Before(exhausted.Reset) {
+				exhausted = rateLimit
+			}
+		case !fastest.Known() || rateLimit.Interval(now) < fastest.Interval(now):
+			fastest = rateLimit
+		}
+	}
+
+	if fastest.Known() {
+		return fastest
+	}
+
+	return exhausted
+}
+
+// PollDelay returns how long to wait before polling the given number of symbols again.
+// It is never shorter than interval, is stretched to spread the remaining quota of the source until it resets,
+// and pauses polling until the reset once the quota is exhausted.
+func (b *Business) PollDelay(interval time.Duration, symbols int) time.Duration {
+	limiter, ok := b.source.(RateLimiter)
+	if !ok {
+		return interval
+	}
+
+	now := time.Now()
+	rateLimit := limiter.RateLimit()
+
+	if rateLimit.Exhausted(now) {
+		return max(rateLimit.Reset.Sub(now), interval)
+	}
+
+	return max(rateLimit.Interval(now)*time.Duration(max(symbols, 1)), interval)
+}
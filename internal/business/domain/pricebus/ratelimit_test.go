@@ -0,0 +1,139 @@
+package pricebus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
+)
+
+func TestBusiness_PollDelay(t *testing.T) {
+	tests := map[string]struct {
+		rateLimit coindeskclient.RateLimit
+		min       time.Duration
+		max       time.Duration
+	}{
+		"unknown": {
+			min: 5 * time.Second,
+			max: 5 * time.Second,
+		},
+		"plenty left": {
+			rateLimit: coindeskclient.RateLimit{Remaining: 1000, Reset: time.Now().Add(time.Minute)},
+			min:       5 * time.Second,
+			max:       5 * time.Second,
+		},
+		"approaching limit": {
+			rateLimit: coindeskclient.RateLimit{Remaining: 4, Reset: time.Now().Add(time.Minute)},
+			min:       29 * time.Second,
+			max:       30 * time.Second,
+		},
+		"exhausted": {
+			rateLimit: coindeskclient.RateLimit{Remaining: 0, Reset: time.Now().Add(time.Hour)},
+			min:       59 * time.Minute,
+			max:       time.Hour,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			source := pricebus.NewCoinDeskSource(&mockCoinDeskClient{
+				RateLimitFn: func() coindeskclient.RateLimit {
+					return test.rateLimit
+				},
+			})
+
+			b := pricebus.NewBusiness(source, breaker.Config{})
+
+			delay := b.PollDelay(5*time.Second, 2)
+
+			assert.GreaterOrEqual(t, delay, test.min)
+			assert.LessOrEqual(t, delay, test.max)
+		})
+	}
+}
+
+func TestAggregator_RateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+
+	limited := func(remaining int) *pricebus.CoinDeskSource {
+		return pricebus.NewCoinDeskSource(&mockCoinDeskClient{
+			RateLimitFn: func() coindeskclient.RateLimit {
+				return coindeskclient.RateLimit{Remaining: remaining, Reset: reset}
+			},
+		})
+	}
+
+	cfg := pricebus.AggregatorConfig{Method: pricebus.AggregationMedian}
+
+	// an exhausted source is skipped while another one can be polled
+	a, err := pricebus.NewAggregator(cfg, limited(0), limited(10))
+	require.NoError(t, err)
+
+	assert.Equal(t, pricebus.RateLimit{Remaining: 10, Reset: reset}, a.RateLimit())
+
+	// polling pauses once every source is exhausted
+	a, err = pricebus.NewAggregator(cfg, limited(0), limited(0))
+	require.NoError(t, err)
+
+	assert.True(t, a.RateLimit().Exhausted(time.Now()))
+
+	// polls follow the least constrained source, the others are called on their own cadence
+	a, err = pricebus.NewAggregator(cfg, limited(5), limited(10))
+	require.NoError(t, err)
+
+	assert.Equal(t, pricebus.RateLimit{Remaining: 10, Reset: reset}, a.RateLimit())
+
+	// a source without limits can always be polled
+	a, err = pricebus.NewAggregator(cfg, limited(0), staticSource("a", "1", "", ""))
+	require.NoError(t, err)
+
+	assert.False(t, a.RateLimit().Known())
+}
+
+func TestAggregator_Quote_RateLimitedSource(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	// a single call left for the hour
+	client := &mockCoinDeskClient{
+		AssetBySymbolFn: func(_ context.Context, symbol string) (coindeskclient.AssetResult, error) {
+			return coindeskclient.AssetResult{
+				Asset: coindeskclient.Asset{
+					Symbol:             symbol,
+					Price:              decimal.RequireFromString("100"),
+					PriceLastUpdatedAt: time.Now().Unix(),
+				},
+			}, nil
+		},
+		RateLimitFn: func() coindeskclient.RateLimit {
+			return coindeskclient.RateLimit{Remaining: 1, Reset: time.Now().Add(time.Hour)}
+		},
+	}
+
+	unlimited := staticSource("a", "102", "", now)
+
+	agg, err := pricebus.NewAggregator(
+		pricebus.AggregatorConfig{Method: pricebus.AggregationMedian, MinSources: 2},
+		pricebus.NewCoinDeskSource(client),
+		unlimited,
+	)
+	require.NoError(t, err)
+
+	for range 3 {
+		result, err := agg.Quote(t.Context(), "BTC")
+		require.NoError(t, err)
+
+		// the last quote of the rate-limited source keeps contributing until it is due again
+		assert.Equal(t, "101", result.Price.String())
+		assert.Len(t, result.Sources, 2)
+	}
+
+	assert.Equal(t, 1, client.AssetBySymbolFnCount)
+	assert.Equal(t, 3, unlimited.QuoteFnCount)
+}
@@ -0,0 +1,104 @@
+package pricebus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+	"github.com/gandarez/btc-price-service/internal/foundation/log"
+)
+
+// ErrNoStreamer is returned when prices are streamed without a configured Streamer.
+var ErrNoStreamer = errors.New("no price streamer configured")
+
+type (
+	// Streamer defines the interface implemented by upstream providers pushing prices.
+	Streamer interface {
+		// Stream delivers the prices of the given symbols to onPrice until ctx is done,
+		// and reports whether the upstream is connected to onState.
+		Stream(ctx context.Context, symbols []string, onPrice func(Price), onState func(connected bool)) error
+	}
+
+	// CoinDeskStreamer is the Streamer backed by the CoinDesk WebSocket API.
+	CoinDeskStreamer struct {
+		stream CoinDeskStream
+	}
+
+	// CoinDeskStream defines the interface for receiving ticks from the CoinDesk WebSocket API.
+	CoinDeskStream interface {
+		Run(ctx context.Context, instruments []string, handler coindeskclient.StreamHandler) error
+	}
+
+	// Option configures the Business.
+	Option func(*Business)
+)
+
+// WithStreamer sets the streamer used by StreamPrices.
+func WithStreamer(streamer Streamer) Option {
+	return func(b *Business) {
+		b.streamer = streamer
+	}
+}
+
+// NewCoinDeskStreamer creates a new instance of the CoinDeskStreamer struct.
+func NewCoinDeskStreamer(stream CoinDeskStream) *CoinDeskStreamer {
+	return &CoinDeskStreamer{
+		stream: stream,
+	}
+}
+
+// Stream implements Streamer interface.
+func (s *CoinDeskStreamer) Stream(
+	ctx context.Context,
+	symbols []string,
+	onPrice func(Price),
+	onState func(connected bool),
+) error {
+	logger := log.Extract(ctx)
+
+	instruments := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		instruments = append(instruments, coindeskclient.InstrumentFor(symbol))
+	}
+
+	return s.stream.Run(ctx, instruments, coindeskclient.StreamHandler{
+		OnTick: func(tick coindeskclient.Tick) {
+			onPrice(tickToBusPrice(tick))
+		},
+		OnState: onState,
+		OnError: func(err error) {
+			logger.Warnf("coindesk stream disconnected: %v", err)
+		},
+	})
+}
+
+// StreamPrices delivers the prices pushed by the configured streamer until ctx is done.
+// Prices failing validation are dropped. It returns ErrNoStreamer when none is configured.
+func (b *Business) StreamPrices(
+	ctx context.Context,
+	symbols []string,
+	onPrice func(Price),
+	onState func(connected bool),
+) error {
+	if b.streamer == nil {
+		return ErrNoStreamer
+	}
+
+	return b.streamer.Stream(ctx, symbols, func(price Price) {
+		if err := b.validate(ctx, price); err != nil {
+			return
+		}
+
+		onPrice(price)
+	}, onState)
+}
+
+func tickToBusPrice(tick coindeskclient.Tick) Price {
+	return Price{
+		Symbol:    strings.TrimSuffix(tick.Instrument, "-"+quoteCurrency),
+		Timestamp: time.Unix(tick.ValueLastUpdate, 0).UTC().Format(time.RFC3339),
+		Price:     tick.Value,
+	}
+}
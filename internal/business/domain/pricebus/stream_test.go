@@ -0,0 +1,74 @@
+package pricebus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
+)
+
+func TestBusiness_StreamPrices(t *testing.T) {
+	stream := &mockCoinDeskStream{
+		RunFn: func(_ context.Context, instruments []string, handler coindeskclient.StreamHandler) error {
+			assert.Equal(t, []string{"BTC-USD", "ETH-USD"}, instruments)
+
+			handler.OnState(true)
+			handler.OnTick(coindeskclient.Tick{
+				Type:            coindeskclient.MessageTypeTick,
+				Instrument:      "ETH-USD",
+				Value:           decimal.RequireFromString("3000.5"),
+				ValueLastUpdate: 1633072800,
+			})
+			handler.OnState(false)
+
+			return context.Canceled
+		},
+	}
+
+	b := pricebus.NewBusiness(nil, breaker.Config{}, pricebus.WithStreamer(pricebus.NewCoinDeskStreamer(stream)))
+
+	var (
+		prices []pricebus.Price
+		states []bool
+	)
+
+	err := b.StreamPrices(t.Context(), []string{"BTC", "ETH"},
+		func(price pricebus.Price) {
+			prices = append(prices, price)
+		},
+		func(connected bool) {
+			states = append(states, connected)
+		},
+	)
+	require.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, []pricebus.Price{
+		{
+			Symbol:    "ETH",
+			Timestamp: "2021-10-01T07:20:00Z",
+			Price:     decimal.RequireFromString("3000.5"),
+		},
+	}, prices)
+	assert.Equal(t, []bool{true, false}, states)
+}
+
+func TestBusiness_StreamPrices_NoStreamer(t *testing.T) {
+	b := pricebus.NewBusiness(nil, breaker.Config{})
+
+	err := b.StreamPrices(t.Context(), []string{"BTC"}, func(pricebus.Price) {}, func(bool) {})
+	assert.ErrorIs(t, err, pricebus.ErrNoStreamer)
+}
+
+type mockCoinDeskStream struct {
+	RunFn func(ctx context.Context, instruments []string, handler coindeskclient.StreamHandler) error
+}
+
+func (m *mockCoinDeskStream) Run(ctx context.Context, instruments []string, handler coindeskclient.StreamHandler) error {
+	return m.RunFn(ctx, instruments, handler)
+}
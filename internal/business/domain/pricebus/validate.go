@@ -0,0 +1,193 @@
+package pricebus
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Reasons a tick is rejected for.
+const (
+	ReasonNonPositivePrice = "non_positive_price"
+	ReasonInvalidTimestamp = "invalid_timestamp"
+	ReasonOutOfOrder       = "out_of_order"
+	ReasonFutureTimestamp  = "future_timestamp"
+	ReasonPriceJump        = "price_jump"
+)
+
+// ErrRejected is wrapped by the errors returned for ticks failing validation.
+var ErrRejected = errors.New("tick rejected")
+
+type (
+	// Validator rejects ticks with implausible prices or timestamps before they reach any client.
+	// Rejected ticks are counted and the most recent ones are kept for inspection.
+	Validator struct {
+		mu       sync.Mutex
+		cfg      ValidatorConfig
+		symbols  map[string]*symbolState
+		counts   map[string]int
+		rejected []Rejection
+		now      func() time.Time
+	}
+
+	// ValidatorConfig holds the configuration for the Validator.
+	ValidatorConfig struct {
+		// MaxJump is the maximum change from the last accepted price of a symbol, in percent. Zero disables the check.
+		MaxJump float64
+		// JumpConfirmations is the number of consecutive ticks agreeing on a new level
+		// after which a jump is accepted as a genuine move. Zero never accepts a jump.
+		JumpConfirmations int
+		// MaxFutureSkew is how far in the future a timestamp may be. Zero disables the check.
+		MaxFutureSkew time.Duration
+		// MaxRejected is the number of rejected ticks kept for inspection.
+		MaxRejected int
+	}
+
+	// Rejection represents a tick that failed validation.
+	Rejection struct {
+		Price      Price
+		Reason     string
+		Detail     string
+		RejectedAt time.Time
+	}
+
+	// Rejections holds the rejected ticks kept for inspection, most recent first, and the counts by reason.
+	Rejections struct {
+		Counts map[string]int
+		Ticks  []Rejection
+	}
+
+	// symbolState holds the last accepted tick of a symbol and the pending jump awaiting confirmation.
+	symbolState struct {
+		last      Price
+		lastTime  time.Time
+		pending   decimal.Decimal
+		confirmed int
+	}
+)
+
+// NewValidator creates a new instance of the Validator struct.
+func NewValidator(cfg ValidatorConfig) *Validator {
+	return &Validator{
+		cfg:     cfg,
+		symbols: make(map[string]*symbolState),
+		counts:  make(map[string]int),
+		now:     time.Now,
+	}
+}
+
+// WithValidator sets the validator every price goes through before being returned or streamed.
+func WithValidator(validator *Validator) Option {
+	return func(b *Business) {
+		b.validator = validator
+	}
+}
+
+// Validate checks the tick against the last accepted one of its symbol.
+// It returns an error wrapping ErrRejected when the tick is rejected, and records it.
+func (v *Validator) Validate(price Price) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	reason, detail := v.check(price)
+	if reason == "" {
+		return nil
+	}
+
+	v.counts[reason]++
+
+	if v.cfg.MaxRejected > 0 {
+		if len(v.rejected) >= v.cfg.MaxRejected {
+			v.rejected = v.rejected[1:]
+		}
+
+		v.rejected = append(v.rejected, Rejection{
+			Price:      price,
+			Reason:     reason,
+			Detail:     detail,
+			RejectedAt: v.now().UTC(),
+		})
+	}
+
+	return fmt.Errorf("%w: %s: %s", ErrRejected, reason, detail)
+}
+
+// Rejections returns the rejected ticks kept for inspection and the counts by reason.
+func (v *Validator) Rejections() Rejections {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	counts := make(map[string]int, len(v.counts))
+	for reason, count := range v.counts {
+		counts[reason] = count
+	}
+
+	ticks := make([]Rejection, 0, len(v.rejected))
+	for i := len(v.rejected) - 1; i >= 0; i-- {
+		ticks = append(ticks, v.rejected[i])
+	}
+
+	return Rejections{
+		Counts: counts,
+		Ticks:  ticks,
+	}
+}
+
+// check returns the reason and detail of the rejection, or an empty reason when the tick is accepted.
+func (v *Validator) check(price Price) (string, string) {
+	if price.Price.Sign() <= 0 {
+		return ReasonNonPositivePrice, fmt.Sprintf("price %s is not positive", price.Price)
+	}
+
+	ts, err := time.Parse(time.RFC3339, price.Timestamp)
+	if err != nil {
+		return ReasonInvalidTimestamp, fmt.Sprintf("timestamp %q is not RFC3339", price.Timestamp)
+	}
+
+	if v.cfg.MaxFutureSkew > 0 && ts.After(v.now().Add(v.cfg.MaxFutureSkew)) {
+		return ReasonFutureTimestamp, fmt.Sprintf("timestamp %s is more than %s in the future",
+			price.Timestamp, v.cfg.MaxFutureSkew)
+	}
+
+	state, ok := v.symbols[price.Symbol]
+	if !ok {
+		v.symbols[price.Symbol] = &symbolState{last: price, lastTime: ts}
+		return "", ""
+	}
+
+	if ts.Before(state.lastTime) {
+		return ReasonOutOfOrder, fmt.Sprintf("timestamp %s is older than the last tick at %s",
+			price.Timestamp, state.last.Timestamp)
+	}
+
+	if v.cfg.MaxJump > 0 {
+		jump := deviationPct(price.Price, state.last.Price)
+		if jump > v.cfg.MaxJump && !state.confirm(price.Price, v.cfg) {
+			return ReasonPriceJump, fmt.Sprintf("price %s jumped %.2f%% from %s", price.Price, jump, state.last.Price)
+		}
+	}
+
+	state.last = price
+	state.lastTime = ts
+	state.pending = decimal.Decimal{}
+	state.confirmed = 0
+
+	return "", ""
+}
+
+// confirm records a tick jumping away from the last accepted price,
+// and reports whether enough consecutive ticks agree on the new level to accept it.
+func (s *symbolState) confirm(price decimal.Decimal, cfg ValidatorConfig) bool {
+	if s.confirmed > 0 && deviationPct(price, s.pending) <= cfg.MaxJump {
+		s.confirmed++
+	} else {
+		s.confirmed = 1
+	}
+
+	s.pending = price
+
+	return cfg.JumpConfirmations > 0 && s.confirmed >= cfg.JumpConfirmations
+}
@@ -0,0 +1,152 @@
+package pricebus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/pricebus"
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	future := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+
+	tests := map[string]struct {
+		price  pricebus.Price
+		reason string
+	}{
+		"zero price": {
+			price:  btcTick("2021-10-01T07:20:10Z", "0"),
+			reason: pricebus.ReasonNonPositivePrice,
+		},
+		"negative price": {
+			price:  btcTick("2021-10-01T07:20:10Z", "-1"),
+			reason: pricebus.ReasonNonPositivePrice,
+		},
+		"invalid timestamp": {
+			price:  btcTick("yesterday", "50000"),
+			reason: pricebus.ReasonInvalidTimestamp,
+		},
+		"older than last tick": {
+			price:  btcTick("2021-10-01T07:19:59Z", "50000"),
+			reason: pricebus.ReasonOutOfOrder,
+		},
+		"far in the future": {
+			price:  pricebus.Price{Symbol: "BTC", Timestamp: future, Price: decimal.RequireFromString("50000")},
+			reason: pricebus.ReasonFutureTimestamp,
+		},
+		"price jump": {
+			price:  btcTick("2021-10-01T07:20:10Z", "75000"),
+			reason: pricebus.ReasonPriceJump,
+		},
+		"same timestamp": {
+			price: btcTick("2021-10-01T07:20:00Z", "50100"),
+		},
+		"small move": {
+			price: btcTick("2021-10-01T07:20:10Z", "52000"),
+		},
+		"other symbol": {
+			price: pricebus.Price{Symbol: "ETH", Timestamp: "2021-10-01T07:19:00Z", Price: decimal.RequireFromString("3000")},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := pricebus.NewValidator(pricebus.ValidatorConfig{
+				MaxJump:       10,
+				MaxFutureSkew: time.Minute,
+				MaxRejected:   10,
+			})
+
+			err := v.Validate(btcTick("2021-10-01T07:20:00Z", "50000"))
+			require.NoError(t, err)
+
+			err = v.Validate(test.price)
+
+			rejections := v.Rejections()
+
+			if test.reason == "" {
+				require.NoError(t, err)
+				assert.Empty(t, rejections.Ticks)
+
+				return
+			}
+
+			require.ErrorIs(t, err, pricebus.ErrRejected)
+			assert.Equal(t, map[string]int{test.reason: 1}, rejections.Counts)
+			require.Len(t, rejections.Ticks, 1)
+			assert.Equal(t, test.price, rejections.Ticks[0].Price)
+			assert.Equal(t, test.reason, rejections.Ticks[0].Reason)
+		})
+	}
+}
+
+func TestValidator_Validate_JumpConfirmed(t *testing.T) {
+	v := pricebus.NewValidator(pricebus.ValidatorConfig{
+		MaxJump:           10,
+		JumpConfirmations: 3,
+		MaxRejected:       10,
+	})
+
+	require.NoError(t, v.Validate(btcTick("2021-10-01T07:20:00Z", "50000")))
+
+	// a single spike is rejected and does not count towards the new level
+	require.ErrorIs(t, v.Validate(btcTick("2021-10-01T07:20:01Z", "90000")), pricebus.ErrRejected)
+
+	// a crash is accepted once confirmed by consecutive ticks
+	require.ErrorIs(t, v.Validate(btcTick("2021-10-01T07:20:02Z", "25000")), pricebus.ErrRejected)
+	require.ErrorIs(t, v.Validate(btcTick("2021-10-01T07:20:03Z", "25100")), pricebus.ErrRejected)
+	require.NoError(t, v.Validate(btcTick("2021-10-01T07:20:04Z", "24900")))
+	require.NoError(t, v.Validate(btcTick("2021-10-01T07:20:05Z", "25000")))
+
+	rejections := v.Rejections()
+
+	assert.Equal(t, map[string]int{pricebus.ReasonPriceJump: 3}, rejections.Counts)
+	require.Len(t, rejections.Ticks, 3)
+	assert.Equal(t, "25100", rejections.Ticks[0].Price.Price.String()) // most recent first
+}
+
+func TestValidator_Rejections_MaxRejected(t *testing.T) {
+	v := pricebus.NewValidator(pricebus.ValidatorConfig{MaxRejected: 2})
+
+	for i := range 3 {
+		err := v.Validate(pricebus.Price{Symbol: "BTC", Timestamp: "2021-10-01T07:20:00Z", Price: decimal.NewFromInt(-int64(i))})
+		require.ErrorIs(t, err, pricebus.ErrRejected)
+	}
+
+	rejections := v.Rejections()
+
+	assert.Equal(t, map[string]int{pricebus.ReasonNonPositivePrice: 3}, rejections.Counts)
+	require.Len(t, rejections.Ticks, 2)
+	assert.Equal(t, "-2", rejections.Ticks[0].Price.Price.String())
+	assert.Equal(t, "-1", rejections.Ticks[1].Price.Price.String())
+}
+
+func TestBusiness_AssetPrice_Rejected(t *testing.T) {
+	source := &mockPriceSource{
+		SourceName: "mock",
+		QuoteFn: func(_ context.Context, symbol string) (pricebus.Price, error) {
+			return pricebus.Price{Symbol: symbol, Timestamp: "2021-10-01T07:20:00Z", Price: decimal.RequireFromString("0")}, nil
+		},
+	}
+
+	validator := pricebus.NewValidator(pricebus.ValidatorConfig{MaxRejected: 10})
+
+	b := pricebus.NewBusiness(source, breaker.Config{FailureThreshold: 1}, pricebus.WithValidator(validator))
+
+	_, err := b.AssetPrice(t.Context(), "BTC")
+	require.ErrorIs(t, err, pricebus.ErrRejected)
+
+	// bad data is not an upstream failure
+	assert.Equal(t, breaker.StateClosed, b.UpstreamState())
+	assert.Len(t, b.Rejections().Ticks, 1)
+}
+
+func btcTick(timestamp, price string) pricebus.Price {
+	return pricebus.Price{Symbol: "BTC", Timestamp: timestamp, Price: decimal.RequireFromString(price)}
+}
@@ -0,0 +1,131 @@
+package webhookbus
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// Names of the events endpoints can subscribe to.
+const (
+	// EventPrice is sent for every published price.
+	EventPrice = "price"
+	// EventStatus is sent when the availability of the upstream changes.
+	EventStatus = "status"
+	// EventCandles is sent with each bar updated by a published price, or completed at the end of its interval.
+	EventCandles = "candles"
+	// EventAlert is sent when an alert rule fires.
+	EventAlert = "alert"
+)
+
+type (
+	// NewEndpoint represents the endpoint to register.
+	NewEndpoint struct {
+		URL     string
+		Secret  string   // signs the deliveries, generated when empty
+		Events  []string // every event but candles when empty
+		Symbols []string // every symbol when empty
+	}
+
+	// Endpoint represents a registered endpoint.
+	Endpoint struct {
+		ID        string
+		URL       string
+		Secret    string
+		Events    []string
+		Symbols   []string
+		CreatedAt time.Time
+	}
+
+	// Event represents an update delivered to the endpoints subscribed to it.
+	Event struct {
+		Name      string
+		Symbol    string // empty for events of every symbol, such as status
+		Data      json.RawMessage
+		Timestamp time.Time
+	}
+
+	// Delivery represents a request queued to be posted to an endpoint, or given up on.
+	Delivery struct {
+		ID            string
+		EndpointID    string // empty for the webhooks of alert rules
+		URL           string
+		Secret        string
+		Event         string
+		Body          json.RawMessage
+		Attempts      int
+		NextAttemptAt time.Time
+		LastError     string
+		CreatedAt     time.Time
+		FailedAt      time.Time // when it was moved to the dead letters
+	}
+
+	// State represents everything persisted by a store, so the queue survives restarts.
+	State struct {
+		Endpoints   []Endpoint
+		Pending     []Delivery // oldest first
+		DeadLetters []Delivery // oldest first
+	}
+
+	// Op is the kind of a change to the queue.
+	Op string
+
+	// Change represents a change to the queue, appended by stores to the state they last saved.
+	Change struct {
+		Op       Op
+		Delivery Delivery // only the ID is set when removed
+	}
+)
+
+// Changes to the queue.
+const (
+	// OpQueue adds the delivery to the queue, or replaces the queued one with the same ID.
+	OpQueue Op = "queue"
+	// OpAck removes the delivery from the queue.
+	OpAck Op = "ack"
+	// OpBury adds the delivery to the dead letters, or replaces the one with the same ID.
+	OpBury Op = "bury"
+	// OpForget removes the delivery from the dead letters.
+	OpForget Op = "forget"
+)
+
+// Apply applies the changes to the state, in order. Replaying changes already applied leaves the same
+// deliveries, so stores may replay the ones they appended before saving the state whole.
+func (s *State) Apply(changes ...Change) {
+	for _, c := range changes {
+		switch c.Op {
+		case OpQueue:
+			s.Pending = put(s.Pending, c.Delivery)
+		case OpAck:
+			s.Pending = slices.DeleteFunc(s.Pending, func(d Delivery) bool { return d.ID == c.Delivery.ID })
+		case OpBury:
+			s.DeadLetters = put(s.DeadLetters, c.Delivery)
+		case OpForget:
+			s.DeadLetters = slices.DeleteFunc(s.DeadLetters, func(d Delivery) bool { return d.ID == c.Delivery.ID })
+		}
+	}
+}
+
+// put replaces the delivery with the same ID, or appends it.
+func put(deliveries []Delivery, d Delivery) []Delivery {
+	if i := slices.IndexFunc(deliveries, func(p Delivery) bool { return p.ID == d.ID }); i >= 0 {
+		deliveries[i] = d
+		return deliveries
+	}
+
+	return append(deliveries, d)
+}
+
+// wants reports whether the endpoint is subscribed to the event. Without events, the endpoint is subscribed to
+// every event but the candles, sent once per bar and interval.
+func (e Endpoint) wants(event Event) bool {
+	if len(e.Events) == 0 && event.Name == EventCandles {
+		return false
+	}
+
+	if len(e.Events) > 0 && !slices.Contains(e.Events, event.Name) {
+		return false
+	}
+
+	return event.Symbol == "" || len(e.Symbols) == 0 || slices.Contains(e.Symbols, event.Symbol)
+}
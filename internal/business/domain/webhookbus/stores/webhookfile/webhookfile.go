@@ -0,0 +1,304 @@
+// Package webhookfile persists the webhook endpoints and queue to a JSON file on local disk.
+package webhookfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus"
+)
+
+// Store persists the state of the webhooks to a file, replaced atomically on every save
+// so a crash never leaves it half written. The changes to the queue made since are appended
+// to a log next to it, one JSON line each, and replayed on load.
+type Store struct {
+	path    string
+	logPath string
+	mu      sync.Mutex
+}
+
+// NewStore creates a new store saving to the file at path, and appending the changes to the file at path
+// followed by ".log". Their directory is created on the first save or append.
+func NewStore(path string) *Store {
+	return &Store{
+		path:    path,
+		logPath: path + ".log",
+	}
+}
+
+// Load implements webhookbus.Storer interface. A missing file loads an empty state.
+// A change the log ends with that was only partly appended, on a crash, is ignored.
+func (s *Store) Load() (webhookbus.State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var st state
+
+	data, err := os.ReadFile(s.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return webhookbus.State{}, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+
+	if err == nil {
+		if err := json.Unmarshal(data, &st); err != nil {
+			return webhookbus.State{}, fmt.Errorf("failed to parse %s: %w", s.path, err)
+		}
+	}
+
+	busState := toBusState(st)
+
+	changes, err := s.loadLog()
+	if err != nil {
+		return webhookbus.State{}, err
+	}
+
+	busState.Apply(changes...)
+
+	return busState, nil
+}
+
+// loadLog reads the changes appended since the last save. It must be called with mu held.
+func (s *Store) loadLog() ([]webhookbus.Change, error) {
+	f, err := os.Open(s.logPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", s.logPath, err)
+	}
+
+	defer f.Close() // nolint:errcheck
+
+	var changes []webhookbus.Change
+
+	r := bufio.NewReader(f)
+
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// a line without its newline was only partly appended
+			return changes, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", s.logPath, err)
+		}
+
+		var c change
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("failed to parse %s at line %d: %w", s.logPath, line, err)
+		}
+
+		changes = append(changes, toBusChange(c))
+	}
+}
+
+// Save implements webhookbus.Storer interface.
+func (s *Store) Save(busState webhookbus.State) error {
+	data, err := json.Marshal(toFileState(busState))
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Dir(s.path)
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+
+	// flushed before renaming, so the file is either the previous state or the new one
+	if err := tmp.Sync(); err != nil {
+		tmp.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("failed to sync %s: %w", tmp.Name(), err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", s.path, err)
+	}
+
+	// the state includes the changes appended, replaying them on a crash before they are removed
+	// leaves the same deliveries
+	if err := os.Remove(s.logPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", s.logPath, err)
+	}
+
+	return nil
+}
+
+// Append implements webhookbus.Storer interface. The changes are synced to disk before it returns.
+func (s *Store) Append(changes []webhookbus.Change) error {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+
+	for _, c := range changes {
+		if err := enc.Encode(toFileChange(c)); err != nil {
+			return fmt.Errorf("failed to encode change: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Dir(s.logPath)
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	f, err := os.OpenFile(s.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", s.logPath, err)
+	}
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("failed to write %s: %w", s.logPath, err)
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("failed to sync %s: %w", s.logPath, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", s.logPath, err)
+	}
+
+	return nil
+}
+
+type (
+	state struct {
+		Endpoints   []endpoint `json:"endpoints"`
+		Pending     []delivery `json:"pending"`
+		DeadLetters []delivery `json:"dead_letters"`
+	}
+
+	endpoint struct {
+		ID        string    `json:"id"`
+		URL       string    `json:"url"`
+		Secret    string    `json:"secret"`
+		Events    []string  `json:"events,omitempty"`
+		Symbols   []string  `json:"symbols,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	delivery struct {
+		ID            string          `json:"id"`
+		EndpointID    string          `json:"endpoint_id,omitempty"`
+		URL           string          `json:"url"`
+		Secret        string          `json:"secret"`
+		Event         string          `json:"event"`
+		Body          json.RawMessage `json:"body"`
+		Attempts      int             `json:"attempts"`
+		NextAttemptAt time.Time       `json:"next_attempt_at"`
+		LastError     string          `json:"last_error,omitempty"`
+		CreatedAt     time.Time       `json:"created_at"`
+		FailedAt      time.Time       `json:"failed_at,omitzero"`
+	}
+
+	// change is a line of the log. Removals only carry the ID of the delivery.
+	change struct {
+		Op       string    `json:"op"`
+		ID       string    `json:"id,omitempty"`
+		Delivery *delivery `json:"delivery,omitempty"`
+	}
+)
+
+func toFileState(s webhookbus.State) state {
+	endpoints := make([]endpoint, 0, len(s.Endpoints))
+	for _, e := range s.Endpoints {
+		endpoints = append(endpoints, endpoint(e))
+	}
+
+	return state{
+		Endpoints:   endpoints,
+		Pending:     toFileDeliveries(s.Pending),
+		DeadLetters: toFileDeliveries(s.DeadLetters),
+	}
+}
+
+func toFileDeliveries(deliveries []webhookbus.Delivery) []delivery {
+	fileDeliveries := make([]delivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		fileDeliveries = append(fileDeliveries, delivery(d))
+	}
+
+	return fileDeliveries
+}
+
+func toBusState(s state) webhookbus.State {
+	var busState webhookbus.State
+
+	for _, e := range s.Endpoints {
+		busState.Endpoints = append(busState.Endpoints, webhookbus.Endpoint(e))
+	}
+
+	busState.Pending = toBusDeliveries(s.Pending)
+	busState.DeadLetters = toBusDeliveries(s.DeadLetters)
+
+	return busState
+}
+
+func toBusDeliveries(deliveries []delivery) []webhookbus.Delivery {
+	var busDeliveries []webhookbus.Delivery
+
+	for _, d := range deliveries {
+		busDeliveries = append(busDeliveries, webhookbus.Delivery(d))
+	}
+
+	return busDeliveries
+}
+
+func toFileChange(c webhookbus.Change) change {
+	switch c.Op {
+	case webhookbus.OpAck, webhookbus.OpForget:
+		return change{Op: string(c.Op), ID: c.Delivery.ID}
+	default:
+		d := delivery(c.Delivery)
+		return change{Op: string(c.Op), Delivery: &d}
+	}
+}
+
+func toBusChange(c change) webhookbus.Change {
+	busChange := webhookbus.Change{
+		Op:       webhookbus.Op(c.Op),
+		Delivery: webhookbus.Delivery{ID: c.ID},
+	}
+
+	if c.Delivery != nil {
+		busChange.Delivery = webhookbus.Delivery(*c.Delivery)
+	}
+
+	return busChange
+}
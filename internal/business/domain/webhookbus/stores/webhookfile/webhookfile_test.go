@@ -0,0 +1,136 @@
+package webhookfile_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus"
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus/stores/webhookfile"
+)
+
+func TestStore_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "webhooks.json")
+	store := webhookfile.NewStore(path)
+
+	state, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, webhookbus.State{}, state)
+
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	state = webhookbus.State{
+		Endpoints: []webhookbus.Endpoint{{
+			ID:        "e1",
+			URL:       "https://example.com/hook",
+			Secret:    "s3cr3t",
+			Events:    []string{"price"},
+			Symbols:   []string{"BTC"},
+			CreatedAt: now,
+		}},
+		Pending: []webhookbus.Delivery{{
+			ID:            "d1",
+			EndpointID:    "e1",
+			URL:           "https://example.com/hook",
+			Secret:        "s3cr3t",
+			Event:         "price",
+			Body:          json.RawMessage(`{"id":"d1"}`),
+			Attempts:      2,
+			NextAttemptAt: now.Add(time.Minute),
+			LastError:     "bad gateway",
+			CreatedAt:     now,
+		}},
+		DeadLetters: []webhookbus.Delivery{{
+			ID:            "d0",
+			URL:           "https://example.com/alert",
+			Secret:        "s3cr3t",
+			Event:         "alert",
+			Body:          json.RawMessage(`{}`),
+			Attempts:      8,
+			NextAttemptAt: now,
+			LastError:     "connection refused",
+			CreatedAt:     now,
+			FailedAt:      now.Add(time.Hour),
+		}},
+	}
+
+	require.NoError(t, store.Save(state))
+
+	loaded, err := webhookfile.NewStore(path).Load()
+	require.NoError(t, err)
+	assert.Equal(t, state, loaded)
+
+	// no temporary file is left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestStore_Load_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+
+	_, err := webhookfile.NewStore(path).Load()
+
+	assert.ErrorContains(t, err, "failed to parse "+path)
+}
+
+func TestStore_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	store := webhookfile.NewStore(path)
+
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	d1 := webhookbus.Delivery{ID: "d1", URL: "https://example.com/hook", Body: json.RawMessage(`{}`), CreatedAt: now}
+	d2 := webhookbus.Delivery{ID: "d2", URL: "https://example.com/hook", Body: json.RawMessage(`{}`), CreatedAt: now}
+
+	require.NoError(t, store.Save(webhookbus.State{Pending: []webhookbus.Delivery{d1}}))
+
+	retried := d1
+	retried.Attempts = 1
+
+	require.NoError(t, store.Append([]webhookbus.Change{
+		{Op: webhookbus.OpQueue, Delivery: d2},
+		{Op: webhookbus.OpQueue, Delivery: retried},
+	}))
+	require.NoError(t, store.Append([]webhookbus.Change{
+		{Op: webhookbus.OpAck, Delivery: webhookbus.Delivery{ID: "d2"}},
+		{Op: webhookbus.OpBury, Delivery: d2},
+	}))
+
+	expected := webhookbus.State{
+		Pending:     []webhookbus.Delivery{retried},
+		DeadLetters: []webhookbus.Delivery{d2},
+	}
+
+	loaded, err := webhookfile.NewStore(path).Load()
+	require.NoError(t, err)
+	assert.Equal(t, expected, loaded)
+
+	// a change only partly appended on a crash is ignored
+	f, err := os.OpenFile(path+".log", os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+
+	_, err = f.WriteString(`{"op":"ack","id":"d1"`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	loaded, err = webhookfile.NewStore(path).Load()
+	require.NoError(t, err)
+	assert.Equal(t, expected, loaded)
+
+	// saving the state whole removes the changes
+	require.NoError(t, store.Save(expected))
+
+	_, err = os.Stat(path + ".log")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	loaded, err = webhookfile.NewStore(path).Load()
+	require.NoError(t, err)
+	assert.Equal(t, expected, loaded)
+}
@@ -0,0 +1,639 @@
+// Package webhookbus delivers signed webhooks to registered endpoints through a durable retry queue.
+package webhookbus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/webhookclient"
+	"github.com/gandarez/btc-price-service/internal/foundation/log"
+)
+
+// maxInFlight is the maximum number of deliveries posted at once.
+const maxInFlight = 16
+
+// maxWait is the longest the queue waits before checking for due deliveries again.
+const maxWait = time.Minute
+
+// maxAppended is the number of changes appended by the store before the state is saved whole again,
+// so the changes do not grow without bounds.
+const maxAppended = 1000
+
+var (
+	// ErrNotFound is returned when an endpoint or a dead letter does not exist.
+	ErrNotFound = errors.New("webhook not found")
+	// ErrInvalidEndpoint is returned when an endpoint to register is invalid.
+	ErrInvalidEndpoint = errors.New("invalid webhook endpoint")
+)
+
+type (
+	// Business represents the business logic for the webhook domain.
+	Business struct {
+		sender   Sender
+		store    Storer
+		cfg      Config
+		state    State
+		inFlight map[string]struct{} // IDs of the deliveries being posted
+		wake     chan struct{}       // signaled when deliveries may be due
+		changes  []Change            // changes to the queue not persisted yet
+		compact  bool                // whether the state must be saved whole, such as when the endpoints changed
+		appended int                 // changes appended since the state was last saved whole, guarded by saveMu
+		mu       sync.Mutex
+		saveMu   sync.Mutex // serializes the saves, so an older state never replaces a newer one
+	}
+
+	// Config holds the configuration of the retry queue.
+	Config struct {
+		MaxAttempts    int           // attempts before a delivery is moved to the dead letters
+		BaseBackoff    time.Duration // delay after the first failure, doubled after each one
+		MaxBackoff     time.Duration
+		MaxPending     int // deliveries queued, the oldest is moved to the dead letters when full, 0 is unlimited
+		MaxDeadLetters int // dead letters kept, the oldest are dropped, 0 is unlimited
+	}
+
+	// Sender defines the interface for posting signed webhooks.
+	Sender interface {
+		Send(ctx context.Context, url, secret string, body []byte) error
+	}
+
+	// Storer defines the interface for persisting the endpoints and the queue. Save replaces everything
+	// persisted with the state, and Append durably adds the changes to the queue made since.
+	Storer interface {
+		Load() (State, error)
+		Save(state State) error
+		Append(changes []Change) error
+	}
+
+	// envelope is the body of the deliveries of an event.
+	envelope struct {
+		ID        string          `json:"id"` // the same across retries, so receivers can skip duplicates
+		Event     string          `json:"event"`
+		Symbol    string          `json:"symbol,omitempty"`
+		Timestamp string          `json:"timestamp"`
+		Data      json.RawMessage `json:"data"`
+	}
+)
+
+// NewBusiness creates a new instance of the Business struct, with the endpoints and the queue loaded from store.
+// Queued deliveries whose url is no longer accepted, such as ones queued by an older version, are moved to
+// the dead letters. Deliveries are only posted while Run is running.
+func NewBusiness(sender Sender, store Storer, cfg Config) (*Business, error) {
+	state, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook queue: %w", err)
+	}
+
+	cfg.MaxAttempts = max(cfg.MaxAttempts, 1)
+
+	b := &Business{
+		sender:   sender,
+		store:    store,
+		cfg:      cfg,
+		state:    state,
+		inFlight: make(map[string]struct{}),
+		wake:     make(chan struct{}, 1),
+	}
+
+	for _, d := range slices.Clone(b.state.Pending) {
+		if err := webhookclient.ValidateURL(d.URL); err != nil {
+			b.ack(d.ID)
+			b.bury(d, err.Error())
+		}
+	}
+
+	if err := b.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to save webhook queue: %w", err)
+	}
+
+	return b, nil
+}
+
+// CreateEndpoint validates and registers a new endpoint.
+func (b *Business) CreateEndpoint(ne NewEndpoint) (Endpoint, error) {
+	if err := ne.validate(); err != nil {
+		return Endpoint{}, fmt.Errorf("%w: %s", ErrInvalidEndpoint, err)
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("failed to generate endpoint id: %w", err)
+	}
+
+	secret := ne.Secret
+	if secret == "" {
+		if secret, err = randomHex(32); err != nil {
+			return Endpoint{}, fmt.Errorf("failed to generate endpoint secret: %w", err)
+		}
+	}
+
+	// nil rather than empty when every symbol is wanted, the same once loaded from the store
+	var symbols []string
+
+	for _, symbol := range ne.Symbols {
+		symbols = append(symbols, strings.ToUpper(strings.TrimSpace(symbol)))
+	}
+
+	endpoint := Endpoint{
+		ID:        id,
+		URL:       ne.URL,
+		Secret:    secret,
+		Events:    append([]string(nil), ne.Events...),
+		Symbols:   symbols,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	b.mu.Lock()
+	b.state.Endpoints = append(b.state.Endpoints, endpoint)
+	b.compact = true
+	b.mu.Unlock()
+
+	// saved right away, so a registered endpoint is never lost
+	if err := b.Flush(); err != nil {
+		b.mu.Lock()
+		b.state.Endpoints = slices.DeleteFunc(b.state.Endpoints, func(e Endpoint) bool { return e.ID == endpoint.ID })
+		b.mu.Unlock()
+
+		return Endpoint{}, fmt.Errorf("failed to save endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// Endpoints returns every endpoint, oldest first.
+func (b *Business) Endpoints() []Endpoint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return slices.Clone(b.state.Endpoints)
+}
+
+// Endpoint returns the endpoint with the given ID, or ErrNotFound.
+func (b *Business) Endpoint(id string) (Endpoint, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := slices.IndexFunc(b.state.Endpoints, func(e Endpoint) bool { return e.ID == id })
+	if i < 0 {
+		return Endpoint{}, ErrNotFound
+	}
+
+	return b.state.Endpoints[i], nil
+}
+
+// DeleteEndpoint removes the endpoint with the given ID along with its deliveries, or returns ErrNotFound.
+func (b *Business) DeleteEndpoint(ctx context.Context, id string) error {
+	b.mu.Lock()
+
+	i := slices.IndexFunc(b.state.Endpoints, func(e Endpoint) bool { return e.ID == id })
+	if i < 0 {
+		b.mu.Unlock()
+		return ErrNotFound
+	}
+
+	b.state.Endpoints = slices.Delete(b.state.Endpoints, i, i+1)
+
+	ofEndpoint := func(d Delivery) bool { return d.EndpointID == id }
+
+	b.state.Pending = slices.DeleteFunc(b.state.Pending, ofEndpoint)
+	b.state.DeadLetters = slices.DeleteFunc(b.state.DeadLetters, ofEndpoint)
+
+	b.compact = true
+	b.mu.Unlock()
+
+	b.flush(ctx)
+
+	return nil
+}
+
+// Publish queues a delivery of the event to every endpoint subscribed to it, persisted before it returns.
+func (b *Business) Publish(ctx context.Context, event Event) {
+	if b.publish(ctx, event) {
+		b.flush(ctx)
+		b.notify()
+	}
+}
+
+// publish queues a delivery of the event to every endpoint subscribed to it, and reports whether any was.
+func (b *Business) publish(ctx context.Context, event Event) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var queued bool
+
+	for _, endpoint := range b.state.Endpoints {
+		if !endpoint.wants(event) {
+			continue
+		}
+
+		id, err := randomHex(8)
+		if err != nil {
+			log.Extract(ctx).Errorf("failed to generate delivery id: %s", err)
+			continue
+		}
+
+		body, err := json.Marshal(envelope{
+			ID:        id,
+			Event:     event.Name,
+			Symbol:    event.Symbol,
+			Timestamp: event.Timestamp.UTC().Format(time.RFC3339),
+			Data:      event.Data,
+		})
+		if err != nil {
+			log.Extract(ctx).Errorf("failed to encode %s event for webhook %s: %s", event.Name, endpoint.ID, err)
+			continue
+		}
+
+		b.enqueue(Delivery{
+			ID:         id,
+			EndpointID: endpoint.ID,
+			URL:        endpoint.URL,
+			Secret:     endpoint.Secret,
+			Event:      event.Name,
+			Body:       body,
+		})
+
+		queued = true
+	}
+
+	return queued
+}
+
+// Send queues a delivery of the body to the url, signed with the secret, persisted before it returns.
+// It implements the notifier of the alert rules, so their webhooks are retried the same as the ones of the endpoints.
+func (b *Business) Send(ctx context.Context, url, secret string, body []byte) error {
+	if err := webhookclient.ValidateURL(url); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidEndpoint, err)
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return fmt.Errorf("failed to generate delivery id: %w", err)
+	}
+
+	b.mu.Lock()
+	b.enqueue(Delivery{
+		ID:     id,
+		URL:    url,
+		Secret: secret,
+		Event:  EventAlert,
+		Body:   body,
+	})
+	b.mu.Unlock()
+
+	b.flush(ctx)
+	b.notify()
+
+	return nil
+}
+
+// Pending returns the number of queued deliveries.
+func (b *Business) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.state.Pending)
+}
+
+// DeadLetters returns the deliveries given up on, oldest first.
+func (b *Business) DeadLetters() []Delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return slices.Clone(b.state.DeadLetters)
+}
+
+// Replay queues the dead letter with the given ID again, with its attempts reset, or returns ErrNotFound.
+func (b *Business) Replay(ctx context.Context, id string) error {
+	b.mu.Lock()
+
+	i := slices.IndexFunc(b.state.DeadLetters, func(d Delivery) bool { return d.ID == id })
+	if i < 0 {
+		b.mu.Unlock()
+		return ErrNotFound
+	}
+
+	d := b.state.DeadLetters[i]
+	b.state.DeadLetters = slices.Delete(b.state.DeadLetters, i, i+1)
+	b.changes = append(b.changes, Change{Op: OpForget, Delivery: Delivery{ID: d.ID}})
+
+	b.enqueue(d)
+	b.mu.Unlock()
+
+	b.flush(ctx)
+	b.notify()
+
+	return nil
+}
+
+// ReplayAll queues every dead letter again, with their attempts reset, and returns how many were.
+func (b *Business) ReplayAll(ctx context.Context) int {
+	b.mu.Lock()
+
+	dead := b.state.DeadLetters
+	b.state.DeadLetters = nil
+
+	for _, d := range dead {
+		b.changes = append(b.changes, Change{Op: OpForget, Delivery: Delivery{ID: d.ID}})
+		b.enqueue(d)
+	}
+
+	b.mu.Unlock()
+
+	if len(dead) > 0 {
+		b.flush(ctx)
+		b.notify()
+	}
+
+	return len(dead)
+}
+
+// Run posts the due deliveries until ctx is done. Deliveries still queued are posted on the next run.
+func (b *Business) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.wake:
+		case <-timer.C:
+		}
+
+		timer.Reset(b.dispatch(ctx))
+	}
+}
+
+// Flush persists the changes to the endpoints and the queue not persisted yet. Every change is flushed
+// before the call making it returns, so Flush only has to retry the ones whose flush failed.
+// The changes to the queue are appended by the store, and the state is saved whole when the endpoints
+// changed, after a failure, or once enough changes were appended.
+func (b *Business) Flush() error {
+	b.saveMu.Lock()
+	defer b.saveMu.Unlock()
+
+	b.mu.Lock()
+
+	if !b.compact && len(b.changes) == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+
+	if !b.compact && b.appended+len(b.changes) <= maxAppended {
+		changes := b.changes
+		b.changes = nil
+		b.mu.Unlock()
+
+		if err := b.store.Append(changes); err != nil {
+			// the store may have appended part of them, so the state is saved whole instead
+			b.mu.Lock()
+			b.compact = true
+			b.mu.Unlock()
+
+			return err
+		}
+
+		b.appended += len(changes)
+
+		return nil
+	}
+
+	// copied, since deliveries are updated in place once unlocked
+	state := State{
+		Endpoints:   slices.Clone(b.state.Endpoints),
+		Pending:     slices.Clone(b.state.Pending),
+		DeadLetters: slices.Clone(b.state.DeadLetters),
+	}
+
+	b.changes = nil
+	b.compact = false
+	b.mu.Unlock()
+
+	if err := b.store.Save(state); err != nil {
+		b.mu.Lock()
+		b.compact = true
+		b.mu.Unlock()
+
+		return err
+	}
+
+	b.appended = 0
+
+	return nil
+}
+
+// dispatch posts the due deliveries in the background, and returns how long until the next one is due.
+func (b *Business) dispatch(ctx context.Context) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	wait := maxWait
+
+	for _, d := range b.state.Pending {
+		if _, ok := b.inFlight[d.ID]; ok {
+			continue
+		}
+
+		if d.NextAttemptAt.After(now) {
+			wait = min(wait, d.NextAttemptAt.Sub(now))
+			continue
+		}
+
+		// the next one is dispatched once a delivery completes
+		if len(b.inFlight) >= maxInFlight {
+			break
+		}
+
+		b.inFlight[d.ID] = struct{}{}
+
+		go b.deliver(ctx, d)
+	}
+
+	return wait
+}
+
+// deliver posts the delivery, and removes it from the queue once accepted. A failed delivery is retried
+// with an exponential backoff, and moved to the dead letters after the maximum attempts.
+func (b *Business) deliver(ctx context.Context, d Delivery) {
+	err := b.sender.Send(ctx, d.URL, d.Secret, d.Body)
+
+	if b.settle(ctx, d, err) {
+		b.flush(ctx)
+	}
+
+	b.notify()
+}
+
+// settle removes the posted delivery from the queue once accepted, or schedules its next attempt,
+// and reports whether the queue changed.
+func (b *Business) settle(ctx context.Context, d Delivery, err error) bool {
+	logger := log.Extract(ctx)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.inFlight, d.ID)
+
+	i := slices.IndexFunc(b.state.Pending, func(p Delivery) bool { return p.ID == d.ID })
+
+	switch {
+	case i < 0:
+		// deleted with its endpoint meanwhile
+		return false
+	case err == nil:
+		b.ack(d.ID)
+	case ctx.Err() != nil:
+		// shutting down, the attempt does not count
+		return false
+	default:
+		p := &b.state.Pending[i]
+		p.Attempts++
+		p.LastError = err.Error()
+
+		if p.Attempts >= b.cfg.MaxAttempts {
+			logger.Warnf("giving up on webhook %s to %s after %d attempts: %s", d.ID, d.URL, p.Attempts, err)
+
+			dead := *p
+			b.ack(dead.ID)
+			b.bury(dead, dead.LastError)
+
+			break
+		}
+
+		backoff := b.backoff(p.Attempts)
+		p.NextAttemptAt = time.Now().Add(backoff)
+
+		b.changes = append(b.changes, Change{Op: OpQueue, Delivery: *p})
+
+		logger.Warnf("failed to deliver webhook %s to %s, retrying in %s: %s", d.ID, d.URL, backoff, err)
+	}
+
+	return true
+}
+
+// enqueue adds the delivery to the queue, due now. When the queue is full, the oldest delivery
+// not being posted is moved to the dead letters. It must be called with mu held.
+func (b *Business) enqueue(d Delivery) {
+	now := time.Now().UTC()
+
+	d.Attempts = 0
+	d.NextAttemptAt = now
+	d.LastError = ""
+	d.FailedAt = time.Time{}
+
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = now
+	}
+
+	if b.cfg.MaxPending > 0 && len(b.state.Pending) >= b.cfg.MaxPending {
+		i := slices.IndexFunc(b.state.Pending, func(p Delivery) bool {
+			_, ok := b.inFlight[p.ID]
+			return !ok
+		})
+		if i >= 0 {
+			oldest := b.state.Pending[i]
+			b.ack(oldest.ID)
+			b.bury(oldest, "queue full")
+		}
+	}
+
+	b.state.Pending = append(b.state.Pending, d)
+	b.changes = append(b.changes, Change{Op: OpQueue, Delivery: d})
+}
+
+// ack removes the delivery from the queue. It must be called with mu held.
+func (b *Business) ack(id string) {
+	b.state.Pending = slices.DeleteFunc(b.state.Pending, func(p Delivery) bool { return p.ID == id })
+	b.changes = append(b.changes, Change{Op: OpAck, Delivery: Delivery{ID: id}})
+}
+
+// bury moves the delivery to the dead letters, dropping the oldest ones beyond the maximum.
+// It must be called with mu held.
+func (b *Business) bury(d Delivery, reason string) {
+	d.LastError = reason
+	d.FailedAt = time.Now().UTC()
+
+	b.state.DeadLetters = append(b.state.DeadLetters, d)
+	b.changes = append(b.changes, Change{Op: OpBury, Delivery: d})
+
+	if n := len(b.state.DeadLetters) - b.cfg.MaxDeadLetters; b.cfg.MaxDeadLetters > 0 && n > 0 {
+		for _, dropped := range b.state.DeadLetters[:n] {
+			b.changes = append(b.changes, Change{Op: OpForget, Delivery: Delivery{ID: dropped.ID}})
+		}
+
+		b.state.DeadLetters = slices.Delete(b.state.DeadLetters, 0, n)
+	}
+}
+
+// backoff returns the delay before the next attempt, doubled after each failed one up to the maximum.
+func (b *Business) backoff(attempts int) time.Duration {
+	delay := b.cfg.BaseBackoff
+
+	for range attempts - 1 {
+		if b.cfg.MaxBackoff > 0 && delay >= b.cfg.MaxBackoff {
+			break
+		}
+
+		delay *= 2
+	}
+
+	if b.cfg.MaxBackoff > 0 {
+		delay = min(delay, b.cfg.MaxBackoff)
+	}
+
+	return delay
+}
+
+// flush persists the changes, logging failures since the queue keeps working in memory.
+// The changes whose flush failed are retried by the next one.
+func (b *Business) flush(ctx context.Context) {
+	if err := b.Flush(); err != nil {
+		log.Extract(ctx).Errorf("failed to save webhook queue: %s", err)
+	}
+}
+
+// notify wakes Run up, without blocking when it was already.
+func (b *Business) notify() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (ne NewEndpoint) validate() error {
+	if err := webhookclient.ValidateURL(ne.URL); err != nil {
+		return err
+	}
+
+	for _, event := range ne.Events {
+		if !slices.Contains([]string{EventPrice, EventStatus, EventCandles, EventAlert}, event) {
+			return fmt.Errorf("unsupported event %q, expected price, status, candles or alert", event)
+		}
+	}
+
+	for _, symbol := range ne.Symbols {
+		if strings.TrimSpace(symbol) == "" {
+			return errors.New("symbols must not be empty")
+		}
+	}
+
+	return nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,472 @@
+package webhookbus_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus"
+	"github.com/gandarez/btc-price-service/internal/business/domain/webhookbus/stores/webhookfile"
+)
+
+func TestBusiness_CreateEndpoint(t *testing.T) {
+	b := newTestBusiness(t, &mockSender{}, filepath.Join(t.TempDir(), "webhooks.json"), webhookbus.Config{})
+
+	endpoint, err := b.CreateEndpoint(webhookbus.NewEndpoint{
+		URL:     "https://example.com/hook",
+		Events:  []string{webhookbus.EventPrice},
+		Symbols: []string{"btc "},
+	})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, endpoint.ID)
+	assert.Len(t, endpoint.Secret, 64) // generated
+	assert.Equal(t, []string{"BTC"}, endpoint.Symbols)
+	assert.Equal(t, []webhookbus.Endpoint{endpoint}, b.Endpoints())
+
+	found, err := b.Endpoint(endpoint.ID)
+	require.NoError(t, err)
+	assert.Equal(t, endpoint, found)
+
+	require.NoError(t, b.DeleteEndpoint(t.Context(), endpoint.ID))
+	assert.ErrorIs(t, b.DeleteEndpoint(t.Context(), endpoint.ID), webhookbus.ErrNotFound)
+
+	_, err = b.Endpoint(endpoint.ID)
+	assert.ErrorIs(t, err, webhookbus.ErrNotFound)
+	assert.Empty(t, b.Endpoints())
+}
+
+func TestBusiness_CreateEndpoint_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		Endpoint webhookbus.NewEndpoint
+		Expected string
+	}{
+		"not https": {
+			Endpoint: webhookbus.NewEndpoint{URL: "http://example.com/hook"},
+			Expected: `invalid webhook endpoint: url "http://example.com/hook" must be an absolute https url`,
+		},
+		"relative": {
+			Endpoint: webhookbus.NewEndpoint{URL: "/hook"},
+			Expected: `invalid webhook endpoint: url "/hook" must be an absolute https url`,
+		},
+		"private": {
+			Endpoint: webhookbus.NewEndpoint{URL: "https://192.168.0.10/hook"},
+			Expected: `invalid webhook endpoint: url "https://192.168.0.10/hook" must not target a local or private address`,
+		},
+		"unsupported event": {
+			Endpoint: webhookbus.NewEndpoint{URL: "https://example.com", Events: []string{"gap"}},
+			Expected: `invalid webhook endpoint: unsupported event "gap", expected price, status, candles or alert`,
+		},
+		"empty symbol": {
+			Endpoint: webhookbus.NewEndpoint{URL: "https://example.com", Symbols: []string{" "}},
+			Expected: "invalid webhook endpoint: symbols must not be empty",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := newTestBusiness(t, &mockSender{}, filepath.Join(t.TempDir(), "webhooks.json"), webhookbus.Config{})
+
+			_, err := b.CreateEndpoint(test.Endpoint)
+
+			require.ErrorIs(t, err, webhookbus.ErrInvalidEndpoint)
+			assert.EqualError(t, err, test.Expected)
+		})
+	}
+}
+
+func TestBusiness_Publish(t *testing.T) {
+	sender := &mockSender{}
+	b := newTestBusiness(t, sender, filepath.Join(t.TempDir(), "webhooks.json"), webhookbus.Config{MaxAttempts: 3})
+
+	prices, err := b.CreateEndpoint(webhookbus.NewEndpoint{
+		URL:     "https://example.com/prices",
+		Secret:  "s3cr3t",
+		Events:  []string{webhookbus.EventPrice},
+		Symbols: []string{"BTC"},
+	})
+	require.NoError(t, err)
+
+	_, err = b.CreateEndpoint(webhookbus.NewEndpoint{URL: "https://example.com/all"})
+	require.NoError(t, err)
+
+	_, err = b.CreateEndpoint(webhookbus.NewEndpoint{
+		URL:    "https://example.com/candles",
+		Events: []string{webhookbus.EventCandles},
+	})
+	require.NoError(t, err)
+
+	ts := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	b.Publish(t.Context(), webhookbus.Event{Name: "price", Symbol: "ETH", Data: json.RawMessage(`{}`), Timestamp: ts})
+	b.Publish(t.Context(), webhookbus.Event{Name: "status", Data: json.RawMessage(`{}`), Timestamp: ts})
+	b.Publish(t.Context(), webhookbus.Event{
+		Name:      "price",
+		Symbol:    "BTC",
+		Data:      json.RawMessage(`{"price":"50000"}`),
+		Timestamp: ts,
+	})
+	b.Publish(t.Context(), webhookbus.Event{Name: "candles", Symbol: "BTC", Data: json.RawMessage(`{}`), Timestamp: ts})
+
+	// the endpoint without events gets every event but the candles, the one of BTC prices only the last price,
+	// and the candles are sent only to the endpoint subscribed to them
+	assert.Equal(t, 5, b.Pending())
+
+	runBusiness(t, b)
+
+	require.Eventually(t, func() bool { return b.Pending() == 0 }, time.Second, 5*time.Millisecond)
+
+	sent := sender.requests("https://example.com/prices")
+	require.Len(t, sent, 1)
+	assert.Equal(t, "s3cr3t", sent[0].secret)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(sent[0].body, &body))
+
+	assert.NotEmpty(t, body["id"])
+	assert.Equal(t, "price", body["event"])
+	assert.Equal(t, "BTC", body["symbol"])
+	assert.Equal(t, "2025-01-01T10:00:00Z", body["timestamp"])
+	assert.Equal(t, map[string]any{"price": "50000"}, body["data"])
+
+	assert.Len(t, sender.requests("https://example.com/all"), 3)
+	assert.Len(t, sender.requests("https://example.com/candles"), 1)
+	assert.Empty(t, b.DeadLetters())
+
+	_, err = b.Endpoint(prices.ID)
+	require.NoError(t, err)
+}
+
+func TestBusiness_Retry(t *testing.T) {
+	sender := &mockSender{err: errors.New("connection refused")}
+	b := newTestBusiness(t, sender, filepath.Join(t.TempDir(), "webhooks.json"), webhookbus.Config{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	})
+
+	require.NoError(t, b.Send(t.Context(), "https://example.com/alert", "s3cr3t", []byte(`{"rule_id":"1"}`)))
+
+	runBusiness(t, b)
+
+	require.Eventually(t, func() bool { return len(b.DeadLetters()) == 1 }, time.Second, 5*time.Millisecond)
+
+	dead := b.DeadLetters()[0]
+	assert.Equal(t, 3, dead.Attempts)
+	assert.Equal(t, "connection refused", dead.LastError)
+	assert.Equal(t, "alert", dead.Event)
+	assert.JSONEq(t, `{"rule_id":"1"}`, string(dead.Body))
+	assert.False(t, dead.FailedAt.IsZero())
+	assert.Len(t, sender.requests("https://example.com/alert"), 3)
+	assert.Zero(t, b.Pending())
+
+	// replayed once the receiver is back
+	sender.setErr(nil)
+
+	assert.ErrorIs(t, b.Replay(t.Context(), "unknown"), webhookbus.ErrNotFound)
+	require.NoError(t, b.Replay(t.Context(), dead.ID))
+
+	require.Eventually(t, func() bool {
+		return len(sender.requests("https://example.com/alert")) == 4 && b.Pending() == 0
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Empty(t, b.DeadLetters())
+}
+
+func TestBusiness_ReplayAll(t *testing.T) {
+	sender := &mockSender{err: errors.New("bad gateway")}
+	b := newTestBusiness(t, sender, filepath.Join(t.TempDir(), "webhooks.json"), webhookbus.Config{MaxAttempts: 1})
+
+	require.NoError(t, b.Send(t.Context(), "https://example.com/1", "s", []byte(`{}`)))
+	require.NoError(t, b.Send(t.Context(), "https://example.com/2", "s", []byte(`{}`)))
+
+	runBusiness(t, b)
+
+	require.Eventually(t, func() bool { return len(b.DeadLetters()) == 2 }, time.Second, 5*time.Millisecond)
+
+	sender.setErr(nil)
+
+	assert.Equal(t, 2, b.ReplayAll(t.Context()))
+	assert.Zero(t, b.ReplayAll(t.Context()))
+
+	require.Eventually(t, func() bool { return b.Pending() == 0 }, time.Second, 5*time.Millisecond)
+	assert.Empty(t, b.DeadLetters())
+}
+
+func TestBusiness_MaxPending(t *testing.T) {
+	b := newTestBusiness(t, &mockSender{}, filepath.Join(t.TempDir(), "webhooks.json"), webhookbus.Config{
+		MaxPending:     2,
+		MaxDeadLetters: 1,
+	})
+
+	for i := range 4 {
+		require.NoError(t, b.Send(t.Context(), fmt.Sprintf("https://example.com/%d", i+1), "s", []byte(`{}`)))
+	}
+
+	assert.Equal(t, 2, b.Pending())
+
+	// the oldest deliveries are given up on, and only the last dead letter is kept
+	dead := b.DeadLetters()
+	require.Len(t, dead, 1)
+	assert.Equal(t, "https://example.com/2", dead[0].URL)
+	assert.Equal(t, "queue full", dead[0].LastError)
+}
+
+func TestBusiness_Restart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+
+	b := newTestBusiness(t, &mockSender{}, path, webhookbus.Config{})
+
+	endpoint, err := b.CreateEndpoint(webhookbus.NewEndpoint{URL: "https://example.com/hook"})
+	require.NoError(t, err)
+
+	b.Publish(t.Context(), webhookbus.Event{
+		Name:      "status",
+		Data:      json.RawMessage(`{"available":false}`),
+		Timestamp: time.Now(),
+	})
+
+	// queued deliveries are persisted right away, and posted by the next process
+	sender := &mockSender{}
+	restarted := newTestBusiness(t, sender, path, webhookbus.Config{})
+
+	assert.Equal(t, []webhookbus.Endpoint{endpoint}, restarted.Endpoints())
+	assert.Equal(t, 1, restarted.Pending())
+
+	runBusiness(t, restarted)
+
+	require.Eventually(t, func() bool { return restarted.Pending() == 0 }, time.Second, 5*time.Millisecond)
+
+	sent := sender.requests("https://example.com/hook")
+	require.Len(t, sent, 1)
+	assert.Contains(t, string(sent[0].body), `"data":{"available":false}`)
+	assert.Equal(t, endpoint.Secret, sent[0].secret)
+}
+
+func TestBusiness_Flush(t *testing.T) {
+	store := &mockStore{}
+
+	b, err := webhookbus.NewBusiness(&mockSender{}, store, webhookbus.Config{})
+	require.NoError(t, err)
+
+	_, err = b.CreateEndpoint(webhookbus.NewEndpoint{URL: "https://example.com/hook"})
+	require.NoError(t, err)
+
+	// endpoints are saved whole right away
+	assert.Equal(t, 1, store.saves())
+
+	// every queued delivery is appended before publishing returns
+	for range 100 {
+		b.Publish(t.Context(), webhookbus.Event{Name: "price", Data: json.RawMessage(`{}`), Timestamp: time.Now()})
+	}
+
+	assert.Equal(t, 1, store.saves())
+	assert.Len(t, store.changes(), 100)
+	assert.Equal(t, webhookbus.OpQueue, store.changes()[0].Op)
+
+	// nothing is left to flush
+	require.NoError(t, b.Flush())
+	assert.Equal(t, 1, store.saves())
+	assert.Len(t, store.changes(), 100)
+
+	// after a failed append, the state is saved whole on the next flush
+	store.setErr(errors.New("disk full"))
+
+	require.NoError(t, b.Send(t.Context(), "https://example.com/alert", "s", []byte(`{}`)))
+	require.EqualError(t, b.Flush(), "disk full")
+
+	store.setErr(nil)
+
+	require.NoError(t, b.Flush())
+	assert.Equal(t, 2, store.saves())
+	assert.Len(t, store.last().Pending, 101)
+
+	// so are the changes once enough were appended
+	for range 1001 {
+		b.Publish(t.Context(), webhookbus.Event{Name: "price", Data: json.RawMessage(`{}`), Timestamp: time.Now()})
+	}
+
+	assert.Equal(t, 3, store.saves())
+	assert.Len(t, store.last().Pending, 1102)
+}
+
+func TestBusiness_DeadLettersInvalidQueued(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+
+	// queued before the url was refused
+	require.NoError(t, webhookfile.NewStore(path).Save(webhookbus.State{
+		Pending: []webhookbus.Delivery{
+			{ID: "d1", URL: "https://127.0.0.1/hook", Event: "alert", Body: json.RawMessage(`{}`)},
+			{ID: "d2", URL: "https://example.com/hook", Event: "alert", Body: json.RawMessage(`{}`)},
+		},
+	}))
+
+	newTestBusiness(t, &mockSender{}, path, webhookbus.Config{})
+
+	// moved to the dead letters for good, not only in memory
+	b := newTestBusiness(t, &mockSender{}, path, webhookbus.Config{})
+
+	assert.Equal(t, 1, b.Pending())
+
+	dead := b.DeadLetters()
+	require.Len(t, dead, 1)
+	assert.Equal(t, "d1", dead[0].ID)
+	assert.Equal(t, `url "https://127.0.0.1/hook" must not target a local or private address`, dead[0].LastError)
+}
+
+func TestBusiness_Send_Invalid(t *testing.T) {
+	b := newTestBusiness(t, &mockSender{}, filepath.Join(t.TempDir(), "webhooks.json"), webhookbus.Config{})
+
+	err := b.Send(t.Context(), "http://example.com/alert", "s", []byte(`{}`))
+	require.ErrorIs(t, err, webhookbus.ErrInvalidEndpoint)
+
+	err = b.Send(t.Context(), "https://127.0.0.1/alert", "s", []byte(`{}`))
+	require.ErrorIs(t, err, webhookbus.ErrInvalidEndpoint)
+
+	assert.Zero(t, b.Pending())
+}
+
+func newTestBusiness(t *testing.T, sender webhookbus.Sender, path string, cfg webhookbus.Config) *webhookbus.Business {
+	t.Helper()
+
+	b, err := webhookbus.NewBusiness(sender, webhookfile.NewStore(path), cfg)
+	require.NoError(t, err)
+
+	return b
+}
+
+func runBusiness(t *testing.T, b *webhookbus.Business) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		b.Run(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+type (
+	mockStore struct {
+		err      error
+		states   []webhookbus.State
+		appended []webhookbus.Change
+		mu       sync.Mutex
+	}
+
+	mockSender struct {
+		err  error
+		sent []sentRequest
+		mu   sync.Mutex
+	}
+
+	sentRequest struct {
+		url    string
+		secret string
+		body   []byte
+	}
+)
+
+func (m *mockSender) Send(_ context.Context, url, secret string, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, sentRequest{url: url, secret: secret, body: body})
+
+	return m.err
+}
+
+func (m *mockSender) setErr(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.err = err
+}
+
+func (m *mockSender) requests(url string) []sentRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var requests []sentRequest
+
+	for _, r := range m.sent {
+		if r.url == url {
+			requests = append(requests, r)
+		}
+	}
+
+	return requests
+}
+
+func (*mockStore) Load() (webhookbus.State, error) {
+	return webhookbus.State{}, nil
+}
+
+func (m *mockStore) Save(state webhookbus.State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+
+	m.states = append(m.states, state)
+
+	return nil
+}
+
+func (m *mockStore) Append(changes []webhookbus.Change) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+
+	m.appended = append(m.appended, changes...)
+
+	return nil
+}
+
+func (m *mockStore) setErr(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.err = err
+}
+
+func (m *mockStore) changes() []webhookbus.Change {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.appended
+}
+
+func (m *mockStore) saves() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.states)
+}
+
+func (m *mockStore) last() webhookbus.State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.states[len(m.states)-1]
+}
@@ -0,0 +1,81 @@
+package coinbaseclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultTimeoutSecs is the default timeout used for requests to the Coinbase API.
+	DefaultTimeoutSecs = 3
+)
+
+// Client communicates with the Coinbase api.
+type Client struct {
+	baseURL string
+	client  *http.Client
+	doFunc  func(c *Client, req *http.Request) (*http.Response, error)
+}
+
+// NewClient initializes a new Coinbase client with the provided base url.
+func NewClient(baseURL string) *Client {
+	c := &Client{
+		baseURL: baseURL,
+		client: &http.Client{
+			Transport: NewTransport(),
+		},
+		doFunc: func(c *Client, req *http.Request) (*http.Response, error) {
+			req.Header.Set("Accept", "application/json")
+
+			return c.client.Do(req)
+		},
+	}
+
+	return c
+}
+
+// Do executes c.doFunc(), which in turn allows wrapping c.client.Do() and manipulating
+// the request behavior of the api client.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.doFunc(c, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// get performs a GET request to the given url and returns the response status code and body.
+func (c *Client) get(ctx context.Context, url string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed making request to %q: %v", url, err)
+	}
+
+	defer resp.Body.Close() // nolint:errcheck,gosec
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed reading response body from %q: %v", url, err)
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+// NewTransport initializes a new http.Transport.
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		Proxy:               http.ProxyFromEnvironment,
+		TLSHandshakeTimeout: DefaultTimeoutSecs * time.Second,
+	}
+}
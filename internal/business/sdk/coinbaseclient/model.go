@@ -0,0 +1,21 @@
+package coinbaseclient
+
+type (
+	// SpotResult represents the response structure from the Coinbase API for a spot price.
+	SpotResult struct {
+		Error string
+		Spot  Spot
+	}
+
+	// Spot represents the spot price returned by the Coinbase API.
+	Spot struct {
+		Data SpotData `json:"data"`
+	}
+
+	// SpotData contains the spot price of a currency pair.
+	SpotData struct {
+		Amount   string `json:"amount"`
+		Base     string `json:"base"`
+		Currency string `json:"currency"`
+	}
+)
@@ -0,0 +1,91 @@
+package coinbaseclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SpotPrice calls the Coinbase API to retrieve the spot price of a currency pair, e.g. BTC-USD.
+func (c *Client) SpotPrice(ctx context.Context, pair string) (SpotResult, error) {
+	pair = url.PathEscape(pair)
+
+	url := fmt.Sprintf("%s/v2/prices/%s/spot", c.baseURL, pair)
+
+	statusCode, body, err := c.get(ctx, url)
+	if err != nil {
+		return SpotResult{}, err
+	}
+
+	// 200
+	if statusCode == http.StatusOK {
+		spot, err := ParseSpotResponse(body)
+		if err != nil {
+			return SpotResult{}, err
+		}
+
+		return SpotResult{
+			Spot: spot,
+		}, nil
+	}
+
+	// 400 - 503
+	if statusCode >= http.StatusBadRequest && statusCode <= http.StatusServiceUnavailable {
+		errMsg, err := ParseSpotResponseError(body)
+		if err != nil {
+			return SpotResult{}, err
+		}
+
+		return SpotResult{
+			Error: errMsg,
+		}, nil
+	}
+
+	return SpotResult{}, fmt.Errorf(
+		"invalid response status from %q. got: %d, want: %d. body: %q",
+		url,
+		statusCode,
+		http.StatusOK,
+		string(body),
+	)
+}
+
+// ParseSpotResponse parses the response from the prices/{pair}/spot endpoint.
+func ParseSpotResponse(data []byte) (Spot, error) {
+	var spot Spot
+	if err := json.Unmarshal(data, &spot); err != nil {
+		return Spot{}, fmt.Errorf("failed to parse spot response: %v", err)
+	}
+
+	return spot, nil
+}
+
+// ParseSpotResponseError parses the error response from the prices/{pair}/spot endpoint.
+func ParseSpotResponseError(data []byte) (string, error) {
+	type responseBodyErr struct {
+		Errors []struct {
+			ID      string `json:"id"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	var errResp responseBodyErr
+	if err := json.Unmarshal(data, &errResp); err != nil {
+		return "", fmt.Errorf("failed to parse error response: %v", err)
+	}
+
+	if len(errResp.Errors) == 0 {
+		return "", errors.New("failed to parse error response: no errors found")
+	}
+
+	messages := make([]string, 0, len(errResp.Errors))
+	for _, e := range errResp.Errors {
+		messages = append(messages, e.Message)
+	}
+
+	return strings.Join(messages, "; "), nil
+}
@@ -0,0 +1,107 @@
+package coinbaseclient_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coinbaseclient"
+)
+
+func TestClient_SpotPrice(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls int
+
+	router.HandleFunc("/v2/prices/BTC-USD/spot", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		// check headers
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, []string{"application/json"}, req.Header["Accept"])
+
+		// write response
+		f, err := os.Open("testdata/api_spot_response.json")
+		require.NoError(t, err)
+
+		defer f.Close() // nolint:errcheck,gosec
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := coinbaseclient.NewClient(url)
+	result, err := c.SpotPrice(t.Context(), "BTC-USD")
+	require.NoError(t, err)
+
+	assert.Equal(t, coinbaseclient.SpotResult{
+		Spot: coinbaseclient.Spot{
+			Data: coinbaseclient.SpotData{
+				Amount:   "113907.17",
+				Base:     "BTC",
+				Currency: "USD",
+			},
+		},
+	}, result)
+
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
+func TestClient_SpotPrice_ErrNotFound(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/v2/prices/FOO-USD/spot", func(w http.ResponseWriter, _ *http.Request) {
+		// write response
+		f, err := os.Open("testdata/api_spot_not_found_response.json")
+		require.NoError(t, err)
+
+		defer f.Close() // nolint:errcheck,gosec
+
+		w.WriteHeader(http.StatusNotFound)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := coinbaseclient.NewClient(url)
+	result, err := c.SpotPrice(t.Context(), "FOO-USD")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Invalid base currency", result.Error)
+}
+
+func TestClient_SpotPrice_DefaultErr(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/v2/prices/BTC-USD/spot", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusGatewayTimeout)
+	})
+
+	c := coinbaseclient.NewClient(url)
+	_, err := c.SpotPrice(t.Context(), "BTC-USD")
+
+	assert.Contains(t, err.Error(), "invalid response status from")
+}
+
+func TestClient_SpotPrice_InvalidURL(t *testing.T) {
+	c := coinbaseclient.NewClient("invalid-url")
+	_, err := c.SpotPrice(t.Context(), "BTC-USD")
+
+	assert.Contains(t, err.Error(), "failed making request to")
+}
+
+func setupTestServer() (string, *http.ServeMux, func()) {
+	router := http.NewServeMux()
+	srv := httptest.NewServer(router)
+
+	return srv.URL, router, func() { srv.Close() }
+}
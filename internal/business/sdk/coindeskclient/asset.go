@@ -0,0 +1,78 @@
+package coindeskclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// notFoundPrefix starts the error message of the by-symbol endpoint for an unknown symbol.
+const notFoundPrefix = "Not found: asset_symbol"
+
+// AssetBySymbol calls the CoinDesk API to retrieve the data of a single asset by its symbol.
+func (c *Client) AssetBySymbol(ctx context.Context, symbol string) (AssetResult, error) {
+	symbol = url.QueryEscape(symbol)
+
+	url := fmt.Sprintf(
+		"%s/asset/v1/data/by/symbol?asset_symbol=%s&groups=%s",
+		c.baseURL,
+		symbol,
+		assetGroups,
+	)
+
+	statusCode, header, body, err := c.get(ctx, url)
+	if err != nil {
+		return AssetResult{}, err
+	}
+
+	rateLimit, _ := parseRateLimit(header, time.Now())
+
+	// 200
+	if statusCode == http.StatusOK {
+		asset, err := ParseAssetResponse(body)
+		if err != nil {
+			return AssetResult{}, err
+		}
+
+		return AssetResult{
+			Asset:     asset,
+			RateLimit: rateLimit,
+		}, nil
+	}
+
+	// 400 - 503
+	if statusCode >= http.StatusBadRequest && statusCode <= http.StatusServiceUnavailable {
+		errMsg, err := ParseTopListResponseError(body)
+		if err != nil {
+			return AssetResult{}, err
+		}
+
+		return AssetResult{
+			Error:     errMsg,
+			NotFound:  statusCode == http.StatusNotFound || strings.HasPrefix(errMsg, notFoundPrefix),
+			RateLimit: rateLimit,
+		}, nil
+	}
+
+	return AssetResult{}, fmt.Errorf(
+		"invalid response status from %q. got: %d, want: %d. body: %q",
+		url,
+		statusCode,
+		http.StatusOK,
+		string(body),
+	)
+}
+
+// ParseAssetResponse parses the response from the data/by/symbol endpoint.
+func ParseAssetResponse(data []byte) (Asset, error) {
+	var assetData AssetData
+	if err := json.Unmarshal(data, &assetData); err != nil {
+		return Asset{}, fmt.Errorf("failed to parse asset response: %v", err)
+	}
+
+	return assetData.Data, nil
+}
@@ -0,0 +1,122 @@
+package coindeskclient_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+)
+
+func TestClient_AssetBySymbol(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls int
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		// check headers
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, []string{"application/json"}, req.Header["Accept"])
+		assert.Equal(t, []string{"application/json"}, req.Header["Content-Type"])
+		assert.Equal(t, []string{apikey}, req.Header["X-Api-Key"])
+
+		err := req.ParseForm()
+		require.NoError(t, err)
+
+		// check query params
+		assert.Equal(t, "BTC", req.Form.Get("asset_symbol"))
+		assert.True(t, req.Form.Has("groups"))
+
+		// write response
+		f, err := os.Open("testdata/api_asset_by_symbol_response.json")
+		require.NoError(t, err)
+
+		defer f.Close() // nolint:errcheck,gosec
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := coindeskclient.NewClient(url, apikey)
+	result, err := c.AssetBySymbol(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Error)
+	assert.Equal(t, 1, result.Asset.ID)
+	assert.Equal(t, "BTC", result.Asset.Symbol)
+	assert.Equal(t, "113907.168087996", result.Asset.Price.String())
+	assert.Equal(t, int64(1754218141), result.Asset.PriceLastUpdatedAt)
+	assert.Equal(t, "2267242813364.67", result.Asset.MarketCap.Decimal.String())
+	assert.Equal(t, "14306287291.2549", result.Asset.Volume24h.Decimal.String())
+	assert.Equal(t, "-1295.04012371", result.Asset.Change24h.Decimal.String())
+	assert.False(t, result.Asset.ChangePct24h.Valid)
+	assert.Equal(t, "19904334", result.Asset.CirculatingSupply.Decimal.String())
+
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
+func TestClient_AssetBySymbol_ErrBadRequest(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		// write response
+		f, err := os.Open("testdata/api_toplist_bad_request_response.json")
+		require.NoError(t, err)
+
+		defer f.Close() // nolint:errcheck,gosec
+
+		w.WriteHeader(http.StatusBadRequest)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := coindeskclient.NewClient(url, apikey)
+	result, err := c.AssetBySymbol(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Asset)
+	assert.Equal(t, "Not found: market parameter.", result.Error)
+	assert.False(t, result.NotFound)
+}
+
+func TestClient_AssetBySymbol_NotFound(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte(`{"Err":{"message":"Not found: asset_symbol parameter. Value: DOGE"}}`))
+		require.NoError(t, err)
+	})
+
+	c := coindeskclient.NewClient(url, apikey)
+	result, err := c.AssetBySymbol(t.Context(), "DOGE")
+	require.NoError(t, err)
+
+	assert.True(t, result.NotFound)
+	assert.Equal(t, "Not found: asset_symbol parameter. Value: DOGE", result.Error)
+}
+
+func TestClient_AssetBySymbol_DefaultErr(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusGatewayTimeout)
+	})
+
+	c := coindeskclient.NewClient(url, apikey)
+	_, err := c.AssetBySymbol(t.Context(), "BTC")
+
+	assert.Contains(t, err.Error(), "invalid response status from")
+}
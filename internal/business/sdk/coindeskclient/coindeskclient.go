@@ -1,6 +1,9 @@
 package coindeskclient
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"time"
 )
@@ -8,17 +11,50 @@ import (
 const (
 	// DefaultTimeoutSecs is the default timeout used for requests to the CoinDesk API.
 	DefaultTimeoutSecs = 3
+
+	// assetGroups are the groups of fields requested from the asset endpoints.
+	assetGroups = "ID,BASIC,SUPPLY,PRICE,MKT_CAP,VOLUME,CHANGE"
+)
+
+type (
+	// Client communicates with the CoinDesk api.
+	Client struct {
+		baseURL      string
+		client       *http.Client
+		doFunc       func(c *Client, req *http.Request) (*http.Response, error)
+		limiter      *limiter
+		retry        RetryPolicy
+		totalTimeout time.Duration
+	}
+
+	// Option configures the Client.
+	Option func(*Client)
 )
 
-// Client communicates with the CoinDesk api.
-type Client struct {
-	baseURL string
-	client  *http.Client
-	doFunc  func(c *Client, req *http.Request) (*http.Response, error)
+// WithRetryPolicy sets the policy used to retry failed requests.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithRequestTimeout sets the timeout of a single attempt, including reading the response body.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.client.Timeout = timeout
+	}
+}
+
+// WithTotalTimeout sets the timeout of a call, including all retry attempts and backoffs.
+func WithTotalTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.totalTimeout = timeout
+	}
 }
 
 // NewClient initializes a new CoinDesk client with the provided base url and api key.
-func NewClient(baseURL, apikey string) *Client {
+// By default, requests are not retried, have no timeout and no call budget.
+func NewClient(baseURL, apikey string, opts ...Option) *Client {
 	c := &Client{
 		baseURL: baseURL,
 		client: &http.Client{
@@ -30,15 +66,21 @@ func NewClient(baseURL, apikey string) *Client {
 
 			return c.client.Do(req)
 		},
+		limiter: &limiter{},
+		retry:   NoRetry(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
 
 	return c
 }
 
 // Do executes c.doFunc(), which in turn allows wrapping c.client.Do() and manipulating
-// the request behavior of the api client.
+// the request behavior of the api client. Failed requests are retried according to the retry policy.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
-	resp, err := c.doFunc(c, req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +88,37 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	return resp, nil
 }
 
+// get performs a GET request to the given url and returns the response status code, headers and body.
+func (c *Client) get(ctx context.Context, url string) (int, http.Header, []byte, error) {
+	if c.totalTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.totalTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed making request to %q: %w", url, err)
+	}
+
+	defer resp.Body.Close() // nolint:errcheck,gosec
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed reading response body from %q: %v", url, err)
+	}
+
+	return resp.StatusCode, resp.Header, body, nil
+}
+
 // NewTransport initializes a new http.Transport.
 func NewTransport() *http.Transport {
 	return &http.Transport{
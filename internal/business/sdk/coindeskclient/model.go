@@ -1,10 +1,21 @@
 package coindeskclient
 
+import "github.com/shopspring/decimal"
+
 type (
 	// Result represents the response structure from the CoinDesk API for the top list of assets.
 	Result struct {
-		Error   string
-		TopList TopList
+		Error     string
+		RateLimit RateLimit
+		TopList   TopList
+	}
+
+	// AssetResult represents the response structure from the CoinDesk API for a single asset.
+	AssetResult struct {
+		Error     string
+		NotFound  bool // the symbol is unknown to the by-symbol endpoint
+		RateLimit RateLimit
+		Asset     Asset
 	}
 
 	// TopList represents the top list of assets returned by the CoinDesk API.
@@ -25,11 +36,22 @@ type (
 		TotalAssets int `json:"TOTAL_ASSETS"`
 	}
 
-	// Asset represents an individual asset in the top list.
+	// AssetData represents the single asset returned by the CoinDesk API asset data endpoints.
+	AssetData struct {
+		Data Asset `json:"Data"`
+	}
+
+	// Asset represents an individual asset in the top list or asset data endpoints.
+	// Market fields are only present when the matching group is requested, and may be null.
 	Asset struct {
-		ID                 int     `json:"ID"`
-		Price              float64 `json:"PRICE_USD"`
-		PriceLastUpdatedAt int64   `json:"PRICE_USD_LAST_UPDATE_TS"`
-		Symbol             string  `json:"SYMBOL"`
+		ID                 int                 `json:"ID"`
+		Price              decimal.Decimal     `json:"PRICE_USD"`
+		PriceLastUpdatedAt int64               `json:"PRICE_USD_LAST_UPDATE_TS"`
+		Symbol             string              `json:"SYMBOL"`
+		MarketCap          decimal.NullDecimal `json:"CIRCULATING_MKT_CAP_USD"`
+		Volume24h          decimal.NullDecimal `json:"SPOT_MOVING_24_HOUR_QUOTE_VOLUME_USD"`
+		Change24h          decimal.NullDecimal `json:"SPOT_MOVING_24_HOUR_CHANGE_USD"`
+		ChangePct24h       decimal.NullDecimal `json:"SPOT_MOVING_24_HOUR_CHANGE_PERCENTAGE_USD"`
+		CirculatingSupply  decimal.NullDecimal `json:"SUPPLY_CIRCULATING"`
 	}
 )
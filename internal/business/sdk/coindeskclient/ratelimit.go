@@ -0,0 +1,250 @@
+package coindeskclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// HeaderRateLimitLimit is the response header holding the number of calls allowed in the current window.
+	HeaderRateLimitLimit = "X-RateLimit-Limit"
+	// HeaderRateLimitRemaining is the response header holding the number of calls left in the current window.
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	// HeaderRateLimitReset is the response header holding when the current window resets,
+	// either in seconds from now or as a unix timestamp.
+	HeaderRateLimitReset = "X-RateLimit-Reset"
+
+	// unixResetThreshold distinguishes a unix timestamp from a number of seconds in the reset header.
+	unixResetThreshold = 1_000_000_000
+)
+
+// ErrBudgetExhausted is returned without calling the CoinDesk API when the configured call budget is spent.
+var ErrBudgetExhausted = errors.New("coindesk call budget exhausted")
+
+type (
+	// RateLimit represents the remaining quota of calls to the CoinDesk API.
+	// A zero value means no limit is known.
+	RateLimit struct {
+		Limit     int
+		Remaining int
+		Reset     time.Time
+	}
+
+	// Budget limits the number of calls made to the CoinDesk API per UTC day and month. Zero means unlimited.
+	Budget struct {
+		Daily   int
+		Monthly int
+	}
+
+	// limiter tracks the rate limit reported by the API and the calls counted against the budget.
+	limiter struct {
+		mu         sync.Mutex
+		budget     Budget
+		store      UsageStore // nil keeps the calls counted in memory only
+		loaded     bool       // whether the calls counted were loaded from the store
+		reported   RateLimit
+		day        time.Time
+		dayCalls   int
+		month      time.Time
+		monthCalls int
+	}
+)
+
+// WithBudget sets the daily and monthly call budget. Calls over budget fail with ErrBudgetExhausted.
+func WithBudget(budget Budget) Option {
+	return func(c *Client) {
+		c.limiter.budget = budget
+	}
+}
+
+// Known reports whether the rate limit holds any information.
+func (r RateLimit) Known() bool {
+	return !r.Reset.IsZero()
+}
+
+// Interval returns the average time between calls that keeps within the limit until it resets.
+func (r RateLimit) Interval(now time.Time) time.Duration {
+	if !r.Known() {
+		return 0
+	}
+
+	return max(r.Reset.Sub(now), 0) / time.Duration(max(r.Remaining, 1))
+}
+
+// RateLimit returns the most constraining of the rate limit last reported by the API and the remaining budget.
+func (c *Client) RateLimit() RateLimit {
+	return c.limiter.rateLimit(time.Now())
+}
+
+// take counts a call against the budget, failing when it is exhausted or the count can't be saved.
+func (l *limiter) take(now time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.load(); err != nil {
+		return err
+	}
+
+	l.rollover(now)
+
+	if l.budget.Daily > 0 && l.dayCalls >= l.budget.Daily {
+		return ErrBudgetExhausted
+	}
+
+	if l.budget.Monthly > 0 && l.monthCalls >= l.budget.Monthly {
+		return ErrBudgetExhausted
+	}
+
+	l.dayCalls++
+	l.monthCalls++
+
+	// saved before calling, so a restart right after the call still counts it
+	if err := l.save(); err != nil {
+		l.dayCalls--
+		l.monthCalls--
+
+		return err
+	}
+
+	return nil
+}
+
+// load seeds the calls counted from the store, once. It must be called with mu held.
+func (l *limiter) load() error {
+	if l.store == nil || l.loaded {
+		return nil
+	}
+
+	usage, err := l.store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load coindesk call usage: %w", err)
+	}
+
+	l.day, l.dayCalls = usage.Day, usage.DayCalls
+	l.month, l.monthCalls = usage.Month, usage.MonthCalls
+	l.loaded = true
+
+	return nil
+}
+
+// save saves the calls counted to the store, if any. It must be called with mu held.
+func (l *limiter) save() error {
+	if l.store == nil {
+		return nil
+	}
+
+	err := l.store.Save(Usage{
+		Day:        l.day,
+		DayCalls:   l.dayCalls,
+		Month:      l.month,
+		MonthCalls: l.monthCalls,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save coindesk call usage: %w", err)
+	}
+
+	return nil
+}
+
+// observe records the rate limit reported by a response, if any.
+func (l *limiter) observe(header http.Header, now time.Time) {
+	rateLimit, ok := parseRateLimit(header, now)
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.reported = rateLimit
+}
+
+func (l *limiter) rateLimit(now time.Time) RateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// a failure is returned by the next call instead
+	_ = l.load()
+
+	l.rollover(now)
+
+	var candidates []RateLimit
+
+	if l.reported.Known() && l.reported.Reset.After(now) {
+		candidates = append(candidates, l.reported)
+	}
+
+	if l.budget.Daily > 0 {
+		candidates = append(candidates, RateLimit{
+			Limit:     l.budget.Daily,
+			Remaining: max(l.budget.Daily-l.dayCalls, 0),
+			Reset:     l.day.AddDate(0, 0, 1),
+		})
+	}
+
+	if l.budget.Monthly > 0 {
+		candidates = append(candidates, RateLimit{
+			Limit:     l.budget.Monthly,
+			Remaining: max(l.budget.Monthly-l.monthCalls, 0),
+			Reset:     l.month.AddDate(0, 1, 0),
+		})
+	}
+
+	var constraining RateLimit
+
+	for _, candidate := range candidates {
+		if candidate.Interval(now) > constraining.Interval(now) || !constraining.Known() {
+			constraining = candidate
+		}
+	}
+
+	return constraining
+}
+
+// rollover resets the budget counters when a new UTC day or month starts.
+func (l *limiter) rollover(now time.Time) {
+	now = now.UTC()
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !day.Equal(l.day) {
+		l.day = day
+		l.dayCalls = 0
+	}
+
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if !month.Equal(l.month) {
+		l.month = month
+		l.monthCalls = 0
+	}
+}
+
+// parseRateLimit parses the rate limit headers of a response. Both the remaining and reset headers are required.
+func parseRateLimit(header http.Header, now time.Time) (RateLimit, bool) {
+	remaining, err := strconv.Atoi(header.Get(HeaderRateLimitRemaining))
+	if err != nil || remaining < 0 {
+		return RateLimit{}, false
+	}
+
+	reset, err := strconv.ParseInt(header.Get(HeaderRateLimitReset), 10, 64)
+	if err != nil || reset < 0 {
+		return RateLimit{}, false
+	}
+
+	// the limit header is informative only
+	limit, _ := strconv.Atoi(header.Get(HeaderRateLimitLimit))
+
+	resetAt := now.Add(time.Duration(reset) * time.Second)
+	if reset >= unixResetThreshold {
+		resetAt = time.Unix(reset, 0)
+	}
+
+	return RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     resetAt.UTC(),
+	}, true
+}
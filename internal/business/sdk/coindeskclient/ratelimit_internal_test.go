@@ -0,0 +1,97 @@
+package coindeskclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Date(2025, 8, 3, 10, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		header   map[string]string
+		expected RateLimit
+		ok       bool
+	}{
+		"seconds": {
+			header: map[string]string{
+				HeaderRateLimitLimit:     "100",
+				HeaderRateLimitRemaining: "10",
+				HeaderRateLimitReset:     "30",
+			},
+			expected: RateLimit{Limit: 100, Remaining: 10, Reset: now.Add(30 * time.Second)},
+			ok:       true,
+		},
+		"unix timestamp": {
+			header: map[string]string{
+				HeaderRateLimitRemaining: "0",
+				HeaderRateLimitReset:     "1754215200",
+			},
+			expected: RateLimit{Reset: time.Date(2025, 8, 3, 10, 0, 0, 0, time.UTC)},
+			ok:       true,
+		},
+		"missing reset": {
+			header: map[string]string{
+				HeaderRateLimitRemaining: "10",
+			},
+		},
+		"missing headers": {
+			header: map[string]string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range test.header {
+				header.Set(key, value)
+			}
+
+			rateLimit, ok := parseRateLimit(header, now)
+
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, rateLimit)
+		})
+	}
+}
+
+func TestLimiter_RateLimit(t *testing.T) {
+	now := time.Date(2025, 8, 31, 23, 0, 0, 0, time.UTC)
+
+	l := &limiter{
+		budget: Budget{Daily: 100, Monthly: 1000},
+	}
+
+	for range 10 {
+		require.NoError(t, l.take(now))
+	}
+
+	// the daily budget leaves 90 calls for an hour, the monthly one 990 calls for an hour
+	assert.Equal(t, RateLimit{
+		Limit:     100,
+		Remaining: 90,
+		Reset:     time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+	}, l.rateLimit(now))
+
+	// the reported limit leaves 1 call for a minute
+	header := http.Header{}
+	header.Set(HeaderRateLimitRemaining, "1")
+	header.Set(HeaderRateLimitReset, "60")
+
+	l.observe(header, now)
+
+	assert.Equal(t, 1, l.rateLimit(now).Remaining)
+
+	// both budgets roll over with the new month, spreading the monthly one is now the most constraining
+	next := time.Date(2025, 9, 1, 0, 1, 0, 0, time.UTC)
+
+	assert.Equal(t, RateLimit{
+		Limit:     1000,
+		Remaining: 1000,
+		Reset:     time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+	}, l.rateLimit(next))
+}
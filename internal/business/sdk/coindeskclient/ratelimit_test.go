@@ -0,0 +1,139 @@
+package coindeskclient_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+)
+
+func TestClient_AssetBySymbol_RateLimit(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(coindeskclient.HeaderRateLimitLimit, "100")
+		w.Header().Set(coindeskclient.HeaderRateLimitRemaining, "42")
+		w.Header().Set(coindeskclient.HeaderRateLimitReset, "60")
+
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"Data":{"ID":1,"SYMBOL":"BTC","PRICE_USD":50000}}`))
+		require.NoError(t, err)
+	})
+
+	c := coindeskclient.NewClient(url, apikey)
+
+	result, err := c.AssetBySymbol(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, 100, result.RateLimit.Limit)
+	assert.Equal(t, 42, result.RateLimit.Remaining)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), result.RateLimit.Reset, 5*time.Second)
+
+	assert.Equal(t, 42, c.RateLimit().Remaining)
+}
+
+func TestClient_AssetBySymbol_BudgetExhausted(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls int
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls++
+
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"Data":{"ID":1,"SYMBOL":"BTC","PRICE_USD":50000}}`))
+		require.NoError(t, err)
+	})
+
+	c := coindeskclient.NewClient(url, apikey, coindeskclient.WithBudget(coindeskclient.Budget{Daily: 2}))
+
+	for range 2 {
+		_, err := c.AssetBySymbol(t.Context(), "BTC")
+		require.NoError(t, err)
+	}
+
+	_, err := c.AssetBySymbol(t.Context(), "BTC")
+	require.ErrorIs(t, err, coindeskclient.ErrBudgetExhausted)
+
+	assert.Equal(t, 2, numCalls)
+
+	rateLimit := c.RateLimit()
+	assert.Equal(t, 2, rateLimit.Limit)
+	assert.Zero(t, rateLimit.Remaining)
+}
+
+func TestClient_AssetBySymbol_BudgetAcrossRestarts(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls int
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls++
+
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"Data":{"ID":1,"SYMBOL":"BTC","PRICE_USD":50000}}`))
+		require.NoError(t, err)
+	})
+
+	path := filepath.Join(t.TempDir(), "usage.json")
+
+	newClient := func() *coindeskclient.Client {
+		return coindeskclient.NewClient(url, apikey,
+			coindeskclient.WithBudget(coindeskclient.Budget{Monthly: 2}),
+			coindeskclient.WithUsageStore(coindeskclient.NewUsageFile(path)),
+		)
+	}
+
+	c := newClient()
+
+	for range 2 {
+		_, err := c.AssetBySymbol(t.Context(), "BTC")
+		require.NoError(t, err)
+	}
+
+	// a restarted client keeps counting from the saved calls
+	restarted := newClient()
+
+	assert.Zero(t, restarted.RateLimit().Remaining)
+
+	_, err := restarted.AssetBySymbol(t.Context(), "BTC")
+	require.ErrorIs(t, err, coindeskclient.ErrBudgetExhausted)
+
+	assert.Equal(t, 2, numCalls)
+}
+
+func TestClient_AssetBySymbol_UsageUnavailable(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls int
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls++
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// the parent of the file is a file, so the usage can't be loaded nor saved
+	dir := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(dir, nil, 0o600))
+
+	c := coindeskclient.NewClient(url, apikey,
+		coindeskclient.WithBudget(coindeskclient.Budget{Daily: 2}),
+		coindeskclient.WithUsageStore(coindeskclient.NewUsageFile(filepath.Join(dir, "usage.json"))),
+	)
+
+	_, err := c.AssetBySymbol(t.Context(), "BTC")
+	require.ErrorContains(t, err, "coindesk call usage")
+
+	assert.Zero(t, numCalls)
+}
@@ -0,0 +1,137 @@
+package coindeskclient
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy defines how failed requests to the CoinDesk API are retried.
+// Requests are retried on network errors, 429 and 5xx responses.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+	// BaseBackoff is the backoff before the first retry. It doubles on every retry.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the exponential backoff.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, between 0 and 1, by which the backoff is randomly spread.
+	Jitter float64
+}
+
+// NoRetry is the retry policy that makes a single attempt.
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// Backoff returns how long to wait before the given retry attempt, starting at 1.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(p.BaseBackoff) * math.Pow(2, float64(attempt-1))
+	if p.MaxBackoff > 0 {
+		backoff = math.Min(backoff, float64(p.MaxBackoff))
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		backoff += backoff * jitter * (2*rand.Float64() - 1) // nolint:gosec
+	}
+
+	return time.Duration(backoff)
+}
+
+// do executes the request with c.doFunc(), retrying it according to the client retry policy.
+// A response whose Retry-After is past the deadline of the request is returned without retrying.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter.take(time.Now()); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.doFunc(c, req)
+		if resp != nil {
+			c.limiter.observe(resp.Header, time.Now())
+		}
+
+		if attempt >= c.retry.MaxAttempts || !shouldRetry(ctx, req, resp, err) {
+			return resp, err
+		}
+
+		wait := c.retry.Backoff(attempt)
+
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				// the retry would only fail with the deadline exceeded, so the response is returned right away
+				if deadline, ok := ctx.Deadline(); ok && time.Now().Add(retryAfter).After(deadline) {
+					return resp, nil
+				}
+
+				wait = retryAfter
+			}
+
+			// drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req.Body = body
+		}
+	}
+}
+
+// shouldRetry reports whether a request that resulted in the given response or error should be retried.
+func shouldRetry(ctx context.Context, req *http.Request, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	// the body of the request can't be sent again
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	// network errors and per attempt timeouts, the caller context is still alive at this point
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// parseRetryAfter parses the Retry-After header, either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,49 @@
+package coindeskclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 8, 3, 10, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		"empty": {
+			value: "",
+		},
+		"seconds": {
+			value:    "120",
+			expected: 2 * time.Minute,
+			ok:       true,
+		},
+		"http date": {
+			value:    "Sun, 03 Aug 2025 10:00:30 GMT",
+			expected: 30 * time.Second,
+			ok:       true,
+		},
+		"http date in the past": {
+			value:    "Sun, 03 Aug 2025 09:00:00 GMT",
+			expected: 0,
+			ok:       true,
+		},
+		"invalid": {
+			value: "soon",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, ok := parseRetryAfter(test.value, now)
+
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, d)
+		})
+	}
+}
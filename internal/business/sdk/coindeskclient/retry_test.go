@@ -0,0 +1,222 @@
+package coindeskclient_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+)
+
+func TestClient_Retry(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls atomic.Int32
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		switch numCalls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			f, err := os.Open("testdata/api_asset_by_symbol_response.json")
+			require.NoError(t, err)
+
+			defer f.Close() // nolint:errcheck,gosec
+
+			w.WriteHeader(http.StatusOK)
+			_, err = io.Copy(w, f)
+			require.NoError(t, err)
+		}
+	})
+
+	c := coindeskclient.NewClient(url, apikey, coindeskclient.WithRetryPolicy(coindeskclient.RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+		Jitter:      0.5,
+	}))
+
+	result, err := c.AssetBySymbol(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "BTC", result.Asset.Symbol)
+	assert.Equal(t, int32(3), numCalls.Load())
+}
+
+func TestClient_Retry_Exhausted(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls atomic.Int32
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls.Add(1)
+
+		w.WriteHeader(http.StatusGatewayTimeout)
+	})
+
+	c := coindeskclient.NewClient(url, apikey, coindeskclient.WithRetryPolicy(coindeskclient.RetryPolicy{
+		MaxAttempts: 2,
+		BaseBackoff: time.Millisecond,
+	}))
+
+	_, err := c.AssetBySymbol(t.Context(), "BTC")
+
+	assert.Contains(t, err.Error(), "invalid response status from")
+	assert.Equal(t, int32(2), numCalls.Load())
+}
+
+func TestClient_Retry_NotOnClientError(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls atomic.Int32
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls.Add(1)
+
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"Err":{"message":"bad request"}}`))
+		require.NoError(t, err)
+	})
+
+	c := coindeskclient.NewClient(url, apikey, coindeskclient.WithRetryPolicy(coindeskclient.RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+	}))
+
+	result, err := c.AssetBySymbol(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "bad request", result.Error)
+	assert.Equal(t, int32(1), numCalls.Load())
+}
+
+func TestClient_RequestTimeout(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls atomic.Int32
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, req *http.Request) {
+		if numCalls.Add(1) == 1 {
+			select {
+			case <-req.Context().Done():
+			case <-time.After(time.Second):
+			}
+
+			return
+		}
+
+		f, err := os.Open("testdata/api_asset_by_symbol_response.json")
+		require.NoError(t, err)
+
+		defer f.Close() // nolint:errcheck,gosec
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := coindeskclient.NewClient(url, apikey,
+		coindeskclient.WithRequestTimeout(50*time.Millisecond),
+		coindeskclient.WithRetryPolicy(coindeskclient.RetryPolicy{
+			MaxAttempts: 2,
+			BaseBackoff: time.Millisecond,
+		}),
+	)
+
+	result, err := c.AssetBySymbol(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "BTC", result.Asset.Symbol)
+	assert.Equal(t, int32(2), numCalls.Load())
+}
+
+func TestClient_TotalTimeout(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	c := coindeskclient.NewClient(url, apikey,
+		coindeskclient.WithTotalTimeout(50*time.Millisecond),
+		coindeskclient.WithRetryPolicy(coindeskclient.RetryPolicy{
+			MaxAttempts: 10,
+			BaseBackoff: time.Second,
+		}),
+	)
+
+	start := time.Now()
+
+	_, err := c.AssetBySymbol(t.Context(), "BTC")
+
+	assert.ErrorContains(t, err, "context deadline exceeded")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestClient_RetryAfterPastDeadline(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls atomic.Int32
+
+	router.HandleFunc("/asset/v1/data/by/symbol", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls.Add(1)
+
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, err := w.Write([]byte(`{"Err":{"message":"You are over your rate limit."}}`))
+		require.NoError(t, err)
+	})
+
+	c := coindeskclient.NewClient(url, apikey,
+		coindeskclient.WithTotalTimeout(time.Second),
+		coindeskclient.WithRetryPolicy(coindeskclient.RetryPolicy{
+			MaxAttempts: 3,
+			BaseBackoff: time.Millisecond,
+		}),
+	)
+
+	start := time.Now()
+
+	result, err := c.AssetBySymbol(t.Context(), "BTC")
+	require.NoError(t, err)
+
+	assert.Equal(t, "You are over your rate limit.", result.Error)
+	assert.Equal(t, int32(1), numCalls.Load())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := coindeskclient.RetryPolicy{
+		MaxAttempts: 5,
+		BaseBackoff: 100 * time.Millisecond,
+		MaxBackoff:  300 * time.Millisecond,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(3))
+
+	policy.Jitter = 0.5
+
+	for range 100 {
+		backoff := policy.Backoff(1)
+
+		assert.GreaterOrEqual(t, backoff, 50*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 150*time.Millisecond)
+	}
+}
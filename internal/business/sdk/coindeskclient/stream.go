@@ -0,0 +1,225 @@
+package coindeskclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DefaultHeartbeatTimeout is the default time to wait for any message, including heartbeats, before reconnecting.
+	DefaultHeartbeatTimeout = 30 * time.Second
+
+	// MessageTypeTick is the type of the latest tick messages of the index channel.
+	MessageTypeTick = "1101"
+	// MessageTypeHeartbeat is the type of the heartbeat messages sent by the streamer.
+	MessageTypeHeartbeat = "4013"
+
+	// tickChannel is the channel streaming the latest tick of the CoinDesk index.
+	tickChannel = "index_cc_v1_latest_tick"
+	// tickMarket is the index the ticks are subscribed from.
+	tickMarket = "cadli"
+)
+
+type (
+	// Stream receives the latest ticks of the CoinDesk index over a WebSocket connection.
+	Stream struct {
+		url              string
+		apikey           string
+		dialer           *websocket.Dialer
+		heartbeatTimeout time.Duration
+		reconnect        RetryPolicy
+	}
+
+	// StreamOption configures the Stream.
+	StreamOption func(*Stream)
+
+	// StreamHandler receives the events of a Stream. Nil functions are ignored.
+	StreamHandler struct {
+		// OnTick is called for every tick received.
+		OnTick func(Tick)
+		// OnState is called when the stream gets connected and subscribed, or disconnected.
+		OnState func(connected bool)
+		// OnError is called for every connection or streamer error before reconnecting.
+		OnError func(error)
+	}
+
+	// Tick represents the latest value of an instrument streamed by CoinDesk.
+	Tick struct {
+		Type            string          `json:"TYPE"`
+		Message         string          `json:"MESSAGE"`
+		Instrument      string          `json:"INSTRUMENT"`
+		Value           decimal.Decimal `json:"VALUE"`
+		ValueLastUpdate int64           `json:"VALUE_LAST_UPDATE_TS"`
+	}
+
+	// subscription represents the message subscribing to the tick channel.
+	subscription struct {
+		Action      string   `json:"action"`
+		Type        string   `json:"type"`
+		Market      string   `json:"market"`
+		Instruments []string `json:"instruments"`
+		Groups      []string `json:"groups"`
+	}
+)
+
+// WithHeartbeatTimeout sets the time to wait for any message before considering the connection dead.
+func WithHeartbeatTimeout(timeout time.Duration) StreamOption {
+	return func(s *Stream) {
+		s.heartbeatTimeout = timeout
+	}
+}
+
+// WithReconnectPolicy sets the backoff between reconnection attempts. MaxAttempts is ignored.
+func WithReconnectPolicy(policy RetryPolicy) StreamOption {
+	return func(s *Stream) {
+		s.reconnect = policy
+	}
+}
+
+// NewStream initializes a new CoinDesk stream with the provided WebSocket url and api key.
+func NewStream(url, apikey string, opts ...StreamOption) *Stream {
+	s := &Stream{
+		url:    url,
+		apikey: apikey,
+		dialer: &websocket.Dialer{
+			HandshakeTimeout: DefaultTimeoutSecs * time.Second,
+		},
+		heartbeatTimeout: DefaultHeartbeatTimeout,
+		reconnect: RetryPolicy{
+			BaseBackoff: time.Second,
+			MaxBackoff:  time.Minute,
+			Jitter:      0.2,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// InstrumentFor returns the instrument streamed for the given asset symbol.
+func InstrumentFor(symbol string) string {
+	return symbol + "-USD"
+}
+
+// Run connects to the streamer and subscribes to the ticks of the given instruments until ctx is done.
+// It reconnects and subscribes again when the connection drops or stays silent for longer than the heartbeat timeout.
+func (s *Stream) Run(ctx context.Context, instruments []string, handler StreamHandler) error {
+	for attempt := 1; ; attempt++ {
+		subscribed, err := s.session(ctx, instruments, handler)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if handler.OnError != nil {
+			handler.OnError(err)
+		}
+
+		// start backing off again after a healthy session
+		if subscribed {
+			attempt = 1
+		}
+
+		timer := time.NewTimer(s.reconnect.Backoff(attempt))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// session runs a single connection until it fails, and reports whether it got subscribed.
+func (s *Stream) session(ctx context.Context, instruments []string, handler StreamHandler) (bool, error) {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return false, fmt.Errorf("invalid stream url %q: %v", s.url, err)
+	}
+
+	query := u.Query()
+	query.Set("api_key", s.apikey)
+	u.RawQuery = query.Encode()
+
+	conn, resp, err := s.dialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed connecting to %q: %v", s.url, err)
+	}
+
+	defer conn.Close() // nolint:errcheck
+
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+
+	// unblock the read below as soon as the caller is done
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	err = conn.WriteJSON(subscription{
+		Action:      "SUBSCRIBE",
+		Type:        tickChannel,
+		Market:      tickMarket,
+		Instruments: instruments,
+		Groups:      []string{"VALUE"},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed subscribing to %s: %v", tickChannel, err)
+	}
+
+	if handler.OnState != nil {
+		handler.OnState(true)
+		defer handler.OnState(false)
+	}
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(s.heartbeatTimeout)); err != nil {
+			return true, err
+		}
+
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			var netErr interface{ Timeout() bool }
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return true, fmt.Errorf("no message received for %s", s.heartbeatTimeout)
+			}
+
+			return true, fmt.Errorf("failed reading from stream: %v", err)
+		}
+
+		var tick Tick
+		if err := json.Unmarshal(data, &tick); err != nil {
+			return true, fmt.Errorf("failed to parse stream message: %v", err)
+		}
+
+		switch {
+		case tick.Type == MessageTypeTick:
+			if handler.OnTick != nil {
+				handler.OnTick(tick)
+			}
+		case isStreamError(tick.Type):
+			return true, fmt.Errorf("stream error %s: %s", tick.Type, tick.Message)
+		}
+	}
+}
+
+// isStreamError reports whether the message type is an error, which the streamer reports with HTTP like codes.
+func isStreamError(messageType string) bool {
+	code, err := strconv.Atoi(messageType)
+
+	return err == nil && code >= 400 && code < 600
+}
@@ -0,0 +1,178 @@
+package coindeskclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/coindeskclient"
+)
+
+func TestStream_Run(t *testing.T) {
+	var (
+		mu            sync.Mutex
+		subscriptions []map[string]any
+		connections   int
+	)
+
+	url := setupTestStreamer(t, func(conn *websocket.Conn) {
+		var subscription map[string]any
+
+		err := conn.ReadJSON(&subscription)
+		require.NoError(t, err)
+
+		mu.Lock()
+		connections++
+		n := connections
+		subscriptions = append(subscriptions, subscription)
+		mu.Unlock()
+
+		err = conn.WriteJSON(map[string]any{"TYPE": coindeskclient.MessageTypeHeartbeat, "MESSAGE": "HEARTBEAT"})
+		require.NoError(t, err)
+
+		err = conn.WriteJSON(map[string]any{
+			"TYPE":                 coindeskclient.MessageTypeTick,
+			"INSTRUMENT":           "BTC-USD",
+			"VALUE":                50000.0 + float64(n),
+			"VALUE_LAST_UPDATE_TS": 1633072800,
+		})
+		require.NoError(t, err)
+
+		// drop the first connection to force a reconnection
+		if n > 1 {
+			_, _, _ = conn.ReadMessage()
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	var (
+		ticks  []coindeskclient.Tick
+		states []bool
+		errs   []error
+	)
+
+	s := coindeskclient.NewStream(url, apikey, coindeskclient.WithReconnectPolicy(coindeskclient.RetryPolicy{
+		BaseBackoff: 10 * time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+	}))
+
+	err := s.Run(ctx, []string{"BTC-USD"}, coindeskclient.StreamHandler{
+		OnTick: func(tick coindeskclient.Tick) {
+			ticks = append(ticks, tick)
+			if len(ticks) == 2 {
+				cancel()
+			}
+		},
+		OnState: func(connected bool) {
+			states = append(states, connected)
+		},
+		OnError: func(err error) {
+			errs = append(errs, err)
+		},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.Len(t, ticks, 2)
+	assert.Equal(t, coindeskclient.Tick{
+		Type:            coindeskclient.MessageTypeTick,
+		Instrument:      "BTC-USD",
+		Value:           decimal.RequireFromString("50001"),
+		ValueLastUpdate: 1633072800,
+	}, ticks[0])
+	assert.Equal(t, "50002", ticks[1].Value.String())
+
+	assert.Equal(t, []bool{true, false, true, false}, states)
+	assert.Len(t, errs, 1)
+
+	// subscribed again after reconnecting
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, subscriptions, 2)
+
+	for _, subscription := range subscriptions {
+		assert.Equal(t, "SUBSCRIBE", subscription["action"])
+		assert.Equal(t, "index_cc_v1_latest_tick", subscription["type"])
+		assert.Equal(t, []any{"BTC-USD"}, subscription["instruments"])
+	}
+}
+
+func TestStream_Run_HeartbeatTimeout(t *testing.T) {
+	url := setupTestStreamer(t, func(conn *websocket.Conn) {
+		// stay silent until the client gives up
+		_, _, _ = conn.ReadMessage()
+		_, _, _ = conn.ReadMessage()
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	s := coindeskclient.NewStream(url, apikey, coindeskclient.WithHeartbeatTimeout(50*time.Millisecond))
+
+	err := s.Run(ctx, []string{"BTC-USD"}, coindeskclient.StreamHandler{
+		OnError: func(err error) {
+			assert.EqualError(t, err, "no message received for 50ms")
+			cancel()
+		},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStream_Run_StreamError(t *testing.T) {
+	url := setupTestStreamer(t, func(conn *websocket.Conn) {
+		_, _, err := conn.ReadMessage()
+		require.NoError(t, err)
+
+		body, err := json.Marshal(map[string]any{"TYPE": "401", "MESSAGE": "UNAUTHORIZED"})
+		require.NoError(t, err)
+
+		err = conn.WriteMessage(websocket.TextMessage, body)
+		require.NoError(t, err)
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	s := coindeskclient.NewStream(url, apikey)
+
+	err := s.Run(ctx, []string{"BTC-USD"}, coindeskclient.StreamHandler{
+		OnError: func(err error) {
+			assert.EqualError(t, err, "stream error 401: UNAUTHORIZED")
+			cancel()
+		},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// setupTestStreamer starts a local stand-in for the CoinDesk streamer running handler for every connection.
+func setupTestStreamer(t *testing.T, handler func(conn *websocket.Conn)) string {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, apikey, req.URL.Query().Get("api_key"))
+
+		conn, err := upgrader.Upgrade(w, req, nil)
+		require.NoError(t, err)
+
+		defer conn.Close() // nolint:errcheck
+
+		handler(conn)
+	}))
+	t.Cleanup(srv.Close)
+
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
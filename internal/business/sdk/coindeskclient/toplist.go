@@ -4,8 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"time"
 
 	"github.com/gandarez/btc-price-service/internal/business/sdk/page"
 )
@@ -14,59 +14,50 @@ import (
 func (c *Client) TopList(ctx context.Context, page page.Page) (Result, error) {
 	url := fmt.Sprintf(
 		"%s/asset/v1/top/list?page=%d&page_size=%d&sort_by=CIRCULATING_MKT_CAP_USD&"+
-			"sort_direction=DESC&groups=ID,BASIC,PRICE&toplist_quote_asset=BTC",
+			"sort_direction=DESC&groups=%s&toplist_quote_asset=BTC",
 		c.baseURL,
 		page.Number(),
 		page.RowsPerPage(),
+		assetGroups,
 	)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	statusCode, header, body, err := c.get(ctx, url)
 	if err != nil {
-		return Result{}, fmt.Errorf("failed to create request: %v", err)
+		return Result{}, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.Do(req)
-	if err != nil {
-		return Result{}, fmt.Errorf("failed making request to %q: %v", url, err)
-	}
-
-	defer resp.Body.Close() // nolint:errcheck,gosec
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return Result{}, fmt.Errorf("failed reading response body from %q: %v", url, err)
-	}
+	rateLimit, _ := parseRateLimit(header, time.Now())
 
 	// 200
-	if resp.StatusCode == http.StatusOK {
+	if statusCode == http.StatusOK {
 		topList, err := ParseTopListResponse(body)
 		if err != nil {
 			return Result{}, err
 		}
 
 		return Result{
-			TopList: topList,
+			RateLimit: rateLimit,
+			TopList:   topList,
 		}, nil
 	}
 
 	// 400 - 503
-	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode <= http.StatusServiceUnavailable {
+	if statusCode >= http.StatusBadRequest && statusCode <= http.StatusServiceUnavailable {
 		errMsg, err := ParseTopListResponseError(body)
 		if err != nil {
 			return Result{}, err
 		}
 
 		return Result{
-			Error: errMsg,
+			Error:     errMsg,
+			RateLimit: rateLimit,
 		}, nil
 	}
 
 	return Result{}, fmt.Errorf(
 		"invalid response status from %q. got: %d, want: %d. body: %q",
 		url,
-		resp.StatusCode,
+		statusCode,
 		http.StatusOK,
 		string(body),
 	)
@@ -83,6 +74,7 @@ func ParseTopListResponse(data []byte) (TopList, error) {
 }
 
 // ParseTopListResponseError parses the error response from the top/list endpoint.
+// The same error shape is returned by every CoinDesk asset endpoint.
 func ParseTopListResponseError(data []byte) (string, error) {
 	type responseBodyErr struct {
 		Error struct {
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -150,61 +151,61 @@ func TestParseTopListResponse(t *testing.T) {
 				{
 					ID:                 1,
 					Symbol:             "BTC",
-					Price:              113907.168087996,
+					Price:              decimal.RequireFromString("113907.168087996"),
 					PriceLastUpdatedAt: 1754218141,
 				},
 				{
 					ID:                 2,
 					Symbol:             "ETH",
-					Price:              3483.56905401533,
+					Price:              decimal.RequireFromString("3483.56905401533"),
 					PriceLastUpdatedAt: 1754218141,
 				},
 				{
 					ID:                 13,
 					Symbol:             "XRP",
-					Price:              2.87429329041395,
+					Price:              decimal.RequireFromString("2.87429329041395"),
 					PriceLastUpdatedAt: 1754218141,
 				},
 				{
 					ID:                 7,
 					Symbol:             "USDT",
-					Price:              1.00018862042186,
+					Price:              decimal.RequireFromString("1.00018862042186"),
 					PriceLastUpdatedAt: 1754218135,
 				},
 				{
 					ID:                 8,
 					Symbol:             "BNB",
-					Price:              750.607092526658,
+					Price:              decimal.RequireFromString("750.607092526658"),
 					PriceLastUpdatedAt: 1754218141,
 				},
 				{
 					ID:                 3,
 					Symbol:             "SOL",
-					Price:              161.794159381107,
+					Price:              decimal.RequireFromString("161.794159381107"),
 					PriceLastUpdatedAt: 1754218137,
 				},
 				{
 					ID:                 14,
 					Symbol:             "USDC",
-					Price:              1.00009464675868,
+					Price:              decimal.RequireFromString("1.00009464675868"),
 					PriceLastUpdatedAt: 1754218140,
 				},
 				{
 					ID:                 28,
 					Symbol:             "TRX",
-					Price:              0.326770605698632,
+					Price:              decimal.RequireFromString("0.326770605698632"),
 					PriceLastUpdatedAt: 1754218140,
 				},
 				{
 					ID:                 26,
 					Symbol:             "DOGE",
-					Price:              0.197819930141338,
+					Price:              decimal.RequireFromString("0.197819930141338"),
 					PriceLastUpdatedAt: 1754218140,
 				},
 				{
 					ID:                 12,
 					Symbol:             "ADA",
-					Price:              0.726014958952661,
+					Price:              decimal.RequireFromString("0.726014958952661"),
 					PriceLastUpdatedAt: 1754218140,
 				},
 			},
@@ -0,0 +1,116 @@
+package coindeskclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type (
+	// Usage holds the calls counted against the budget in the current UTC day and month.
+	Usage struct {
+		Day        time.Time `json:"day"`
+		DayCalls   int       `json:"day_calls"`
+		Month      time.Time `json:"month"`
+		MonthCalls int       `json:"month_calls"`
+	}
+
+	// UsageStore persists the calls counted against the budget, so the budget holds across restarts.
+	UsageStore interface {
+		Load() (Usage, error)
+		Save(usage Usage) error
+	}
+
+	// UsageFile is the UsageStore saving to a JSON file, replaced atomically on every save.
+	UsageFile struct {
+		path string
+		mu   sync.Mutex
+	}
+)
+
+// WithUsageStore sets the store the calls counted against the budget are loaded from on the first call,
+// and saved to before every call. A call whose count can't be saved is not made.
+func WithUsageStore(store UsageStore) Option {
+	return func(c *Client) {
+		c.limiter.store = store
+	}
+}
+
+// NewUsageFile creates a new store saving to the file at path. Its directory is created on the first save.
+func NewUsageFile(path string) *UsageFile {
+	return &UsageFile{
+		path: path,
+	}
+}
+
+// Load implements UsageStore interface. A missing file loads no calls.
+func (f *UsageFile) Load() (Usage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Usage{}, nil
+	}
+
+	if err != nil {
+		return Usage{}, fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+
+	var usage Usage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return Usage{}, fmt.Errorf("failed to parse %s: %w", f.path, err)
+	}
+
+	return usage, nil
+}
+
+// Save implements UsageStore interface.
+func (f *UsageFile) Save(usage Usage) error {
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dir := filepath.Dir(f.path)
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+
+	// flushed before renaming, so the file is either the previous usage or the new one
+	if err := tmp.Sync(); err != nil {
+		tmp.Close() // nolint:errcheck,gosec
+		return fmt.Errorf("failed to sync %s: %w", tmp.Name(), err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", f.path, err)
+	}
+
+	return nil
+}
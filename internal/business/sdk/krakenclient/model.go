@@ -0,0 +1,24 @@
+package krakenclient
+
+type (
+	// TickerResult represents the response structure from the Kraken API for a ticker.
+	TickerResult struct {
+		Error  string
+		Ticker Ticker
+	}
+
+	// Ticker represents the ticker information of a single pair returned by the Kraken API.
+	Ticker struct {
+		Pair string `json:"-"` // pair name as returned by the api, e.g. XXBTZUSD
+		// Ask is the ask array [price, whole lot volume, lot volume].
+		Ask []string `json:"a"`
+		// Bid is the bid array [price, whole lot volume, lot volume].
+		Bid []string `json:"b"`
+		// LastTrade is the last trade closed array [price, lot volume].
+		LastTrade []string `json:"c"`
+		// Volume is the volume array [today, last 24 hours].
+		Volume []string `json:"v"`
+		// Open is today's opening price.
+		Open string `json:"o"`
+	}
+)
@@ -0,0 +1,70 @@
+package krakenclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Ticker calls the Kraken API to retrieve the ticker information of a currency pair, e.g. XBTUSD.
+func (c *Client) Ticker(ctx context.Context, pair string) (TickerResult, error) {
+	pair = url.QueryEscape(pair)
+
+	url := fmt.Sprintf("%s/0/public/Ticker?pair=%s", c.baseURL, pair)
+
+	statusCode, body, err := c.get(ctx, url)
+	if err != nil {
+		return TickerResult{}, err
+	}
+
+	// kraken reports most errors with a 200 status and a non-empty error list
+	if statusCode == http.StatusOK || (statusCode >= http.StatusBadRequest && statusCode <= http.StatusServiceUnavailable) {
+		ticker, errMsg, err := ParseTickerResponse(body)
+		if err != nil {
+			return TickerResult{}, err
+		}
+
+		return TickerResult{
+			Error:  errMsg,
+			Ticker: ticker,
+		}, nil
+	}
+
+	return TickerResult{}, fmt.Errorf(
+		"invalid response status from %q. got: %d, want: %d. body: %q",
+		url,
+		statusCode,
+		http.StatusOK,
+		string(body),
+	)
+}
+
+// ParseTickerResponse parses the response from the public/Ticker endpoint.
+// It returns the first ticker found and the joined api error messages, if any.
+func ParseTickerResponse(data []byte) (Ticker, string, error) {
+	type responseBody struct {
+		Error  []string          `json:"error"`
+		Result map[string]Ticker `json:"result"`
+	}
+
+	var resp responseBody
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return Ticker{}, "", fmt.Errorf("failed to parse ticker response: %v", err)
+	}
+
+	if len(resp.Error) > 0 {
+		return Ticker{}, strings.Join(resp.Error, "; "), nil
+	}
+
+	for pair, ticker := range resp.Result {
+		ticker.Pair = pair
+
+		return ticker, "", nil
+	}
+
+	return Ticker{}, "", errors.New("failed to parse ticker response: no ticker found")
+}
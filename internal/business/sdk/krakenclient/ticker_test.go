@@ -0,0 +1,103 @@
+package krakenclient_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/krakenclient"
+)
+
+func TestClient_Ticker(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls int
+
+	router.HandleFunc("/0/public/Ticker", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		// check headers
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, []string{"application/json"}, req.Header["Accept"])
+
+		err := req.ParseForm()
+		require.NoError(t, err)
+
+		// check query params
+		assert.Equal(t, "XBTUSD", req.Form.Get("pair"))
+
+		// write response
+		f, err := os.Open("testdata/api_ticker_response.json")
+		require.NoError(t, err)
+
+		defer f.Close() // nolint:errcheck,gosec
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := krakenclient.NewClient(url)
+	result, err := c.Ticker(t.Context(), "XBTUSD")
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Error)
+	assert.Equal(t, "XXBTZUSD", result.Ticker.Pair)
+	assert.Equal(t, []string{"113907.20000", "0.00150000"}, result.Ticker.LastTrade)
+	assert.Equal(t, []string{"1021.34567890", "2743.91827364"}, result.Ticker.Volume)
+	assert.Equal(t, "113050.00000", result.Ticker.Open)
+
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
+func TestClient_Ticker_ErrUnknownPair(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/0/public/Ticker", func(w http.ResponseWriter, _ *http.Request) {
+		// write response
+		f, err := os.Open("testdata/api_ticker_unknown_pair_response.json")
+		require.NoError(t, err)
+
+		defer f.Close() // nolint:errcheck,gosec
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := krakenclient.NewClient(url)
+	result, err := c.Ticker(t.Context(), "FOOUSD")
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Ticker)
+	assert.Equal(t, "EQuery:Unknown asset pair", result.Error)
+}
+
+func TestClient_Ticker_DefaultErr(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/0/public/Ticker", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusGatewayTimeout)
+	})
+
+	c := krakenclient.NewClient(url)
+	_, err := c.Ticker(t.Context(), "XBTUSD")
+
+	assert.Contains(t, err.Error(), "invalid response status from")
+}
+
+func setupTestServer() (string, *http.ServeMux, func()) {
+	router := http.NewServeMux()
+	srv := httptest.NewServer(router)
+
+	return srv.URL, router, func() { srv.Close() }
+}
@@ -2,13 +2,16 @@
 package page
 
 import (
+	"encoding/base64"
 	"fmt"
 )
 
-// Page represents the requested page and rows per page.
+// Page represents the requested page and rows per page. A page is either requested by number,
+// or after an opaque cursor returned with the previous page.
 type Page struct {
 	number int
 	rows   int
+	cursor string // decoded position after which the page starts
 }
 
 // New creates a new Page instance and validates the values are in reason.
@@ -17,28 +20,65 @@ func New(number, rowsPerPage int) (Page, error) {
 		return Page{}, fmt.Errorf("page value too small, must be larger than 0")
 	}
 
-	if rowsPerPage <= 0 {
-		return Page{}, fmt.Errorf("rows value too small, must be larger than 0")
+	if err := validateRows(rowsPerPage); err != nil {
+		return Page{}, err
 	}
 
-	if rowsPerPage > 100 {
-		return Page{}, fmt.Errorf("rows value too large, must be less than 100")
+	p := Page{
+		number: number,
+		rows:   rowsPerPage,
+	}
+
+	return p, nil
+}
+
+// NewCursor creates a new Page instance starting after the given cursor, as returned by Cursor.
+// An empty cursor requests the first page.
+func NewCursor(cursor string, rowsPerPage int) (Page, error) {
+	if err := validateRows(rowsPerPage); err != nil {
+		return Page{}, err
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || (cursor != "" && len(after) == 0) {
+		return Page{}, fmt.Errorf("invalid cursor %q", cursor)
 	}
 
 	p := Page{
-		number: number,
 		rows:   rowsPerPage,
+		cursor: string(after),
 	}
 
 	return p, nil
 }
 
+// Cursor encodes the position of the last row of a page into an opaque cursor to request the next one.
+func Cursor(position string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+func validateRows(rowsPerPage int) error {
+	if rowsPerPage <= 0 {
+		return fmt.Errorf("rows value too small, must be larger than 0")
+	}
+
+	if rowsPerPage > 100 {
+		return fmt.Errorf("rows value too large, must be less than 100")
+	}
+
+	return nil
+}
+
 // String implements the stringer interface.
 func (p Page) String() string {
+	if p.number == 0 {
+		return fmt.Sprintf("after: %q rows: %d", p.cursor, p.rows)
+	}
+
 	return fmt.Sprintf("page: %d rows: %d", p.number, p.rows)
 }
 
-// Number returns the page number.
+// Number returns the page number, or 0 when the page was requested by cursor.
 func (p Page) Number() int {
 	return p.number
 }
@@ -47,3 +87,8 @@ func (p Page) Number() int {
 func (p Page) RowsPerPage() int {
 	return p.rows
 }
+
+// After returns the decoded position after which the page starts, or an empty string for the first page.
+func (p Page) After() string {
+	return p.cursor
+}
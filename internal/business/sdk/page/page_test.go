@@ -55,3 +55,46 @@ func TestPageString(t *testing.T) {
 
 	assert.Equal(t, "page: 3 rows: 25", p.String())
 }
+
+func TestPageNewCursor(t *testing.T) {
+	p, err := page.NewCursor(page.Cursor("42"), 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, "42", p.After())
+	assert.Equal(t, 10, p.RowsPerPage())
+	assert.Zero(t, p.Number())
+	assert.Equal(t, `after: "42" rows: 10`, p.String())
+
+	p, err = page.NewCursor("", 10)
+	require.NoError(t, err)
+
+	assert.Empty(t, p.After())
+}
+
+func TestPageNewCursor_Err(t *testing.T) {
+	tests := map[string]struct {
+		cursor      string
+		rowsPerPage int
+		expected    string
+	}{
+		"invalid cursor": {
+			cursor:      "not a cursor!",
+			rowsPerPage: 10,
+			expected:    `invalid cursor "not a cursor!"`,
+		},
+		"rows per page too large": {
+			cursor:      page.Cursor("42"),
+			rowsPerPage: 101,
+			expected:    "rows value too large, must be less than 100",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := page.NewCursor(test.cursor, test.rowsPerPage)
+
+			assert.EqualError(t, err, test.expected)
+			assert.Empty(t, p)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Package webhookclient sends signed webhooks.
+package webhookclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultTimeoutSecs is the default timeout used for webhook requests.
+	DefaultTimeoutSecs = 10
+	// maxRedirects is the maximum number of redirects followed by a webhook request.
+	maxRedirects = 5
+
+	// SignatureHeader is the header carrying the signature of the request, as "sha256=<hex>".
+	SignatureHeader = "X-Signature-256"
+	// TimestampHeader is the header carrying the unix time the request was signed at.
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+// ErrPrivateAddress is returned when a webhook host resolves to a local or private address.
+var ErrPrivateAddress = errors.New("local or private address")
+
+type (
+	// Client sends webhooks signed with HMAC-SHA256.
+	Client struct {
+		allowed []netip.Prefix // local or private addresses the client may connect to anyway
+		client  *http.Client
+		doFunc  func(c *Client, req *http.Request) (*http.Response, error)
+	}
+
+	// Option configures the Client.
+	Option func(*Client)
+)
+
+// WithAllowedPrefixes lets the client connect to the local or private addresses in the prefixes,
+// such as a receiver on the internal network.
+func WithAllowedPrefixes(prefixes ...netip.Prefix) Option {
+	return func(c *Client) {
+		c.allowed = append(c.allowed, prefixes...)
+	}
+}
+
+// NewClient initializes a new webhook client. It refuses to connect to local or private addresses,
+// whatever the host of the url resolves to, and never goes through a proxy, which would resolve it instead.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		doFunc: func(c *Client, req *http.Request) (*http.Response, error) {
+			return c.client.Do(req)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   DefaultTimeoutSecs * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   c.control,
+	}
+
+	c.client = &http.Client{
+		CheckRedirect: checkRedirect,
+		Timeout:       DefaultTimeoutSecs * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: DefaultTimeoutSecs * time.Second,
+		},
+	}
+
+	return c
+}
+
+// Do executes c.doFunc(), which in turn allows wrapping c.client.Do() and manipulating
+// the request behavior of the webhook client.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.doFunc(c, req)
+}
+
+// Send posts the JSON body to the url, signed with the secret. It fails unless the receiver replies with a 2xx status.
+func (c *Client) Send(ctx context.Context, url, secret string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	timestamp := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, "sha256="+Sign(secret, timestamp, body))
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed making request to %q: %w", url, err)
+	}
+
+	defer resp.Body.Close() // nolint:errcheck,gosec
+
+	// drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("invalid response status from %q. got: %d, want: 2xx", url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// ValidateURL checks that the url is an absolute https url whose host is not a local or private address,
+// so webhooks cannot be used to reach the internal network. Redirects are checked the same by the client.
+// It only rejects what the url shows early: the client checks every address the host resolves to when connecting.
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("url %q must be an absolute https url", rawURL)
+	}
+
+	return checkHost(u)
+}
+
+// checkRedirect refuses to follow a redirect downgrading https to http, or targeting a local or private address.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	if via[0].URL.Scheme == "https" && req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing redirect from https to %q", req.URL.Redacted())
+	}
+
+	return checkHost(req.URL)
+}
+
+// checkHost checks that the host of the url is not a local or private address.
+func checkHost(u *url.URL) error {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("url %q must not target a local address", u.Redacted())
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublic(addr) {
+		return fmt.Errorf("url %q must not target a local or private address", u.Redacted())
+	}
+
+	return nil
+}
+
+// control refuses to connect to local or private addresses, once the host of the url is resolved.
+// It runs on every connection, including the ones of redirects.
+func (c *Client) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse address %q: %w", address, err)
+	}
+
+	addr := addrPort.Addr().Unmap()
+
+	for _, prefix := range c.allowed {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+
+	if !isPublic(addr) {
+		return fmt.Errorf("refusing to connect to %s: %w", addr, ErrPrivateAddress)
+	}
+
+	return nil
+}
+
+// isPublic reports whether the address may be reached by webhooks, rejecting loopback, link-local,
+// private (RFC 1918 and RFC 4193), shared (RFC 6598, carrier-grade NAT), multicast and unspecified addresses.
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	shared := netip.PrefixFrom(netip.AddrFrom4([4]byte{100, 64, 0, 0}), 10)
+
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !shared.Contains(addr)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>" with the secret.
+// Receivers verify requests by computing it from the timestamp header and the raw body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp)
+	_, _ = mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,57 @@
+package webhookclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRedirect(t *testing.T) {
+	via := []*http.Request{httptest.NewRequest(http.MethodPost, "https://example.com/hook", nil)}
+
+	tests := map[string]struct {
+		URL      string
+		Via      []*http.Request
+		Expected string
+	}{
+		"https": {
+			URL: "https://hooks.example.com/hook",
+			Via: via,
+		},
+		"downgrade": {
+			URL:      "http://hooks.example.com/hook",
+			Via:      via,
+			Expected: `refusing redirect from https to "http://hooks.example.com/hook"`,
+		},
+		"private": {
+			URL:      "https://10.1.2.3/hook",
+			Via:      via,
+			Expected: `url "https://10.1.2.3/hook" must not target a local or private address`,
+		},
+		"metadata": {
+			URL:      "https://169.254.169.254/latest/meta-data",
+			Via:      via,
+			Expected: `url "https://169.254.169.254/latest/meta-data" must not target a local or private address`,
+		},
+		"too many": {
+			URL:      "https://hooks.example.com/hook",
+			Via:      []*http.Request{via[0], via[0], via[0], via[0], via[0]},
+			Expected: "stopped after 5 redirects",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkRedirect(httptest.NewRequest(http.MethodPost, test.URL, nil), test.Via)
+
+			if test.Expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, test.Expected)
+		})
+	}
+}
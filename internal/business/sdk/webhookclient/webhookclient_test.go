@@ -0,0 +1,138 @@
+package webhookclient_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/business/sdk/webhookclient"
+)
+
+func TestClient_Send(t *testing.T) {
+	var numCalls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"symbol":"BTC"}`, string(body))
+
+		timestamp, err := strconv.ParseInt(req.Header.Get(webhookclient.TimestampHeader), 10, 64)
+		require.NoError(t, err)
+
+		assert.Equal(t, "sha256="+webhookclient.Sign("s3cr3t", timestamp, body), req.Header.Get(webhookclient.SignatureHeader))
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := webhookclient.NewClient(webhookclient.WithAllowedPrefixes(netip.MustParsePrefix("127.0.0.0/8")))
+
+	err := c.Send(t.Context(), server.URL, "s3cr3t", []byte(`{"symbol":"BTC"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, numCalls)
+}
+
+func TestClient_Send_Err(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c := webhookclient.NewClient(webhookclient.WithAllowedPrefixes(netip.MustParsePrefix("127.0.0.0/8")))
+
+	err := c.Send(t.Context(), server.URL, "s3cr3t", []byte(`{}`))
+
+	assert.EqualError(t, err, `invalid response status from "`+server.URL+`". got: 500, want: 2xx`)
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		webhookclient.Sign("secret", 1700000000, []byte("{}")),
+	)
+}
+
+func TestValidateURL(t *testing.T) {
+	require.NoError(t, webhookclient.ValidateURL("https://example.com/hook"))
+	require.NoError(t, webhookclient.ValidateURL("https://8.8.8.8/hook"))
+
+	tests := map[string]string{
+		"http":          "http://example.com/hook",
+		"relative":      "/hook",
+		"localhost":     "https://localhost:8080/hook",
+		"loopback":      "https://127.0.0.1/hook",
+		"loopback ipv6": "https://[::1]/hook",
+		"mapped ipv4":   "https://[::ffff:127.0.0.1]/hook",
+		"private":       "https://192.168.1.10/hook",
+		"unique local":  "https://[fd00::1]/hook",
+		"link-local":    "https://169.254.169.254/latest/meta-data",
+		"unspecified":   "https://0.0.0.0/hook",
+		"shared":        "https://100.64.0.1/hook",
+	}
+
+	for name, rawURL := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, webhookclient.ValidateURL(rawURL))
+		})
+	}
+}
+
+func TestClient_Send_PrivateRedirect(t *testing.T) {
+	var redirected bool
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		redirected = true
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer target.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, target.URL+"/internal", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	c := webhookclient.NewClient(webhookclient.WithAllowedPrefixes(netip.MustParsePrefix("127.0.0.0/8")))
+
+	err := c.Send(t.Context(), server.URL, "s3cr3t", []byte(`{}`))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must not target a local or private address")
+	assert.False(t, redirected)
+}
+
+func TestClient_Send_PrivateAddress(t *testing.T) {
+	var called bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// a host name is only known to be local once resolved
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	c := webhookclient.NewClient()
+
+	err := c.Send(t.Context(), url, "s3cr3t", []byte(`{}`))
+
+	require.ErrorIs(t, err, webhookclient.ErrPrivateAddress)
+	assert.False(t, called)
+}
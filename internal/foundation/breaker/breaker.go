@@ -0,0 +1,162 @@
+// Package breaker provides a circuit breaker to stop calling a failing dependency.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned when the circuit breaker rejects a call.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State represents the state of the circuit breaker.
+type State int
+
+const (
+	// StateClosed lets every call through.
+	StateClosed State = iota
+	// StateOpen rejects every call until the cool-down elapses.
+	StateOpen
+	// StateHalfOpen lets a single probe call through to decide whether to close or reopen.
+	StateHalfOpen
+)
+
+// String implements fmt.Stringer interface.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type (
+	// Config holds the configuration for the circuit breaker.
+	Config struct {
+		// FailureThreshold is the number of consecutive failures that opens the circuit.
+		// Zero or less disables the breaker.
+		FailureThreshold int
+		// CoolDown is how long the circuit stays open before a probe call is let through.
+		CoolDown time.Duration
+	}
+
+	// Breaker is a thread-safe circuit breaker.
+	Breaker struct {
+		cfg      Config
+		state    State
+		failures int
+		openedAt time.Time
+		probing  bool
+		now      func() time.Time
+		mu       sync.Mutex
+	}
+)
+
+// New creates a new instance of Breaker in the closed state.
+func New(cfg Config) *Breaker {
+	return &Breaker{
+		cfg:   cfg,
+		state: StateClosed,
+		now:   time.Now,
+	}
+}
+
+// Do calls fn if the circuit allows it and records its outcome.
+// It returns ErrOpen without calling fn when the circuit is open.
+func (b *Breaker) Do(fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+	if err != nil {
+		b.Failure()
+		return err
+	}
+
+	b.Success()
+
+	return nil
+}
+
+// Allow reports whether a call may proceed. Once the cool-down elapses,
+// the circuit moves to half-open and a single probe call is allowed.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.CoolDown {
+			return ErrOpen
+		}
+
+		b.state = StateHalfOpen
+		b.probing = true
+
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrOpen
+		}
+
+		b.probing = true
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a successful call and closes the circuit.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Skip records a call whose outcome says nothing about the dependency, such as one refused locally or
+// canceled by the caller. It counts neither as a success nor as a failure, and lets another probe call through
+// when the circuit is half-open.
+func (b *Breaker) Skip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Failure records a failed call. The circuit opens when the failure threshold
+// is reached or when the probe call of a half-open circuit fails.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cfg.FailureThreshold <= 0 {
+		return
+	}
+
+	b.failures++
+	b.probing = false
+
+	if b.state == StateHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+	}
+}
+
+// State returns the current state of the circuit.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
@@ -0,0 +1,104 @@
+package breaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gandarez/btc-price-service/internal/foundation/breaker"
+)
+
+func TestBreaker_Opens(t *testing.T) {
+	b := breaker.New(breaker.Config{FailureThreshold: 2, CoolDown: time.Hour})
+
+	fail := func() error { return errors.New("fail") }
+
+	assert.EqualError(t, b.Do(fail), "fail")
+	assert.Equal(t, breaker.StateClosed, b.State())
+
+	assert.EqualError(t, b.Do(fail), "fail")
+	assert.Equal(t, breaker.StateOpen, b.State())
+
+	var called bool
+
+	err := b.Do(func() error {
+		called = true
+		return nil
+	})
+
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	assert.False(t, called)
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b := breaker.New(breaker.Config{FailureThreshold: 2, CoolDown: time.Hour})
+
+	b.Failure()
+	b.Success()
+	b.Failure()
+
+	assert.Equal(t, breaker.StateClosed, b.State())
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	b := breaker.New(breaker.Config{FailureThreshold: 1, CoolDown: 20 * time.Millisecond})
+
+	b.Failure()
+	require.Equal(t, breaker.StateOpen, b.State())
+
+	assert.Eventually(t, func() bool { return b.Allow() == nil }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, breaker.StateHalfOpen, b.State())
+
+	// only a single probe is let through
+	assert.ErrorIs(t, b.Allow(), breaker.ErrOpen)
+
+	// failed probe reopens the circuit
+	b.Failure()
+	assert.Equal(t, breaker.StateOpen, b.State())
+
+	assert.Eventually(t, func() bool { return b.Allow() == nil }, time.Second, 5*time.Millisecond)
+
+	// successful probe closes the circuit
+	b.Success()
+	assert.Equal(t, breaker.StateClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
+
+func TestBreaker_Skip(t *testing.T) {
+	b := breaker.New(breaker.Config{FailureThreshold: 1, CoolDown: 20 * time.Millisecond})
+
+	require.NoError(t, b.Allow())
+
+	b.Skip()
+	assert.Equal(t, breaker.StateClosed, b.State())
+
+	b.Failure()
+	require.Equal(t, breaker.StateOpen, b.State())
+
+	assert.Eventually(t, func() bool { return b.Allow() == nil }, time.Second, 5*time.Millisecond)
+
+	// a skipped probe leaves the circuit half-open for the next one
+	b.Skip()
+	assert.Equal(t, breaker.StateHalfOpen, b.State())
+	assert.NoError(t, b.Allow())
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := breaker.New(breaker.Config{})
+
+	for range 10 {
+		b.Failure()
+	}
+
+	assert.Equal(t, breaker.StateClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "closed", breaker.StateClosed.String())
+	assert.Equal(t, "open", breaker.StateOpen.String())
+	assert.Equal(t, "half-open", breaker.StateHalfOpen.String())
+}
@@ -82,6 +82,26 @@ func (b *Buffer[T]) Since(since time.Time) []T {
 	return result
 }
 
+// Range retrieves all entities that were updated from the given time, inclusive, until the given one, exclusive.
+// A zero to has no upper bound. It is safe to call this method concurrently.
+func (b *Buffer[T]) Range(from, to time.Time) []T {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var result []T
+
+	for _, u := range b.items {
+		ts := u.Timestamp()
+		if ts.Before(from) || (!to.IsZero() && !ts.Before(to)) {
+			continue
+		}
+
+		result = append(result, u)
+	}
+
+	return result
+}
+
 // Len returns the number of items in the buffer.
 // It is safe to call this method concurrently.
 func (b *Buffer[T]) Len() int {
@@ -54,6 +54,24 @@ func TestBuffer_Since(t *testing.T) {
 	}
 }
 
+func TestBuffer_Range(t *testing.T) {
+	buffer := cache.NewBuffer[mockEntity](10*time.Second, 20*time.Second, 5)
+	require.NotNil(t, buffer)
+
+	now := time.Now().UTC()
+	for i := range 5 {
+		buffer.Add(mockEntity{UpdatedAt: now.Add(time.Duration(i) * time.Second)})
+	}
+
+	updates := buffer.Range(now.Add(1*time.Second), now.Add(3*time.Second))
+
+	require.Len(t, updates, 2)
+	assert.Equal(t, now.Add(1*time.Second), updates[0].UpdatedAt)
+	assert.Equal(t, now.Add(2*time.Second), updates[1].UpdatedAt)
+
+	assert.Len(t, buffer.Range(now.Add(2*time.Second), time.Time{}), 3)
+}
+
 type mockEntity struct {
 	UpdatedAt time.Time
 }
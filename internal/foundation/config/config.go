@@ -13,15 +13,38 @@ type (
 		Environment     string    `mapstructure:"ENVIRONMENT"`
 		ServiceName     string    `mapstructure:"SERVICE_NAME"`
 		ShutdownTimeout int       `mapstructure:"SHUTDOWN_TIMEOUT"` // in seconds
+		AdminConfig     Admin     `mapstructure:",squash"`
+		BreakerConfig   Breaker   `mapstructure:",squash"`
 		BroadcastConfig Broadcast `mapstructure:",squash"`
 		CacheConfig     Cache     `mapstructure:",squash"`
+		CandleConfig    Candle    `mapstructure:",squash"`
+		CoinbaseConfig  Coinbase  `mapstructure:",squash"`
 		CoinDeskConfig  CoinDesk  `mapstructure:",squash"`
+		KrakenConfig    Kraken    `mapstructure:",squash"`
+		PriceConfig     Price     `mapstructure:",squash"`
 		ServerConfig    Server    `mapstructure:",squash"`
+		SSEConfig       SSE       `mapstructure:",squash"`
+		TickConfig      Tick      `mapstructure:",squash"`
+		WebhookConfig   Webhook   `mapstructure:",squash"`
+	}
+
+	// Admin holds the configuration for the admin routes.
+	Admin struct {
+		Token string `mapstructure:"ADMIN_TOKEN"` // bearer token required by the admin routes, empty disables them
+	}
+
+	// Breaker holds the configuration for the circuit breaker guarding the upstream price source.
+	Breaker struct {
+		FailureThreshold int `mapstructure:"BREAKER_FAILURE_THRESHOLD"` // consecutive failures to open, 0 disables
+		CoolDown         int `mapstructure:"BREAKER_COOL_DOWN"`         // in seconds
 	}
 
 	// Broadcast holds the configuration for the pubsub broadcaster.
 	Broadcast struct {
-		MaxPeersPerBroadcaster int `mapstructure:"BROADCAST_MAX_PEERS_PER_BROADCASTER"`
+		MaxPeersPerBroadcaster int    `mapstructure:"BROADCAST_MAX_PEERS_PER_BROADCASTER"`
+		BufferSize             int    `mapstructure:"BROADCAST_BUFFER_SIZE"`     // updates queued per subscriber
+		OverflowPolicy         string `mapstructure:"BROADCAST_OVERFLOW_POLICY"` // drop-oldest, keep-latest or disconnect
+		MaxDrops               int    `mapstructure:"BROADCAST_MAX_DROPS"`       // drops before disconnecting a subscriber
 	}
 
 	// Cache holds the configuration for the in-memory cache.
@@ -31,16 +54,90 @@ type (
 		ExpirationInterval int `mapstructure:"CACHE_EXPIRATION_INTERVAL"` // interval to check for expired entries in seconds
 	}
 
+	// Candle holds the configuration for the OHLC candle aggregation.
+	Candle struct {
+		Intervals []string `mapstructure:"CANDLE_INTERVALS"` // comma separated list of durations, e.g. 1m,5m,1h
+		MaxBars   int      `mapstructure:"CANDLE_MAX_BARS"`  // completed bars kept per symbol and interval
+	}
+
+	// Coinbase holds the configuration for the Coinbase API.
+	Coinbase struct {
+		URL string `mapstructure:"COINBASE_URL"`
+	}
+
 	// CoinDesk holds the configuration for the CoinDesk API.
 	CoinDesk struct {
-		URL          string `mapstructure:"COINDESK_URL"`
-		APIKey       string `mapstructure:"COINDESK_API_KEY"`
-		PollInterval int    `mapstructure:"COINDESK_POLL_INTERVAL"` // in seconds
+		URL            string `mapstructure:"COINDESK_URL"`
+		APIKey         string `mapstructure:"COINDESK_API_KEY"`
+		PollInterval   int    `mapstructure:"COINDESK_POLL_INTERVAL"`   // in seconds
+		RequestTimeout int    `mapstructure:"COINDESK_REQUEST_TIMEOUT"` // timeout of a single attempt in seconds
+		TotalTimeout   int    `mapstructure:"COINDESK_TOTAL_TIMEOUT"`   // timeout including all retries in seconds
+		DailyBudget    int    `mapstructure:"COINDESK_DAILY_BUDGET"`    // calls per UTC day, 0 is unlimited
+		MonthlyBudget  int    `mapstructure:"COINDESK_MONTHLY_BUDGET"`  // calls per UTC month, 0 is unlimited
+		UsagePath      string `mapstructure:"COINDESK_USAGE_PATH"`      // file the calls counted against the budget are saved to
+		Stream         Stream `mapstructure:",squash"`
+		Retry          Retry  `mapstructure:",squash"`
+	}
+
+	// Stream holds the configuration for the CoinDesk WebSocket API.
+	Stream struct {
+		URL              string `mapstructure:"COINDESK_STREAM_URL"`
+		HeartbeatTimeout int    `mapstructure:"COINDESK_STREAM_HEARTBEAT_TIMEOUT"` // in seconds
+	}
+
+	// Retry holds the configuration for retrying failed CoinDesk API requests.
+	Retry struct {
+		MaxAttempts int     `mapstructure:"COINDESK_RETRY_MAX_ATTEMPTS"`
+		BaseBackoff int     `mapstructure:"COINDESK_RETRY_BASE_BACKOFF"` // in milliseconds
+		MaxBackoff  int     `mapstructure:"COINDESK_RETRY_MAX_BACKOFF"`  // in milliseconds
+		Jitter      float64 `mapstructure:"COINDESK_RETRY_JITTER"`       // fraction between 0 and 1
+	}
+
+	// Kraken holds the configuration for the Kraken API.
+	Kraken struct {
+		URL string `mapstructure:"KRAKEN_URL"`
+	}
+
+	// Price holds the configuration for the price domain.
+	Price struct {
+		Aggregation  string   `mapstructure:"PRICE_AGGREGATION"`   // median or vwap, used with more than one source
+		Ingestion    string   `mapstructure:"PRICE_INGESTION"`     // poll or stream
+		MaxDeviation float64  `mapstructure:"PRICE_MAX_DEVIATION"` // maximum deviation from the median in percent
+		MaxQuoteAge  int      `mapstructure:"PRICE_MAX_QUOTE_AGE"` // in seconds
+		MinSources   int      `mapstructure:"PRICE_MIN_SOURCES"`   // minimum number of quotes to compute a price
+		Sources      []string `mapstructure:"PRICE_SOURCES"`       // comma separated list of coindesk, coinbase or kraken
+		Symbols      []string `mapstructure:"PRICE_SYMBOLS"`       // comma separated list of asset symbols to poll
+	}
+
+	// SSE holds the configuration for the Server-Sent Events price stream.
+	SSE struct {
+		Retry         int     `mapstructure:"SSE_RETRY"`          // reconnect delay suggested to clients in milliseconds
+		ShutdownRetry int     `mapstructure:"SSE_SHUTDOWN_RETRY"` // reconnect delay suggested on shutdown in milliseconds
+		RetryJitter   float64 `mapstructure:"SSE_RETRY_JITTER"`   // fraction between 0 and 1
+	}
+
+	// Tick holds the configuration for the validation of upstream ticks.
+	Tick struct {
+		MaxJump           float64 `mapstructure:"TICK_MAX_JUMP"`           // in percent, 0 disables the check
+		JumpConfirmations int     `mapstructure:"TICK_JUMP_CONFIRMATIONS"` // ticks confirming a jump, 0 never accepts it
+		MaxFutureSkew     int     `mapstructure:"TICK_MAX_FUTURE_SKEW"`    // in seconds, 0 disables the check
+		MaxRejected       int     `mapstructure:"TICK_MAX_REJECTED"`       // rejected ticks kept for inspection
+	}
+
+	// Webhook holds the configuration for the delivery of webhooks.
+	Webhook struct {
+		QueuePath      string `mapstructure:"WEBHOOK_QUEUE_PATH"`       // file the endpoints and the queue are persisted to
+		MaxAttempts    int    `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`     // attempts before a delivery is dead-lettered
+		BaseBackoff    int    `mapstructure:"WEBHOOK_BASE_BACKOFF"`     // in milliseconds, doubled after each failure
+		MaxBackoff     int    `mapstructure:"WEBHOOK_MAX_BACKOFF"`      // in milliseconds
+		MaxPending     int    `mapstructure:"WEBHOOK_MAX_PENDING"`      // deliveries queued, 0 is unlimited
+		MaxDeadLetters int    `mapstructure:"WEBHOOK_MAX_DEAD_LETTERS"` // dead letters kept, 0 is unlimited
 	}
 
 	// Server holds the configuration for the HTTP server.
 	Server struct {
 		Port              int `mapstructure:"SERVER_PORT"`
+		GRPCPort          int `mapstructure:"SERVER_GRPC_PORT"`
 		ReadHeaderTimeout int `mapstructure:"SERVER_READ_HEADER_TIMEOUT"`
 	}
 )
@@ -51,6 +148,45 @@ func Load(path string) (Config, error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.SetDefault("BREAKER_FAILURE_THRESHOLD", 5)
+	viper.SetDefault("BREAKER_COOL_DOWN", 30)
+	viper.SetDefault("BROADCAST_BUFFER_SIZE", 100)
+	viper.SetDefault("BROADCAST_OVERFLOW_POLICY", "drop-oldest")
+	viper.SetDefault("BROADCAST_MAX_DROPS", 100)
+	viper.SetDefault("CANDLE_INTERVALS", "")
+	viper.SetDefault("CANDLE_MAX_BARS", 500)
+	viper.SetDefault("COINBASE_URL", "https://api.coinbase.com")
+	viper.SetDefault("COINDESK_REQUEST_TIMEOUT", 5)
+	viper.SetDefault("COINDESK_TOTAL_TIMEOUT", 15)
+	viper.SetDefault("COINDESK_USAGE_PATH", "./data/coindesk-usage.json")
+	viper.SetDefault("COINDESK_RETRY_MAX_ATTEMPTS", 3)
+	viper.SetDefault("COINDESK_RETRY_BASE_BACKOFF", 200)
+	viper.SetDefault("COINDESK_RETRY_MAX_BACKOFF", 2000)
+	viper.SetDefault("COINDESK_RETRY_JITTER", 0.2)
+	viper.SetDefault("COINDESK_STREAM_URL", "wss://data-streamer.coindesk.com")
+	viper.SetDefault("COINDESK_STREAM_HEARTBEAT_TIMEOUT", 30)
+	viper.SetDefault("KRAKEN_URL", "https://api.kraken.com")
+	viper.SetDefault("PRICE_AGGREGATION", "median")
+	viper.SetDefault("PRICE_INGESTION", "poll")
+	viper.SetDefault("PRICE_MAX_DEVIATION", 2)
+	viper.SetDefault("PRICE_MAX_QUOTE_AGE", 60)
+	viper.SetDefault("PRICE_MIN_SOURCES", 1)
+	viper.SetDefault("PRICE_SOURCES", "coindesk")
+	viper.SetDefault("PRICE_SYMBOLS", "BTC")
+	viper.SetDefault("SERVER_GRPC_PORT", 17022)
+	viper.SetDefault("SSE_RETRY", 3000)
+	viper.SetDefault("SSE_SHUTDOWN_RETRY", 5000)
+	viper.SetDefault("SSE_RETRY_JITTER", 0.5)
+	viper.SetDefault("TICK_MAX_JUMP", 10)
+	viper.SetDefault("TICK_JUMP_CONFIRMATIONS", 3)
+	viper.SetDefault("TICK_MAX_FUTURE_SKEW", 30)
+	viper.SetDefault("TICK_MAX_REJECTED", 100)
+	viper.SetDefault("WEBHOOK_QUEUE_PATH", "./data/webhooks.json")
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_BASE_BACKOFF", 1000)
+	viper.SetDefault("WEBHOOK_MAX_BACKOFF", 300000)
+	viper.SetDefault("WEBHOOK_MAX_PENDING", 10000)
+	viper.SetDefault("WEBHOOK_MAX_DEAD_LETTERS", 1000)
 
 	err := viper.ReadInConfig()
 	if err != nil {
@@ -67,9 +203,21 @@ func Load(path string) (Config, error) {
 	return config, nil
 }
 
+// String implements fmt.Stringer interface.
+func (a Admin) String() string {
+	return fmt.Sprintf("enabled: %t", a.Token != "")
+}
+
+// String implements fmt.Stringer interface.
+func (b Breaker) String() string {
+	return fmt.Sprintf("failure threshold: %d, cool down: %d", b.FailureThreshold, b.CoolDown)
+}
+
 // String implements fmt.Stringer interface.
 func (b Broadcast) String() string {
-	return fmt.Sprintf("max peers per broadcaster: %d", b.MaxPeersPerBroadcaster)
+	return fmt.Sprintf("max peers per broadcaster: %d, buffer size: %d, overflow policy: %s, max drops: %d",
+		b.MaxPeersPerBroadcaster, b.BufferSize, b.OverflowPolicy, b.MaxDrops,
+	)
 }
 
 // String implements fmt.Stringer interface.
@@ -77,21 +225,83 @@ func (c Cache) String() string {
 	return fmt.Sprintf("ttl: %d, max size: %d, expiration interval: %d", c.TTL, c.MaxSize, c.ExpirationInterval)
 }
 
+// String implements fmt.Stringer interface.
+func (c Candle) String() string {
+	return fmt.Sprintf("intervals: %s, max bars: %d", strings.Join(c.Intervals, ","), c.MaxBars)
+}
+
+// String implements fmt.Stringer interface.
+func (cb Coinbase) String() string {
+	return fmt.Sprintf("url: %s", cb.URL)
+}
+
 // String implements fmt.Stringer interface.
 func (cd CoinDesk) String() string {
-	return fmt.Sprintf("url: %s, apiKey: %s, poll interval: %d", cd.URL, cd.APIKey, cd.PollInterval)
+	return fmt.Sprintf("url: %s, apiKey: %s, poll interval: %d, request timeout: %d, total timeout: %d,"+
+		" daily budget: %d, monthly budget: %d, usage path: %s, stream: (%s), retry: (%s)",
+		cd.URL, cd.APIKey, cd.PollInterval, cd.RequestTimeout, cd.TotalTimeout, cd.DailyBudget, cd.MonthlyBudget,
+		cd.UsagePath, cd.Stream, cd.Retry,
+	)
+}
+
+// String implements fmt.Stringer interface.
+func (s Stream) String() string {
+	return fmt.Sprintf("url: %s, heartbeat timeout: %d", s.URL, s.HeartbeatTimeout)
+}
+
+// String implements fmt.Stringer interface.
+func (r Retry) String() string {
+	return fmt.Sprintf("max attempts: %d, base backoff: %d, max backoff: %d, jitter: %.2f",
+		r.MaxAttempts, r.BaseBackoff, r.MaxBackoff, r.Jitter,
+	)
+}
+
+// String implements fmt.Stringer interface.
+func (k Kraken) String() string {
+	return fmt.Sprintf("url: %s", k.URL)
+}
+
+// String implements fmt.Stringer interface.
+func (p Price) String() string {
+	return fmt.Sprintf("aggregation: %s, ingestion: %s, max deviation: %.2f, max quote age: %d, min sources: %d,"+
+		" sources: %s, symbols: %s",
+		p.Aggregation, p.Ingestion, p.MaxDeviation, p.MaxQuoteAge, p.MinSources,
+		strings.Join(p.Sources, ","), strings.Join(p.Symbols, ","),
+	)
 }
 
 // String implements fmt.Stringer interface.
 func (s Server) String() string {
-	return fmt.Sprintf("port: %d, read header timeout: %d", s.Port, s.ReadHeaderTimeout)
+	return fmt.Sprintf("port: %d, grpc port: %d, read header timeout: %d", s.Port, s.GRPCPort, s.ReadHeaderTimeout)
+}
+
+// String implements fmt.Stringer interface.
+func (s SSE) String() string {
+	return fmt.Sprintf("retry: %d, shutdown retry: %d, retry jitter: %.2f", s.Retry, s.ShutdownRetry, s.RetryJitter)
+}
+
+// String implements fmt.Stringer interface.
+func (t Tick) String() string {
+	return fmt.Sprintf("max jump: %.2f, jump confirmations: %d, max future skew: %d, max rejected: %d",
+		t.MaxJump, t.JumpConfirmations, t.MaxFutureSkew, t.MaxRejected,
+	)
+}
+
+// String implements fmt.Stringer interface.
+func (w Webhook) String() string {
+	return fmt.Sprintf("queue path: %s, max attempts: %d, base backoff: %d, max backoff: %d, max pending: %d,"+
+		" max dead letters: %d",
+		w.QueuePath, w.MaxAttempts, w.BaseBackoff, w.MaxBackoff, w.MaxPending, w.MaxDeadLetters,
+	)
 }
 
 // String implements fmt.Stringer interface.
 func (c Config) String() string {
-	return fmt.Sprintf("env: %s, service: %s, shutdown timeout: %d,"+
-		" broadcast: (%s), cache: (%s), coindesk: (%s), server: (%s)",
-		c.Environment, c.ServiceName, c.ShutdownTimeout,
-		c.BroadcastConfig, c.CacheConfig, c.CoinDeskConfig, c.ServerConfig,
+	return fmt.Sprintf("env: %s, service: %s, shutdown timeout: %d, admin: (%s),"+
+		" breaker: (%s), broadcast: (%s), cache: (%s), candle: (%s), coinbase: (%s), coindesk: (%s), kraken: (%s), price: (%s),"+
+		" server: (%s), sse: (%s), tick: (%s), webhook: (%s)",
+		c.Environment, c.ServiceName, c.ShutdownTimeout, c.AdminConfig,
+		c.BreakerConfig, c.BroadcastConfig, c.CacheConfig, c.CandleConfig, c.CoinbaseConfig, c.CoinDeskConfig, c.KrakenConfig,
+		c.PriceConfig, c.ServerConfig, c.SSEConfig, c.TickConfig, c.WebhookConfig,
 	)
 }
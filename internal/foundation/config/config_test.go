@@ -23,23 +23,87 @@ func TestConfig_Load(t *testing.T) {
 		Environment:     "development",
 		ServiceName:     "btc-price-service",
 		ShutdownTimeout: 20,
+		AdminConfig: config.Admin{
+			Token: "some-admin-token",
+		},
+		BreakerConfig: config.Breaker{
+			FailureThreshold: 3,
+			CoolDown:         45,
+		},
 		BroadcastConfig: config.Broadcast{
 			MaxPeersPerBroadcaster: 300,
+			BufferSize:             50,
+			OverflowPolicy:         "disconnect",
+			MaxDrops:               10,
 		},
 		CacheConfig: config.Cache{
 			TTL:                900,
 			MaxSize:            50,
 			ExpirationInterval: 20,
 		},
+		CandleConfig: config.Candle{
+			Intervals: []string{"1m", "15m"},
+			MaxBars:   200,
+		},
+		CoinbaseConfig: config.Coinbase{
+			URL: "https://api.coinbase.com",
+		},
 		CoinDeskConfig: config.CoinDesk{
-			URL:          "https://data-api.coindesk.com",
-			APIKey:       "some-api-key",
-			PollInterval: 10,
+			URL:            "https://data-api.coindesk.com",
+			APIKey:         "some-api-key",
+			PollInterval:   10,
+			RequestTimeout: 4,
+			TotalTimeout:   12,
+			DailyBudget:    5000,
+			MonthlyBudget:  100000,
+			UsagePath:      "/var/lib/btc-price-service/coindesk-usage.json",
+			Stream: config.Stream{
+				URL:              "wss://data-streamer.coindesk.com",
+				HeartbeatTimeout: 20,
+			},
+			Retry: config.Retry{
+				MaxAttempts: 4,
+				BaseBackoff: 250,
+				MaxBackoff:  3000,
+				Jitter:      0.25,
+			},
+		},
+		KrakenConfig: config.Kraken{
+			URL: "https://api.kraken.com",
+		},
+		PriceConfig: config.Price{
+			Aggregation:  "vwap",
+			Ingestion:    "stream",
+			MaxDeviation: 1.5,
+			MaxQuoteAge:  30,
+			MinSources:   2,
+			Sources:      []string{"coindesk", "coinbase", "kraken"},
+			Symbols:      []string{"BTC", "ETH", "SOL"},
 		},
 		ServerConfig: config.Server{
 			Port:              8081,
+			GRPCPort:          9091,
 			ReadHeaderTimeout: 15,
 		},
+		SSEConfig: config.SSE{
+			Retry:         2000,
+			ShutdownRetry: 8000,
+			RetryJitter:   0.3,
+		},
+		TickConfig: config.Tick{
+			MaxJump:           25,
+			JumpConfirmations: 5,
+			MaxFutureSkew:     10,
+			MaxRejected:       20,
+		},
+		WebhookConfig: config.Webhook{
+			QueuePath:      "/var/lib/btc-price-service/webhooks.json",
+			MaxAttempts:    5,
+			BaseBackoff:    500,
+			MaxBackoff:     60000,
+			MaxPending:     2000,
+			MaxDeadLetters: 300,
+		},
 	}, cfg)
 }
 
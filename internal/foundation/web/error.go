@@ -0,0 +1,50 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Error is an error response replied with its status code, encoded as {"error":"..."}.
+type Error struct {
+	Err    error
+	Status int
+}
+
+// NewError creates an error response with the given status code.
+func NewError(status int, err error) *Error {
+	return &Error{
+		Err:    err,
+		Status: status,
+	}
+}
+
+// Error implements error interface.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode implements StatusCoder interface.
+func (e *Error) StatusCode() int {
+	if e.Status == 0 {
+		return http.StatusInternalServerError
+	}
+
+	return e.Status
+}
+
+// Encode implements Encoder interface.
+func (e *Error) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(struct {
+		Error string `json:"error"`
+	}{
+		Error: e.Err.Error(),
+	})
+
+	return data, "application/json", err
+}
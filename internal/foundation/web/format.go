@@ -0,0 +1,235 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Names of the formats clients can request with the format query parameter.
+const (
+	FormatJSON     = "json"
+	FormatCompact  = "compact"
+	FormatMsgPack  = "msgpack"
+	FormatProtobuf = "protobuf"
+)
+
+// ErrUnsupportedFormat is returned when a value cannot be encoded in the requested format.
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+type (
+	// Format is an encoding clients can request, either with the format query parameter or the Accept header.
+	// Every format is derived from the JSON encoding of the value, so fields keep their JSON names.
+	Format struct {
+		Name        string
+		ContentType string
+		Binary      bool     // whether the encoding is binary, sent base64 encoded over text transports
+		aliases     []string // other media types accepted for the format
+		encode      func(v any, data []byte) ([]byte, error)
+	}
+
+	// Compacter can be implemented by a value to support the compact format, which encodes it as
+	// a JSON array of its fields instead of an object.
+	Compacter interface {
+		Compact() any
+	}
+
+	// Protoer can be implemented by a value to support the protobuf format.
+	Protoer interface {
+		Proto() proto.Message
+	}
+)
+
+// Formats returns the supported formats, the default one first.
+func Formats() []Format {
+	return []Format{
+		{
+			Name:        FormatJSON,
+			ContentType: "application/json",
+			encode: func(_ any, data []byte) ([]byte, error) {
+				return data, nil
+			},
+		},
+		{
+			Name:        FormatCompact,
+			ContentType: "application/vnd.btc-price-service.compact+json",
+			encode:      encodeCompact,
+		},
+		{
+			Name:        FormatMsgPack,
+			ContentType: "application/msgpack",
+			Binary:      true,
+			aliases:     []string{"application/x-msgpack", "application/vnd.msgpack"},
+			encode:      encodeMsgPack,
+		},
+		{
+			Name:        FormatProtobuf,
+			ContentType: "application/x-protobuf",
+			Binary:      true,
+			aliases:     []string{"application/protobuf"},
+			encode:      encodeProtobuf,
+		},
+	}
+}
+
+// LookupFormat returns the format with the given name.
+func LookupFormat(name string) (Format, bool) {
+	for _, f := range Formats() {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	return Format{}, false
+}
+
+// NegotiateFormat returns the format requested by the format query parameter, or else the preferred
+// format of the Accept header. It defaults to JSON, and fails on an unknown format query parameter.
+// Media types of the Accept header that are not formats, e.g. text/event-stream, are ignored.
+func NegotiateFormat(r *http.Request) (Format, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		f, ok := LookupFormat(strings.ToLower(name))
+		if !ok {
+			return Format{}, fmt.Errorf("invalid 'format' parameter %q, expected json, compact, msgpack or protobuf", name)
+		}
+
+		return f, nil
+	}
+
+	formats := Formats()
+
+	var (
+		best    = formats[0]
+		bestQ   float64
+		matched bool
+	)
+
+	for accepted := range strings.SplitSeq(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		for _, f := range formats {
+			// ties go to the first listed media type
+			if f.matches(mediaType) && q > 0 && (!matched || q > bestQ) {
+				best, bestQ, matched = f, q, true
+			}
+		}
+	}
+
+	return best, nil
+}
+
+// Marshal encodes the value in the format.
+func (f Format) Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.encode(v, data)
+}
+
+func (f Format) matches(mediaType string) bool {
+	if mediaType == f.ContentType {
+		return true
+	}
+
+	for _, alias := range f.aliases {
+		if mediaType == alias {
+			return true
+		}
+	}
+
+	return false
+}
+
+func encodeCompact(v any, _ []byte) ([]byte, error) {
+	c, ok := v.(Compacter)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T has no compact encoding", ErrUnsupportedFormat, v)
+	}
+
+	return json.Marshal(c.Compact())
+}
+
+// encodeMsgPack transcodes the JSON encoding of the value, so decimals encoded as strings stay exact.
+// Map keys are sorted so the encoding is deterministic.
+func encodeMsgPack(_ any, data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+
+	if err := enc.Encode(fromJSONNumbers(doc)); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeProtobuf(v any, _ []byte) ([]byte, error) {
+	p, ok := v.(Protoer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T has no protobuf encoding", ErrUnsupportedFormat, v)
+	}
+
+	msg := p.Proto()
+	if msg == nil {
+		return nil, fmt.Errorf("%w: %T has no protobuf encoding", ErrUnsupportedFormat, v)
+	}
+
+	return proto.Marshal(msg)
+}
+
+// fromJSONNumbers replaces the JSON numbers of a decoded document by integers, or floats when they have a fraction.
+func fromJSONNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := v.Float64()
+
+		return f
+	case map[string]any:
+		for key, value := range v {
+			v[key] = fromJSONNumbers(value)
+		}
+
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = fromJSONNumbers(value)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
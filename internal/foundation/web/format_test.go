@@ -0,0 +1,164 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/gandarez/btc-price-service/internal/foundation/web"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := map[string]struct {
+		Query    string
+		Accept   string
+		Expected string
+	}{
+		"default":           {Expected: web.FormatJSON},
+		"query":             {Query: "?format=msgpack", Accept: "application/json", Expected: web.FormatMsgPack},
+		"query case":        {Query: "?format=Compact", Expected: web.FormatCompact},
+		"accept":            {Accept: "application/x-protobuf", Expected: web.FormatProtobuf},
+		"accept alias":      {Accept: "application/vnd.msgpack", Expected: web.FormatMsgPack},
+		"accept quality":    {Accept: "application/json;q=0.5, application/msgpack", Expected: web.FormatMsgPack},
+		"accept first wins": {Accept: "application/msgpack, application/x-protobuf", Expected: web.FormatMsgPack},
+		"accept rejected":   {Accept: "application/msgpack;q=0", Expected: web.FormatJSON},
+		"accept unknown":    {Accept: "text/event-stream, */*", Expected: web.FormatJSON},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/"+test.Query, nil)
+			r.Header.Set("Accept", test.Accept)
+
+			format, err := web.NegotiateFormat(r)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, format.Name)
+		})
+	}
+}
+
+func TestNegotiateFormat_Unknown(t *testing.T) {
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?format=xml", nil)
+
+	_, err := web.NegotiateFormat(r)
+	assert.EqualError(t, err, `invalid 'format' parameter "xml", expected json, compact, msgpack or protobuf`)
+}
+
+func TestFormat_Marshal(t *testing.T) {
+	v := testValue{Symbol: "BTC", Price: "50000.1", Ticks: 3, Ratio: 0.5}
+
+	compact, ok := web.LookupFormat(web.FormatCompact)
+	require.True(t, ok)
+
+	data, err := compact.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `["BTC","50000.1"]`, string(data))
+
+	_, err = compact.Marshal(struct{}{})
+	require.ErrorIs(t, err, web.ErrUnsupportedFormat)
+
+	mp, ok := web.LookupFormat(web.FormatMsgPack)
+	require.True(t, ok)
+
+	data, err = mp.Marshal(v)
+	require.NoError(t, err)
+
+	// the fields keep their JSON names, and numbers their type
+	var decoded map[string]any
+	require.NoError(t, msgpack.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]any{"symbol": "BTC", "price": "50000.1", "ticks": int64(3), "ratio": 0.5}, decoded)
+
+	pb, ok := web.LookupFormat(web.FormatProtobuf)
+	require.True(t, ok)
+
+	_, err = pb.Marshal(v)
+	assert.ErrorIs(t, err, web.ErrUnsupportedFormat)
+}
+
+func TestHandlerFunc_Format(t *testing.T) {
+	app := web.NewApp()
+	app.HandlerFunc(t.Context(), http.MethodGet, "", "/value", func(context.Context, *http.Request) web.Encoder {
+		return testValue{Symbol: "BTC", Price: "50000.1"}
+	})
+	app.HandlerFunc(t.Context(), http.MethodGet, "", "/error", func(context.Context, *http.Request) web.Encoder {
+		return web.NewError(http.StatusNotFound, errors.New("not found"))
+	})
+
+	tests := map[string]struct {
+		Path        string
+		Accept      string
+		Status      int
+		ContentType string
+		Body        string
+	}{
+		"json": {
+			Path:        "/value",
+			Status:      http.StatusOK,
+			ContentType: "application/json",
+			Body:        `{"symbol":"BTC","price":"50000.1","ticks":0,"ratio":0}`,
+		},
+		"compact": {
+			Path:        "/value",
+			Accept:      "application/vnd.btc-price-service.compact+json",
+			Status:      http.StatusOK,
+			ContentType: "application/vnd.btc-price-service.compact+json",
+			Body:        `["BTC","50000.1"]`,
+		},
+		"not acceptable": {
+			Path:        "/value?format=protobuf",
+			Status:      http.StatusNotAcceptable,
+			ContentType: "application/json",
+			Body:        `{"error":"unsupported format: web_test.testValue has no protobuf encoding"}`,
+		},
+		"unknown format": {
+			Path:        "/value?format=xml",
+			Status:      http.StatusBadRequest,
+			ContentType: "application/json",
+			Body:        `{"error":"invalid 'format' parameter \"xml\", expected json, compact, msgpack or protobuf"}`,
+		},
+		"error stays json": {
+			Path:        "/error?format=compact",
+			Status:      http.StatusNotFound,
+			ContentType: "application/json",
+			Body:        `{"error":"not found"}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, test.Path, nil)
+			r.Header.Set("Accept", test.Accept)
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			assert.Equal(t, test.Status, w.Code)
+			assert.Equal(t, test.ContentType, w.Header().Get("Content-Type"))
+			assert.JSONEq(t, test.Body, w.Body.String())
+		})
+	}
+}
+
+type testValue struct {
+	Symbol string  `json:"symbol"`
+	Price  string  `json:"price"`
+	Ticks  int     `json:"ticks"`
+	Ratio  float64 `json:"ratio"`
+}
+
+func (v testValue) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(v)
+	return data, "application/json", err
+}
+
+func (v testValue) Compact() any {
+	return []any{v.Symbol, v.Price}
+}
@@ -5,9 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
-func respond(ctx context.Context, w http.ResponseWriter, resp Encoder) error {
+func respond(ctx context.Context, w http.ResponseWriter, r *http.Request, resp Encoder) error {
 	// If the context has been canceled, it means the client is no longer
 	// waiting for a response.
 	if err := ctx.Err(); err != nil {
@@ -18,7 +19,9 @@ func respond(ctx context.Context, w http.ResponseWriter, resp Encoder) error {
 
 	statusCode := http.StatusOK
 
-	switch resp.(type) {
+	switch v := resp.(type) {
+	case StatusCoder:
+		statusCode = v.StatusCode()
 	case error:
 		statusCode = http.StatusInternalServerError
 	default:
@@ -27,7 +30,17 @@ func respond(ctx context.Context, w http.ResponseWriter, resp Encoder) error {
 		}
 	}
 
-	if statusCode == http.StatusNoContent {
+	if h, ok := resp.(Headerer); ok {
+		for key, values := range h.Headers() {
+			w.Header()[key] = values
+		}
+
+		if statusCode == http.StatusOK && notModified(r, w.Header().Get("ETag")) {
+			statusCode = http.StatusNotModified
+		}
+	}
+
+	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
 		w.WriteHeader(statusCode)
 		return nil
 	}
@@ -38,6 +51,28 @@ func respond(ctx context.Context, w http.ResponseWriter, resp Encoder) error {
 		return fmt.Errorf("respond: encode: %w", err)
 	}
 
+	// errors are always replied as JSON, the format only applies to JSON responses
+	if _, ok := resp.(error); !ok && contentType == "application/json" {
+		w.Header().Add("Vary", "Accept")
+
+		data, contentType, err = reformat(r, resp, data)
+
+		var webErr *Error
+
+		switch {
+		case errors.As(err, &webErr):
+			statusCode = webErr.StatusCode()
+
+			if data, contentType, err = webErr.Encode(); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return fmt.Errorf("respond: encode: %w", err)
+			}
+		case err != nil:
+			w.WriteHeader(http.StatusInternalServerError)
+			return fmt.Errorf("respond: encode: %w", err)
+		}
+	}
+
 	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(statusCode)
 
@@ -47,3 +82,48 @@ func respond(ctx context.Context, w http.ResponseWriter, resp Encoder) error {
 
 	return nil
 }
+
+// reformat encodes the JSON response in the format requested by the client. It fails with 400 Bad Request on
+// an unknown format, and 406 Not Acceptable when the response cannot be encoded in the requested one.
+func reformat(r *http.Request, resp Encoder, data []byte) ([]byte, string, error) {
+	format, err := NegotiateFormat(r)
+	if err != nil {
+		return nil, "", NewError(http.StatusBadRequest, err)
+	}
+
+	if format.Name == FormatJSON {
+		return data, format.ContentType, nil
+	}
+
+	data, err = format.encode(resp, data)
+
+	switch {
+	case errors.Is(err, ErrUnsupportedFormat):
+		return nil, "", NewError(http.StatusNotAcceptable, err)
+	case err != nil:
+		return nil, "", fmt.Errorf("%s: %w", format.Name, err)
+	}
+
+	return data, format.ContentType, nil
+}
+
+// notModified reports whether the If-None-Match header of a GET or HEAD request matches the ETag.
+// Weak and strong tags match each other, as conditional GETs use the weak comparison.
+func notModified(r *http.Request, etag string) bool {
+	if etag == "" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+
+	match := r.Header.Get("If-None-Match")
+	if strings.TrimSpace(match) == "*" {
+		return true
+	}
+
+	for tag := range strings.SplitSeq(match, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
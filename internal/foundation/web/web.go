@@ -14,6 +14,17 @@ type Encoder interface {
 	Encode() (data []byte, contentType string, err error)
 }
 
+// StatusCoder can be implemented by an Encoder to reply with a status code other than the default one.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Headerer can be implemented by an Encoder to set headers of the response. When they include an ETag
+// matching the If-None-Match header of the request, the response is replaced by 304 Not Modified.
+type Headerer interface {
+	Headers() http.Header
+}
+
 // HandlerFunc defines a function type for handling HTTP requests.
 type HandlerFunc func(ctx context.Context, r *http.Request) Encoder
 
@@ -53,7 +64,7 @@ func (a *App) HandlerFunc(ctx context.Context, method, group, path string, handl
 
 		resp := handlerFunc(rctx, r)
 
-		if err := respond(rctx, w, resp); err != nil {
+		if err := respond(rctx, w, r, resp); err != nil {
 			logger := log.Extract(ctx)
 			logger.Errorf("Error processing request for %s: %s", finalPath, err)
 
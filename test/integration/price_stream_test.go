@@ -40,6 +40,9 @@ func TestPriceStream(t *testing.T) {
 
 	// Read the stream line by line
 	scanner := bufio.NewScanner(resp.Body)
+
+	var event string
+
 	for scanner.Scan() {
 		line := scanner.Text()
 
@@ -49,6 +52,21 @@ func TestPriceStream(t *testing.T) {
 			continue
 		}
 
+		// event IDs and reconnect hints are only needed to resume the stream
+		if strings.HasPrefix(line, "id: ") || strings.HasPrefix(line, "retry: ") || line == "" {
+			continue
+		}
+
+		// only price events are checked, status events tell whether the upstream is available
+		if after, ok := strings.CutPrefix(line, "event: "); ok {
+			event = after
+			continue
+		}
+
+		if event != "price" {
+			continue
+		}
+
 		if after, ok := strings.CutPrefix(line, "data: "); ok {
 			require.NotEmpty(t, after, "Data should not be empty")
 